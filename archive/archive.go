@@ -8,7 +8,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -41,6 +44,7 @@ func FromDirectory(baseDir string, id int) (*pgrdf.Ebook, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
 	return pgrdf.ReadRDF(file)
 }
@@ -80,7 +84,227 @@ func FromTarArchive(archiveFile io.Reader, id int) (*pgrdf.Ebook, error) {
 	return pgrdf.ReadRDF(r)
 }
 
+// WalkTarArchive reads each .rdf file from the archive, in archive order, and
+// calls fn with the ebook. The walk stops at the first error returned by fn.
+func WalkTarArchive(archiveFile io.Reader, fn func(e *pgrdf.Ebook) error) error {
+	r := newRDFReader(archiveFile)
+	for {
+		ebook, err := r.next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(ebook); err != nil {
+			return err
+		}
+	}
+}
+
+// rdfReader reads the .rdf files from a tar archive, skipping all others.
+type rdfReader struct {
+	r *tar.Reader
+}
+
+func newRDFReader(archiveFile io.Reader) *rdfReader {
+	return &rdfReader{r: tar.NewReader(archiveFile)}
+}
+
+// next returns the next ebook in the archive, or io.EOF when there are none.
+func (r *rdfReader) next() (*pgrdf.Ebook, error) {
+	for {
+		header, err := r.r.Next()
+		if err == io.EOF {
+			return nil, err
+		} else if err != nil {
+			return nil, errors.Wrap(err, "error reading archive")
+		}
+		if header.Typeflag != tar.TypeReg || rdfFileID(header.Name) == 0 {
+			continue
+		}
+
+		ebook, err := pgrdf.ReadRDF(r.r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading '%s'", header.Name)
+		}
+		return ebook, nil
+	}
+}
+
+// WalkDirectory reads each .rdf file from the base directory, in order of the
+// eText ID, and calls fn with the ebook. The walk stops at the first error
+// returned by fn. The directory structure must be the same as required by
+// FromDirectory.
+func WalkDirectory(baseDir string, fn func(e *pgrdf.Ebook) error) error {
+	ids, err := DirectoryIDs(baseDir)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		ebook, err := FromDirectory(baseDir, id)
+		if err != nil {
+			return errors.Wrapf(err, "error reading eText ID '%d'", id)
+		}
+		if err := fn(ebook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rdfFileID returns the eText ID for an archive path in the standard PG
+// structure, e.g. `cache/epub/11/pg11.rdf`, or 0 for any other path.
+func rdfFileID(name string) int {
+	parts := strings.Split(filepath.ToSlash(name), "/")
+	if len(parts) < 3 {
+		return 0
+	}
+	id, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil || id < 0 || parts[len(parts)-1] != fmt.Sprintf("pg%d.rdf", id) {
+		return 0
+	}
+	return id
+}
+
 func rdfFilename(directory string, id int) string {
 	filename := fmt.Sprintf("pg%d.rdf", id)
 	return filepath.Join(directory, "cache", "epub", strconv.Itoa(id), filename)
 }
+
+// DirectoryIDs returns the eText IDs, in ascending order, of all the RDF files
+// in the base directory. The directory structure must be the same as required
+// by FromDirectory.
+func DirectoryIDs(baseDir string) ([]int, error) {
+	entries, err := os.ReadDir(filepath.Join(baseDir, "cache", "epub"))
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for _, entry := range entries {
+		id, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(rdfFilename(baseDir, id)); err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	return ids, nil
+}
+
+// ErrNoRDFFiles is returned when an archive does not contain any RDF files,
+// most likely because it is empty or not a Project Gutenberg RDF archive.
+var ErrNoRDFFiles = errors.New("archive contains no RDF files")
+
+// ExtractResult lists the eText IDs changed by Extract.
+type ExtractResult struct {
+	Added     []int
+	Updated   []int
+	Removed   []int
+	Unchanged int
+}
+
+// Extract the RDF files from the archive into the base directory, creating
+// the directory structure required by FromDirectory.
+//
+// An existing directory is synced with the archive: files with the same size
+// and modification time as the archive entry are skipped, and the RDF files
+// of eTexts no longer in the archive are removed. Each file is written to a
+// temporary file first, and then renamed, so readers never see partial files.
+//
+// When the archive has no RDF files nothing is removed, and ErrNoRDFFiles is
+// returned, so a truncated or wrong archive never empties the directory.
+func Extract(archiveFile io.Reader, baseDir string) (*ExtractResult, error) {
+	existing, err := DirectoryIDs(baseDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	result := &ExtractResult{}
+	seen := make(map[int]bool)
+
+	r := tar.NewReader(archiveFile)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return result, errors.Wrap(err, "error reading archive")
+		}
+		id := rdfFileID(header.Name)
+		if header.Typeflag != tar.TypeReg || id == 0 {
+			continue
+		}
+		seen[id] = true
+
+		filename := rdfFilename(baseDir, id)
+		info, err := os.Stat(filename)
+		switch {
+		case err == nil && info.Size() == header.Size && info.ModTime().Unix() == header.ModTime.Unix():
+			result.Unchanged++
+			continue
+		case err == nil:
+			result.Updated = append(result.Updated, id)
+		case os.IsNotExist(err):
+			result.Added = append(result.Added, id)
+		default:
+			return result, err
+		}
+
+		if err := writeFileAtomic(filename, r, header.ModTime); err != nil {
+			return result, errors.Wrapf(err, "error extracting eText ID '%d'", id)
+		}
+	}
+	if len(seen) == 0 {
+		return result, ErrNoRDFFiles
+	}
+
+	for _, id := range existing {
+		if seen[id] {
+			continue
+		}
+		filename := rdfFilename(baseDir, id)
+		if err := os.Remove(filename); err != nil {
+			return result, err
+		}
+		_ = os.Remove(filepath.Dir(filename)) // only when empty
+		result.Removed = append(result.Removed, id)
+	}
+
+	return result, nil
+}
+
+// writeFileAtomic writes the data to a temporary file in the same directory,
+// before renaming it to the filename.
+func writeFileAtomic(filename string, r io.Reader, modTime time.Time) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), modTime, modTime); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
@@ -1,9 +1,14 @@
 package archive_test
 
 import (
+	"archive/tar"
+	"bytes"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/mrcook/pgrdf"
 	"github.com/mrcook/pgrdf/archive"
 )
 
@@ -42,3 +47,127 @@ func TestArchiveLookup(t *testing.T) {
 		t.Errorf("unexpected title found, got '%s'", rdf.Titles[0])
 	}
 }
+
+func TestWalkTarArchive(t *testing.T) {
+	file, err := os.Open("../samples/rdf-files-test.tar")
+	if err != nil {
+		t.Fatalf("Unable to open RDF tar archive: %s", err)
+	}
+	defer file.Close()
+
+	var ids []int
+	err = archive.WalkTarArchive(file, func(e *pgrdf.Ebook) error {
+		ids = append(ids, e.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error walking archive: %s", err)
+	}
+	if len(ids) != 2 || ids[0] != 1400 || ids[1] != 11 {
+		t.Errorf("unexpected eText IDs, got %v", ids)
+	}
+}
+
+func TestWalkDirectory(t *testing.T) {
+	var ids []int
+	err := archive.WalkDirectory("../samples", func(e *pgrdf.Ebook) error {
+		ids = append(ids, e.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error walking directory: %s", err)
+	}
+	if len(ids) != 1 || ids[0] != 999991234 {
+		t.Errorf("unexpected eText IDs, got %v", ids)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	extract := func() *archive.ExtractResult {
+		t.Helper()
+		file, err := os.Open("../samples/rdf-files-test.tar")
+		if err != nil {
+			t.Fatalf("Unable to open RDF tar archive: %s", err)
+		}
+		defer file.Close()
+
+		result, err := archive.Extract(file, dir)
+		if err != nil {
+			t.Fatalf("unexpected error extracting archive: %s", err)
+		}
+		return result
+	}
+
+	result := extract()
+	if !reflect.DeepEqual(result.Added, []int{1400, 11}) || result.Unchanged != 0 {
+		t.Errorf("unexpected first extract, got %+v", result)
+	}
+	if _, err := archive.FromDirectory(dir, 11); err != nil {
+		t.Errorf("unexpected error reading extracted RDF: %s", err)
+	}
+
+	result = extract()
+	if len(result.Added) != 0 || len(result.Updated) != 0 || result.Unchanged != 2 {
+		t.Errorf("expected all files to be unchanged, got %+v", result)
+	}
+
+	// a modified file, and an eText which is no longer in the archive
+	if err := os.WriteFile(filepath.Join(dir, "cache", "epub", "11", "pg11.rdf"), []byte("changed"), 0o644); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "cache", "epub", "5"), 0o755); err != nil {
+		t.Fatalf("unable to create directory: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cache", "epub", "5", "pg5.rdf"), []byte("stale"), 0o644); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+
+	result = extract()
+	if !reflect.DeepEqual(result.Updated, []int{11}) || !reflect.DeepEqual(result.Removed, []int{5}) || result.Unchanged != 1 {
+		t.Errorf("unexpected sync result, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cache", "epub", "5")); !os.IsNotExist(err) {
+		t.Errorf("expected the stale eText directory to be removed")
+	}
+	if _, err := archive.FromDirectory(dir, 11); err != nil {
+		t.Errorf("unexpected error reading updated RDF: %s", err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(dir, "cache", "epub", "11"))
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files to remain, got %d files", len(entries))
+	}
+}
+
+func TestExtract_NoRDFFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "cache", "epub", "5"), 0o755); err != nil {
+		t.Fatalf("unable to create directory: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cache", "epub", "5", "pg5.rdf"), []byte("existing"), 0o644); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+
+	var foreign bytes.Buffer
+	tw := tar.NewWriter(&foreign)
+	if err := tw.WriteHeader(&tar.Header{Name: "README.txt", Mode: 0o644, Size: 5}); err != nil {
+		t.Fatalf("unable to write tar header: %s", err)
+	}
+	_, _ = tw.Write([]byte("hello"))
+	_ = tw.Close()
+
+	archives := map[string][]byte{"empty": nil, "foreign": foreign.Bytes()}
+	for name, data := range archives {
+		result, err := archive.Extract(bytes.NewReader(data), dir)
+		if err != archive.ErrNoRDFFiles {
+			t.Errorf("%s: expected ErrNoRDFFiles, got '%v'", name, err)
+		}
+		if len(result.Removed) != 0 {
+			t.Errorf("%s: expected no files to be removed, got %v", name, result.Removed)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "cache", "epub", "5", "pg5.rdf")); err != nil {
+			t.Errorf("%s: expected the existing RDF file to remain, got '%s'", name, err)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/internal/marcrel"
+)
+
+// Column of a CSV catalog, with the functions for formatting the column value
+// from an ebook, and for parsing the value back to the ebook fields.
+type Column struct {
+	Name   string
+	Format func(e *pgrdf.Ebook) string
+	Parse  func(e *pgrdf.Ebook, value string)
+}
+
+// listSeparator separates the values of a list column, e.g. the languages.
+const listSeparator = "; "
+
+// The columns of the Project Gutenberg `pg_catalog.csv` file.
+var (
+	ColumnTextNumber = Column{
+		Name:   "Text#",
+		Format: func(e *pgrdf.Ebook) string { return strconv.Itoa(e.ID) },
+		Parse:  func(e *pgrdf.Ebook, v string) { e.ID, _ = strconv.Atoi(v) },
+	}
+	ColumnType = Column{
+		Name:   "Type",
+		Format: func(e *pgrdf.Ebook) string { return string(e.BookType) },
+		Parse:  func(e *pgrdf.Ebook, v string) { e.BookType = pgrdf.BookType(v) },
+	}
+	ColumnIssued = Column{
+		Name:   "Issued",
+		Format: func(e *pgrdf.Ebook) string { return e.ReleaseDate },
+		Parse:  func(e *pgrdf.Ebook, v string) { e.ReleaseDate = v },
+	}
+	ColumnTitle = Column{
+		Name:   "Title",
+		Format: func(e *pgrdf.Ebook) string { return strings.Join(e.Titles, "\n") },
+		Parse:  func(e *pgrdf.Ebook, v string) { e.Titles = splitList(v, "\n") },
+	}
+	ColumnLanguage = Column{
+		Name:   "Language",
+		Format: func(e *pgrdf.Ebook) string { return strings.Join(e.Languages, listSeparator) },
+		Parse:  func(e *pgrdf.Ebook, v string) { e.Languages = splitList(v, listSeparator) },
+	}
+	ColumnAuthors = Column{
+		Name:   "Authors",
+		Format: formatCreators,
+		Parse:  parseCreators,
+	}
+	ColumnSubjects = Column{
+		Name:   "Subjects",
+		Format: func(e *pgrdf.Ebook) string { return formatSubjects(e, pgrdf.SchemaLCSH) },
+		Parse:  func(e *pgrdf.Ebook, v string) { parseSubjects(e, v, pgrdf.SchemaLCSH) },
+	}
+	ColumnLoCC = Column{
+		Name:   "LoCC",
+		Format: func(e *pgrdf.Ebook) string { return formatSubjects(e, pgrdf.SchemaLCC) },
+		Parse:  func(e *pgrdf.Ebook, v string) { parseSubjects(e, v, pgrdf.SchemaLCC) },
+	}
+	ColumnBookshelves = Column{
+		Name: "Bookshelves",
+		Format: func(e *pgrdf.Ebook) string {
+			var names []string
+			for _, shelf := range e.Bookshelves {
+				names = append(names, shelf.Name)
+			}
+			return strings.Join(names, listSeparator)
+		},
+		Parse: func(e *pgrdf.Ebook, v string) {
+			for _, name := range splitList(v, listSeparator) {
+				e.AddBookshelf(name, "2009/pgterms/Bookshelf")
+			}
+		},
+	}
+)
+
+// Additional columns for a wider catalog export.
+var (
+	ColumnAlternateTitles = Column{
+		Name:   "Alternate Titles",
+		Format: func(e *pgrdf.Ebook) string { return strings.Join(e.AlternateTitles, "\n") },
+		Parse:  func(e *pgrdf.Ebook, v string) { e.AlternateTitles = splitList(v, "\n") },
+	}
+	ColumnPublishedYear = Column{
+		Name: "Published Year",
+		Format: func(e *pgrdf.Ebook) string {
+			if e.PublishedYear == 0 {
+				return ""
+			}
+			return strconv.Itoa(e.PublishedYear)
+		},
+		Parse: func(e *pgrdf.Ebook, v string) { e.PublishedYear, _ = strconv.Atoi(v) },
+	}
+	ColumnSeries = Column{
+		Name:   "Series",
+		Format: func(e *pgrdf.Ebook) string { return strings.Join(e.Series, listSeparator) },
+		Parse:  func(e *pgrdf.Ebook, v string) { e.Series = splitList(v, listSeparator) },
+	}
+	ColumnSummary = Column{
+		Name:   "Summary",
+		Format: func(e *pgrdf.Ebook) string { return e.Summary },
+		Parse:  func(e *pgrdf.Ebook, v string) { e.Summary = v },
+	}
+	ColumnRights = Column{
+		Name:   "Rights",
+		Format: func(e *pgrdf.Ebook) string { return e.Copyright },
+		Parse:  func(e *pgrdf.Ebook, v string) { e.Copyright = v },
+	}
+	ColumnDownloads = Column{
+		Name:   "Downloads",
+		Format: func(e *pgrdf.Ebook) string { return strconv.Itoa(e.Downloads) },
+		Parse:  func(e *pgrdf.Ebook, v string) { e.Downloads, _ = strconv.Atoi(v) },
+	}
+)
+
+// PGCatalogColumns are the columns of the official `pg_catalog.csv` file.
+var PGCatalogColumns = []Column{
+	ColumnTextNumber, ColumnType, ColumnIssued, ColumnTitle, ColumnLanguage,
+	ColumnAuthors, ColumnSubjects, ColumnLoCC, ColumnBookshelves,
+}
+
+// ExtendedColumns are the `pg_catalog.csv` columns along with all the
+// additional columns.
+var ExtendedColumns = append(append([]Column{}, PGCatalogColumns...),
+	ColumnAlternateTitles, ColumnPublishedYear, ColumnSeries, ColumnSummary,
+	ColumnRights, ColumnDownloads,
+)
+
+// creatorRE matches a creator in the Authors column, e.g.
+// "Browne, Hablot Knight, 1815-1882 [Illustrator]" or "Homer, 751? BCE-651? BCE".
+var creatorRE = regexp.MustCompile(`^(.*?)(?:, (\d*)\?? ?(BCE)?-(\d*)\?? ?(BCE)?)?(?: \[([^\]]+)\])?$`)
+
+// CSVWriter writes ebooks as rows of a CSV catalog, with a header row of the
+// column names. The Project Gutenberg `pg_catalog.csv` columns are used when
+// no columns are given.
+type CSVWriter struct {
+	w       *csv.Writer
+	columns []Column
+	header  bool
+}
+
+// NewCSVWriter returns a CSVWriter writing comma separated values to w.
+func NewCSVWriter(w io.Writer, columns ...Column) *CSVWriter {
+	if len(columns) == 0 {
+		columns = PGCatalogColumns
+	}
+	return &CSVWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+// NewTSVWriter returns a CSVWriter writing tab separated values to w.
+func NewTSVWriter(w io.Writer, columns ...Column) *CSVWriter {
+	cw := NewCSVWriter(w, columns...)
+	cw.w.Comma = '\t'
+	return cw
+}
+
+// Write the ebook as a CSV row, writing the header row first when required.
+func (cw *CSVWriter) Write(e *pgrdf.Ebook) error {
+	return cw.writeRecord(cw.record(e))
+}
+
+// Flush writes any buffered data, including the header row when no ebooks
+// have been written.
+func (cw *CSVWriter) Flush() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *CSVWriter) record(e *pgrdf.Ebook) []string {
+	record := make([]string, len(cw.columns))
+	for i, col := range cw.columns {
+		record[i] = col.Format(e)
+	}
+	return record
+}
+
+func (cw *CSVWriter) writeRecord(record []string) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *CSVWriter) writeHeader() error {
+	if cw.header {
+		return nil
+	}
+	cw.header = true
+
+	names := make([]string, len(cw.columns))
+	for i, col := range cw.columns {
+		names[i] = col.Name
+	}
+	return cw.w.Write(names)
+}
+
+// WriteCSVCatalog reads every RDF in the archive and writes them to the
+// CSVWriter, ordered by eText ID as in the official `pg_catalog.csv`. Only
+// the formatted rows are kept in memory until they are written, not the
+// ebooks.
+//
+//	cw := archive.NewCSVWriter(os.Stdout)
+//	err := archive.WriteCSVCatalog(archiveFile, cw)
+func WriteCSVCatalog(archiveFile io.Reader, cw *CSVWriter) error {
+	type row struct {
+		id     int
+		record []string
+	}
+	var rows []row
+	err := WalkTarArchive(archiveFile, func(e *pgrdf.Ebook) error {
+		rows = append(rows, row{id: e.ID, record: cw.record(e)})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].id < rows[j].id })
+	for _, r := range rows {
+		if err := cw.writeRecord(r.record); err != nil {
+			return err
+		}
+	}
+	return cw.Flush()
+}
+
+// ReadCSVCatalog reads a CSV (or TSV) catalog, such as `pg_catalog.csv`, and
+// returns a lightweight Ebook for each row. Columns are matched by the header
+// names, using the ExtendedColumns when no columns are given, and any other
+// columns are ignored.
+func ReadCSVCatalog(r io.Reader, columns ...Column) ([]*pgrdf.Ebook, error) {
+	if len(columns) == 0 {
+		columns = ExtendedColumns
+	}
+
+	br := bufio.NewReader(r)
+	firstLine, err := br.Peek(br.Size())
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if i := strings.IndexByte(string(firstLine), '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+
+	cr := csv.NewReader(br)
+	if strings.Count(string(firstLine), "\t") > strings.Count(string(firstLine), ",") {
+		cr.Comma = '\t'
+	}
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "error reading CSV header")
+	}
+
+	parsers := make([]func(e *pgrdf.Ebook, v string), len(header))
+	for i, name := range header {
+		for _, col := range columns {
+			if strings.EqualFold(strings.TrimSpace(name), col.Name) {
+				parsers[i] = col.Parse
+			}
+		}
+	}
+
+	var ebooks []*pgrdf.Ebook
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return ebooks, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "error reading CSV")
+		}
+
+		e := &pgrdf.Ebook{}
+		for i, value := range record {
+			if parsers[i] != nil && len(value) > 0 {
+				parsers[i](e, value)
+			}
+		}
+		ebooks = append(ebooks, e)
+	}
+}
+
+// formatCreators writes each creator with their dates, along with the role
+// for non-authors, as used by the Authors column.
+func formatCreators(e *pgrdf.Ebook) string {
+	var creators []string
+	for _, c := range e.Creators {
+		s := c.Name
+		if c.Born != 0 || c.Died != 0 {
+			s += ", " + formatYear(c.Born) + "-" + formatYear(c.Died)
+		}
+		if c.Role != pgrdf.RoleAut && len(c.Role) > 0 {
+			term := c.Role.Term()
+			if len(term) == 0 {
+				term = string(c.Role)
+			}
+			s += " [" + strings.ToUpper(term[:1]) + term[1:] + "]"
+		}
+		creators = append(creators, s)
+	}
+	return strings.Join(creators, listSeparator)
+}
+
+func parseCreators(e *pgrdf.Ebook, value string) {
+	for _, s := range splitList(value, listSeparator) {
+		m := creatorRE.FindStringSubmatch(s)
+		c := pgrdf.Creator{Name: m[1], Role: pgrdf.RoleAut}
+		c.Born = parseYear(m[2], m[3])
+		c.Died = parseYear(m[4], m[5])
+		if len(m[6]) > 0 {
+			if code, ok := marcrel.FromTerm(m[6]); ok {
+				c.Role = pgrdf.MarcRelator(code)
+			} else {
+				c.Role = pgrdf.MarcRelator(strings.ToLower(m[6]))
+			}
+		}
+		e.AddCreator(c)
+	}
+}
+
+func formatYear(year int) string {
+	switch {
+	case year == 0:
+		return ""
+	case year < 0:
+		return strconv.Itoa(-year) + " BCE"
+	default:
+		return strconv.Itoa(year)
+	}
+}
+
+func parseYear(year, bce string) int {
+	y, _ := strconv.Atoi(year)
+	if len(bce) > 0 {
+		return -y
+	}
+	return y
+}
+
+func formatSubjects(e *pgrdf.Ebook, schema string) string {
+	var headings []string
+	for _, s := range e.Subjects {
+		if s.Schema == schema {
+			headings = append(headings, s.Heading)
+		}
+	}
+	return strings.Join(headings, listSeparator)
+}
+
+func parseSubjects(e *pgrdf.Ebook, value, schema string) {
+	for _, heading := range splitList(value, listSeparator) {
+		e.AddSubject(heading, schema)
+	}
+}
+
+func splitList(value, sep string) []string {
+	var list []string
+	for _, v := range strings.Split(value, sep) {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,132 @@
+package archive_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/archive"
+)
+
+func TestWriteCSVCatalog(t *testing.T) {
+	file, err := os.Open("../samples/rdf-files-test.tar")
+	if err != nil {
+		t.Fatalf("Unable to open RDF tar archive: %s", err)
+	}
+	defer file.Close()
+
+	w := bytes.NewBuffer([]byte{})
+	if err := archive.WriteCSVCatalog(file, archive.NewCSVWriter(w)); err != nil {
+		t.Fatalf("unexpected error writing CSV: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	if lines[0] != "Text#,Type,Issued,Title,Language,Authors,Subjects,LoCC,Bookshelves" {
+		t.Errorf("unexpected header, got '%s'", lines[0])
+	}
+	// rows are ordered by eText ID, not in archive order
+	if !strings.HasPrefix(lines[1], `11,Text,2008-06-27,Alice's Adventures in Wonderland,en,"Carroll, Lewis, 1832-1898",`) {
+		t.Errorf("unexpected first row, got '%s'", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "1400,") || !strings.HasSuffix(lines[2], `,PR,Best Books Ever Listings`) {
+		t.Errorf("unexpected second row, got '%s'", lines[2])
+	}
+}
+
+func TestCSVWriter_ReadCSVCatalog_RoundTrip(t *testing.T) {
+	ebook := &pgrdf.Ebook{
+		ID:          1400,
+		BookType:    pgrdf.BookTypeText,
+		ReleaseDate: "1998-07-01",
+		Titles:      []string{"Great Expectations", "A Novel"},
+		Languages:   []string{"en", "fr"},
+		Downloads:   16579,
+		Creators: []pgrdf.Creator{
+			{Name: "Dickens, Charles", Born: 1812, Died: 1870, Role: pgrdf.RoleAut},
+			{Name: "Browne, Hablot Knight", Born: 1815, Died: 1882, Role: pgrdf.RoleIll},
+			{Name: "Homer", Born: -751, Died: -651, Role: pgrdf.RoleAut},
+			{Name: "Anonymous", Role: pgrdf.RoleEdt},
+		},
+	}
+	ebook.AddSubject("Orphans -- Fiction", "http://purl.org/dc/terms/LCSH")
+	ebook.AddSubject("PR", "http://purl.org/dc/terms/LCC")
+	ebook.AddBookshelf("Best Books Ever Listings", "2009/pgterms/Bookshelf")
+
+	w := bytes.NewBuffer([]byte{})
+	cw := archive.NewTSVWriter(w, archive.ExtendedColumns...)
+	if err := cw.Write(ebook); err != nil {
+		t.Fatalf("unexpected error writing TSV: %s", err)
+	}
+	if err := cw.Flush(); err != nil {
+		t.Fatalf("unexpected error writing TSV: %s", err)
+	}
+	if !strings.Contains(w.String(), "Dickens, Charles, 1812-1870; Browne, Hablot Knight, 1815-1882 [Illustrator]; Homer, 751 BCE-651 BCE; Anonymous [Editor]") {
+		t.Errorf("unexpected authors, got:\n%s", w.String())
+	}
+
+	ebooks, err := archive.ReadCSVCatalog(w)
+	if err != nil {
+		t.Fatalf("unexpected error reading TSV: %s", err)
+	}
+	if len(ebooks) != 1 {
+		t.Fatalf("expected 1 ebook, got %d", len(ebooks))
+	}
+	got := ebooks[0]
+
+	if got.ID != 1400 || got.BookType != pgrdf.BookTypeText || got.ReleaseDate != "1998-07-01" || got.Downloads != 16579 {
+		t.Errorf("unexpected ebook, got %+v", got)
+	}
+	if len(got.Titles) != 2 || got.Titles[1] != "A Novel" {
+		t.Errorf("unexpected titles, got %q", got.Titles)
+	}
+	if len(got.Languages) != 2 || got.Languages[1] != "fr" {
+		t.Errorf("unexpected languages, got %q", got.Languages)
+	}
+	if len(got.Creators) != len(ebook.Creators) {
+		t.Fatalf("expected %d creators, got %d", len(ebook.Creators), len(got.Creators))
+	}
+	for i, c := range ebook.Creators {
+		g := got.Creators[i]
+		if g.Name != c.Name || g.Born != c.Born || g.Died != c.Died || g.Role != c.Role {
+			t.Errorf("unexpected creator #%d, got %+v", i, g)
+		}
+	}
+	if len(got.Subjects) != 2 || got.Subjects[1].Heading != "PR" || got.Subjects[1].Schema != "http://purl.org/dc/terms/LCC" {
+		t.Errorf("unexpected subjects, got %+v", got.Subjects)
+	}
+	if len(got.Bookshelves) != 1 || got.Bookshelves[0].Name != "Best Books Ever Listings" {
+		t.Errorf("unexpected bookshelves, got %+v", got.Bookshelves)
+	}
+}
+
+func TestReadCSVCatalog(t *testing.T) {
+	data := `Text#,Type,Issued,Title,Language,Authors,Subjects,LoCC,Bookshelves
+1,Text,1971-12-01,The Declaration of Independence of the United States of America,en,"Jefferson, Thomas, 1743-1826","United States -- History -- Revolution, 1775-1783 -- Sources; United States. Declaration of Independence",E201; JK,Politics; American Revolutionary War; United States Law
+`
+	ebooks, err := archive.ReadCSVCatalog(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error reading CSV: %s", err)
+	}
+	if len(ebooks) != 1 {
+		t.Fatalf("expected 1 ebook, got %d", len(ebooks))
+	}
+	e := ebooks[0]
+
+	if e.ID != 1 || e.Titles[0] != "The Declaration of Independence of the United States of America" {
+		t.Errorf("unexpected ebook, got %d '%s'", e.ID, e.Titles[0])
+	}
+	if len(e.Creators) != 1 || e.Creators[0].Name != "Jefferson, Thomas" || e.Creators[0].Died != 1826 {
+		t.Errorf("unexpected creators, got %+v", e.Creators)
+	}
+	if len(e.Subjects) != 4 {
+		t.Errorf("expected 4 subjects, got %d", len(e.Subjects))
+	}
+	if len(e.Bookshelves) != 3 {
+		t.Errorf("expected 3 bookshelves, got %d", len(e.Bookshelves))
+	}
+}
@@ -0,0 +1,179 @@
+package archive
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mrcook/pgrdf"
+)
+
+// DiffStatus of an eText between two archives.
+type DiffStatus string
+
+const (
+	DiffAdded    DiffStatus = "added"
+	DiffRemoved  DiffStatus = "removed"
+	DiffModified DiffStatus = "modified"
+)
+
+// VolatileFields are the Ebook fields, by JSON name, which change with every
+// regeneration of the archive, and can be ignored using DiffOptions.
+var VolatileFields = []string{"downloads"}
+
+// DiffOptions for the Diff of two archives.
+type DiffOptions struct {
+	// Ebook fields to ignore when comparing, given by their JSON name,
+	// e.g. "downloads".
+	IgnoreFields []string
+}
+
+// EbookDiff is an eText that was added, removed, or modified between two
+// archives. For a modified eText, Fields lists the changed Ebook fields by
+// their JSON name, e.g. "titles", "creators", and Changes the field-level
+// changes, as returned by `pgrdf.Diff`.
+type EbookDiff struct {
+	ID      int
+	Status  DiffStatus
+	Fields  []string
+	Changes []pgrdf.Change
+	Old     *pgrdf.Ebook // nil when added
+	New     *pgrdf.Ebook // nil when removed
+}
+
+// Diff reads two archive snapshots and calls fn for each eText that was
+// added, removed, or modified between them. The walk stops at the first
+// error returned by fn.
+//
+// Both archives are read at the same time, with an ebook only being held in
+// memory until the same eText is found in the other archive, so memory use
+// stays low when the archives have a similar order. Modified eTexts are
+// reported as they are found, followed by the removed and then added eTexts,
+// in eText ID order.
+//
+//	err := archive.Diff(oldFile, newFile, &archive.DiffOptions{IgnoreFields: archive.VolatileFields},
+//		func(d *archive.EbookDiff) error {
+//			fmt.Println(d.ID, d.Status, d.Fields)
+//			return nil
+//		})
+func Diff(oldArchive, newArchive io.Reader, opts *DiffOptions, fn func(d *EbookDiff) error) error {
+	if opts == nil {
+		opts = &DiffOptions{}
+	}
+
+	readers := [2]*rdfReader{newRDFReader(oldArchive), newRDFReader(newArchive)}
+	pending := [2]map[int]*pgrdf.Ebook{{}, {}}
+	done := [2]bool{}
+
+	for !done[0] || !done[1] {
+		for i, r := range readers {
+			if done[i] {
+				continue
+			}
+			ebook, err := r.next()
+			if err == io.EOF {
+				done[i] = true
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			// matched with the other archive?
+			other, ok := pending[1-i][ebook.ID]
+			if !ok {
+				pending[i][ebook.ID] = ebook
+				continue
+			}
+			delete(pending[1-i], ebook.ID)
+
+			oldEbook, newEbook := other, ebook
+			if i == 0 {
+				oldEbook, newEbook = ebook, other
+			}
+			changes := changesExcluding(pgrdf.Diff(oldEbook, newEbook), opts.IgnoreFields)
+			if len(changes) == 0 {
+				continue
+			}
+			d := &EbookDiff{
+				ID:      ebook.ID,
+				Status:  DiffModified,
+				Fields:  changedFields(changes, nil),
+				Changes: changes,
+				Old:     oldEbook,
+				New:     newEbook,
+			}
+			if err := fn(d); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, id := range sortedIDs(pending[0]) {
+		if err := fn(&EbookDiff{ID: id, Status: DiffRemoved, Old: pending[0][id]}); err != nil {
+			return err
+		}
+	}
+	for _, id := range sortedIDs(pending[1]) {
+		if err := fn(&EbookDiff{ID: id, Status: DiffAdded, New: pending[1][id]}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ChangedFields returns the JSON names of the Ebook fields which differ
+// between the two ebooks, excluding any ignored fields. As with `pgrdf.Diff`,
+// lists are compared as sets.
+func ChangedFields(a, b *pgrdf.Ebook, ignore ...string) []string {
+	return changedFields(pgrdf.Diff(a, b), ignore)
+}
+
+// changedFields returns the unique field names of the changes, in order.
+func changedFields(changes []pgrdf.Change, ignore []string) []string {
+	var fields []string
+	for _, c := range changes {
+		name := changeField(c)
+		if !containsField(ignore, name) && !containsField(fields, name) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// changesExcluding returns the changes which are not to the ignored fields.
+func changesExcluding(changes []pgrdf.Change, ignore []string) []pgrdf.Change {
+	var list []pgrdf.Change
+	for _, c := range changes {
+		if !containsField(ignore, changeField(c)) {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// changeField returns the top-level Ebook field of the change path.
+func changeField(c pgrdf.Change) string {
+	if i := strings.IndexAny(c.Path, ".["); i >= 0 {
+		return c.Path[:i]
+	}
+	return c.Path
+}
+
+func containsField(fields []string, name string) bool {
+	for _, f := range fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedIDs(ebooks map[int]*pgrdf.Ebook) []int {
+	ids := make([]int, 0, len(ebooks))
+	for id := range ebooks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -0,0 +1,123 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf/archive"
+)
+
+// newSnapshot returns a copy of the sample archive, without pg11, with pg1400
+// modified, and with the pg999991234 sample added.
+func newSnapshot(t *testing.T, replacer *strings.Replacer) *bytes.Buffer {
+	t.Helper()
+
+	file, err := os.Open("../samples/rdf-files-test.tar")
+	if err != nil {
+		t.Fatalf("Unable to open RDF tar archive: %s", err)
+	}
+	defer file.Close()
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	add := func(name string, data []byte) {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatalf("unable to write tar header: %s", err)
+		}
+		_, _ = tw.Write(data)
+	}
+
+	sample, err := os.ReadFile("../samples/cache/epub/999991234/pg999991234.rdf")
+	if err != nil {
+		t.Fatalf("unable to read sample: %s", err)
+	}
+	add("cache/epub/999991234/pg999991234.rdf", sample)
+
+	r := tar.NewReader(file)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if header.Name != "cache/epub/1400/pg1400.rdf" {
+			continue
+		}
+		data, _ := io.ReadAll(r)
+		add(header.Name, []byte(replacer.Replace(string(data))))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("unable to close tar: %s", err)
+	}
+	return buf
+}
+
+func TestDiff(t *testing.T) {
+	oldFile, err := os.Open("../samples/rdf-files-test.tar")
+	if err != nil {
+		t.Fatalf("Unable to open RDF tar archive: %s", err)
+	}
+	defer oldFile.Close()
+
+	newFile := newSnapshot(t, strings.NewReplacer(
+		">Great Expectations<", ">Great Expectations, Revised<",
+		">16579<", ">20000<",
+	))
+
+	var diffs []*archive.EbookDiff
+	err = archive.Diff(oldFile, newFile, nil, func(d *archive.EbookDiff) error {
+		diffs = append(diffs, d)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(diffs) != 3 {
+		t.Fatalf("expected 3 differences, got %d", len(diffs))
+	}
+	if d := diffs[0]; d.ID != 1400 || d.Status != archive.DiffModified || !reflect.DeepEqual(d.Fields, []string{"titles", "downloads"}) {
+		t.Errorf("unexpected modified eText, got %d %s %v", d.ID, d.Status, d.Fields)
+	}
+	if d := diffs[0]; d.Old.Titles[0] != "Great Expectations" || d.New.Titles[0] != "Great Expectations, Revised" {
+		t.Errorf("unexpected old/new ebooks, got '%s' and '%s'", d.Old.Titles[0], d.New.Titles[0])
+	}
+	if d := diffs[0]; len(d.Changes) != 3 || d.Changes[0].Path != `titles["Great Expectations"]` {
+		t.Errorf("unexpected field changes, got %+v", d.Changes)
+	}
+	if d := diffs[1]; d.ID != 11 || d.Status != archive.DiffRemoved || d.Old == nil || d.New != nil {
+		t.Errorf("unexpected removed eText, got %d %s", d.ID, d.Status)
+	}
+	if d := diffs[2]; d.ID != 999991234 || d.Status != archive.DiffAdded || d.New == nil || d.Old != nil {
+		t.Errorf("unexpected added eText, got %d %s", d.ID, d.Status)
+	}
+}
+
+func TestDiff_IgnoreFields(t *testing.T) {
+	oldFile, err := os.Open("../samples/rdf-files-test.tar")
+	if err != nil {
+		t.Fatalf("Unable to open RDF tar archive: %s", err)
+	}
+	defer oldFile.Close()
+
+	newFile := newSnapshot(t, strings.NewReplacer(">16579<", ">20000<"))
+
+	var modified []int
+	opts := &archive.DiffOptions{IgnoreFields: archive.VolatileFields}
+	err = archive.Diff(oldFile, newFile, opts, func(d *archive.EbookDiff) error {
+		if d.Status == archive.DiffModified {
+			modified = append(modified, d.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(modified) != 0 {
+		t.Errorf("expected no modified eTexts, got %v", modified)
+	}
+}
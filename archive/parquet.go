@@ -0,0 +1,215 @@
+package archive
+
+import (
+	"io"
+	"time"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/internal/parquet"
+)
+
+// parquetSchema is the Parquet schema of an Ebook, using the JSON field names.
+// Creators, subjects, bookshelves, files, and book covers are written as lists
+// of structs, so they can be queried using `unnest()`, e.g. with DuckDB.
+var parquetSchema = []parquet.Field{
+	{Name: "id", Type: parquet.Int32},
+	{Name: "type", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "titles", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	{Name: "alternate_titles", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	{Name: "released", Type: parquet.Int32, Logical: parquet.Date, Optional: true},
+	{Name: "published_year", Type: parquet.Int32, Optional: true},
+	{Name: "publisher", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "summary", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "toc", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "series", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	{Name: "languages", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	{Name: "language_dialect", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "language_notes", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	{Name: "publication_note", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "edition_note", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "production_notes", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	{Name: "copyright", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "copyright_clearance_code", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "notes", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	{Name: "physical_description_note", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "source_links", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	{Name: "lccn", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "isbn", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "title_page_image", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "back_cover", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "book_covers", Type: parquet.Group, List: true, Fields: []parquet.Field{
+		{Name: "filename", Type: parquet.ByteArray, Logical: parquet.String},
+		{Name: "source", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	}},
+	{Name: "creators", Type: parquet.Group, List: true, Fields: []parquet.Field{
+		{Name: "id", Type: parquet.Int32, Optional: true},
+		{Name: "name", Type: parquet.ByteArray, Logical: parquet.String},
+		{Name: "aliases", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+		{Name: "born_year", Type: parquet.Int32, Optional: true},
+		{Name: "died_year", Type: parquet.Int32, Optional: true},
+		{Name: "role", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+		{Name: "webpages", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	}},
+	{Name: "subjects", Type: parquet.Group, List: true, Fields: []parquet.Field{
+		{Name: "heading", Type: parquet.ByteArray, Logical: parquet.String},
+		{Name: "schema", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	}},
+	{Name: "files", Type: parquet.Group, List: true, Fields: []parquet.Field{
+		{Name: "url", Type: parquet.ByteArray, Logical: parquet.String},
+		{Name: "extent", Type: parquet.Int64},
+		{Name: "modified", Type: parquet.Int64, Logical: parquet.TimestampMillis, Optional: true},
+		{Name: "encoding", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	}},
+	{Name: "bookshelves", Type: parquet.Group, List: true, Fields: []parquet.Field{
+		{Name: "name", Type: parquet.ByteArray, Logical: parquet.String},
+		{Name: "resource", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	}},
+	{Name: "downloads", Type: parquet.Int32},
+}
+
+// ParquetWriter writes ebooks to an Apache Parquet file, with one row per
+// ebook, for loading a full catalog into an analytics database, such as
+// DuckDB. The file is complete only once Close has been called.
+type ParquetWriter struct {
+	w *parquet.Writer
+}
+
+// NewParquetWriter returns a ParquetWriter writing to w.
+func NewParquetWriter(w io.Writer) (*ParquetWriter, error) {
+	pw, err := parquet.NewWriter(w, parquetSchema)
+	if err != nil {
+		return nil, err
+	}
+	return &ParquetWriter{w: pw}, nil
+}
+
+// Write the ebook as a Parquet row.
+func (pw *ParquetWriter) Write(e *pgrdf.Ebook) error {
+	return pw.w.Write(parquetRow(e))
+}
+
+// Close writes any buffered rows and the Parquet file footer. It does not
+// close the underlying writer.
+func (pw *ParquetWriter) Close() error {
+	return pw.w.Close()
+}
+
+// WriteParquetCatalog reads every RDF in the archive and writes them to the
+// ParquetWriter, closing the writer when done. Rows are written in archive
+// order as they are read, so only the current row group is held in memory;
+// use `ORDER BY id` when querying if the eText ID order is needed.
+//
+//	pw, err := archive.NewParquetWriter(file)
+//	err = archive.WriteParquetCatalog(archiveFile, pw)
+func WriteParquetCatalog(archiveFile io.Reader, pw *ParquetWriter) error {
+	err := WalkTarArchive(archiveFile, func(e *pgrdf.Ebook) error {
+		return pw.Write(e)
+	})
+	if err != nil {
+		return err
+	}
+	return pw.Close()
+}
+
+// parquetRow returns the row values of the ebook, in parquetSchema order.
+func parquetRow(e *pgrdf.Ebook) []interface{} {
+	var covers []interface{}
+	for _, c := range e.BookCovers {
+		covers = append(covers, []interface{}{c.Filename, parquetString(c.Source)})
+	}
+
+	var creators []interface{}
+	for _, c := range e.Creators {
+		creators = append(creators, []interface{}{
+			parquetInt(c.ID),
+			c.Name,
+			parquetList(c.Aliases),
+			parquetInt(c.Born),
+			parquetInt(c.Died),
+			parquetString(string(c.Role)),
+			parquetList(c.WebPages),
+		})
+	}
+
+	var subjects []interface{}
+	for _, s := range e.Subjects {
+		subjects = append(subjects, []interface{}{s.Heading, parquetString(s.Schema)})
+	}
+
+	var files []interface{}
+	for _, f := range e.Files {
+		var modified interface{}
+		if t, err := f.ModifiedTime(); err == nil {
+			modified = t.UnixMilli()
+		}
+		files = append(files, []interface{}{f.URL, int64(f.Extent), modified, parquetList(f.Encodings)})
+	}
+
+	var shelves []interface{}
+	for _, b := range e.Bookshelves {
+		shelves = append(shelves, []interface{}{b.Name, parquetString(b.Resource)})
+	}
+
+	var released interface{}
+	if t, err := time.Parse("2006-01-02", e.ReleaseDate); err == nil {
+		released = int32(t.Unix() / 86400)
+	}
+
+	return []interface{}{
+		int32(e.ID),
+		parquetString(string(e.BookType)),
+		parquetList(e.Titles),
+		parquetList(e.AlternateTitles),
+		released,
+		parquetInt(e.PublishedYear),
+		parquetString(e.Publisher),
+		parquetString(e.Summary),
+		parquetString(e.TableOfContents),
+		parquetList(e.Series),
+		parquetList(e.Languages),
+		parquetString(e.LanguageDialect),
+		parquetList(e.LanguageNotes),
+		parquetString(e.PublicationNote),
+		parquetString(e.EditionNote),
+		parquetList(e.ProductionNotes),
+		parquetString(e.Copyright),
+		parquetString(e.CopyrightClearanceCode),
+		parquetList(e.Notes),
+		parquetString(e.PhysicalDescriptionNote),
+		parquetList(e.SourceLinks),
+		parquetString(e.LCCN),
+		parquetString(e.ISBN),
+		parquetString(e.TitlePageImage),
+		parquetString(e.BackCover),
+		covers,
+		creators,
+		subjects,
+		files,
+		shelves,
+		int32(e.Downloads),
+	}
+}
+
+// parquetString returns nil for an empty string, which is written as null.
+func parquetString(s string) interface{} {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+// parquetInt returns nil for a zero value, which is written as null.
+func parquetInt(i int) interface{} {
+	if i == 0 {
+		return nil
+	}
+	return int32(i)
+}
+
+func parquetList(values []string) []interface{} {
+	var list []interface{}
+	for _, v := range values {
+		list = append(list, v)
+	}
+	return list
+}
@@ -0,0 +1,107 @@
+package archive_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/archive"
+)
+
+func TestWriteParquetCatalog(t *testing.T) {
+	file, err := os.Open("../samples/rdf-files-test.tar")
+	if err != nil {
+		t.Fatalf("Unable to open RDF tar archive: %s", err)
+	}
+	defer file.Close()
+
+	w := bytes.NewBuffer([]byte{})
+	pw, err := archive.NewParquetWriter(w)
+	if err != nil {
+		t.Fatalf("unexpected error creating Parquet writer: %s", err)
+	}
+	if err := archive.WriteParquetCatalog(file, pw); err != nil {
+		t.Fatalf("unexpected error writing Parquet: %s", err)
+	}
+	data := w.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatal("expected the PAR1 magic at the start and end of the file")
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-8-size : len(data)-8]
+	for _, name := range []string{"titles", "creators", "born_year", "subjects", "files", "encoding", "downloads"} {
+		if !bytes.Contains(footer, []byte(name)) {
+			t.Errorf("expected column '%s' in the schema", name)
+		}
+	}
+}
+
+var updateFixture = flag.Bool("update-fixture", false, "regenerate the Parquet fixture")
+
+// parquetFixture is the sample archive written as Parquet. Its pages and
+// values are checked by a reader written from the format specification, in
+// the internal/parquet tests. The writer must keep producing the same file,
+// or the fixture must be updated with `go test ./archive -update-fixture`.
+const parquetFixture = "testdata/rdf-files-test.parquet"
+
+func TestWriteParquetCatalog_Fixture(t *testing.T) {
+	file, err := os.Open("../samples/rdf-files-test.tar")
+	if err != nil {
+		t.Fatalf("Unable to open RDF tar archive: %s", err)
+	}
+	defer file.Close()
+
+	w := bytes.NewBuffer([]byte{})
+	pw, err := archive.NewParquetWriter(w)
+	if err != nil {
+		t.Fatalf("unexpected error creating Parquet writer: %s", err)
+	}
+	if err := archive.WriteParquetCatalog(file, pw); err != nil {
+		t.Fatalf("unexpected error writing Parquet: %s", err)
+	}
+
+	if *updateFixture {
+		if err := os.WriteFile(parquetFixture, w.Bytes(), 0o644); err != nil {
+			t.Fatalf("unexpected error updating fixture: %s", err)
+		}
+	}
+	expected, err := os.ReadFile(parquetFixture)
+	if err != nil {
+		t.Fatalf("unable to read fixture: %s", err)
+	}
+	if !bytes.Equal(w.Bytes(), expected) {
+		t.Errorf("Parquet output differs from %s, regenerate with: go test ./archive -run TestWriteParquetCatalog_Fixture -update-fixture", parquetFixture)
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	ebook := &pgrdf.Ebook{
+		ID:          1400,
+		Titles:      []string{"Great Expectations"},
+		ReleaseDate: "1998-07-01",
+		Creators:    []pgrdf.Creator{{Name: "Dickens, Charles", Born: 1812, Died: 1870, Role: pgrdf.RoleAut}},
+		Files:       []pgrdf.File{{URL: "https://www.gutenberg.org/ebooks/1400.epub.images", Extent: 1024, Modified: "2022-07-14T12:00:00"}},
+	}
+
+	w := bytes.NewBuffer([]byte{})
+	pw, err := archive.NewParquetWriter(w)
+	if err != nil {
+		t.Fatalf("unexpected error creating Parquet writer: %s", err)
+	}
+	if err := pw.Write(ebook); err != nil {
+		t.Fatalf("unexpected error writing Parquet: %s", err)
+	}
+	if w.Len() != 0 {
+		t.Errorf("expected rows to be buffered until Close, got %d bytes", w.Len())
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatalf("unexpected error closing Parquet: %s", err)
+	}
+	if !bytes.HasSuffix(w.Bytes(), []byte("PAR1")) {
+		t.Error("expected the PAR1 magic at the end of the file")
+	}
+}
@@ -0,0 +1,66 @@
+package pgrdf
+
+import (
+	"net/url"
+	"strings"
+)
+
+// BookCover is an image acting as a book cover, i.e. this could be a title page.
+// `<pgterms:marc901>`
+type BookCover struct {
+	// The cover filename, relative to the HTML ebook directory, e.g. "images/cover.jpg".
+	Filename string `json:"filename"`
+
+	// The original marc901 value, which is a URL or `file://` URI, e.g.
+	// "file:///files/1400/1400-h/images/cover.jpg". When present this value is
+	// written back to the RDF unchanged.
+	Source string `json:"source,omitempty"`
+}
+
+// NewBookCover creates a BookCover from a marc901 value, keeping the original
+// value along with the filename extracted from the HTML ebook directory path.
+func NewBookCover(source string) BookCover {
+	return BookCover{
+		Filename: bookCoverFilename(source),
+		Source:   source,
+	}
+}
+
+// String returns the value used for the marc901 tag, which is the original
+// Source when present, otherwise the Filename.
+func (c BookCover) String() string {
+	if len(c.Source) > 0 {
+		return c.Source
+	}
+	return c.Filename
+}
+
+// AbsoluteURL resolves the cover against the gutenberg.org base URL, so a
+// `file:///files/...` URI becomes `http://www.gutenberg.org/files/...`.
+// Values which are already absolute web URLs are returned unchanged, and an
+// empty string is returned when no absolute URL can be determined.
+func (c BookCover) AbsoluteURL() string {
+	u, err := url.Parse(c.String())
+	if err != nil {
+		return ""
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return u.String()
+	case "file":
+		base, _ := url.Parse(gutenbergBaseURL)
+		return base.ResolveReference(&url.URL{Path: strings.TrimPrefix(u.Path, "/")}).String()
+	default:
+		return ""
+	}
+}
+
+// bookCoverFilename extracts the book cover filename from the file path.
+// marc901 tags contain a book cover filename from the HTML version of the ebook.
+func bookCoverFilename(cover string) string {
+	parts := strings.Split(cover, "-h")
+	cover = parts[len(parts)-1]
+	cover = strings.TrimPrefix(cover, "/")
+	return cover
+}
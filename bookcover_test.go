@@ -0,0 +1,35 @@
+package pgrdf_test
+
+import (
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+)
+
+func TestNewBookCover(t *testing.T) {
+	cover := pgrdf.NewBookCover("file:///files/1400/1400-h/images/cover.jpg")
+
+	if cover.Filename != "images/cover.jpg" {
+		t.Errorf("unexpected filename: '%s'", cover.Filename)
+	}
+	if cover.Source != "file:///files/1400/1400-h/images/cover.jpg" {
+		t.Errorf("unexpected source: '%s'", cover.Source)
+	}
+}
+
+func TestBookCover_AbsoluteURL(t *testing.T) {
+	cases := []struct {
+		cover    pgrdf.BookCover
+		expected string
+	}{
+		{pgrdf.NewBookCover("file:///files/1400/1400-h/images/cover.jpg"), "http://www.gutenberg.org/files/1400/1400-h/images/cover.jpg"},
+		{pgrdf.NewBookCover("https://www.gutenberg.org/cache/epub/11/pg11.cover.medium.jpg"), "https://www.gutenberg.org/cache/epub/11/pg11.cover.medium.jpg"},
+		{pgrdf.BookCover{Filename: "images/cover.jpg"}, ""},
+	}
+
+	for _, c := range cases {
+		if url := c.cover.AbsoluteURL(); url != c.expected {
+			t.Errorf("expected '%s', got '%s'", c.expected, url)
+		}
+	}
+}
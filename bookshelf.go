@@ -5,7 +5,7 @@ package pgrdf
 type Bookshelf struct {
 	// The bookshelf name.
 	// <rdf:Description><rdf:value>
-	Name string `json:"subject"`
+	Name string `json:"name"`
 
 	// Name of bookshelf at gutenberg.org.
 	// <rdf:Description><dcam:memberOf rdf:resource="2009/pgterms/Bookshelf"/>
@@ -0,0 +1,321 @@
+package catalog
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/internal/idlist"
+)
+
+// Agent is the canonical record of a creator, merged from every ebook that
+// references the agent. The same agent data is repeated in each RDF file, and
+// the copies are not always the same, so the most common value is used for
+// the name and years, and the differing values are listed in Conflicts.
+//
+// Aliases and WebPages are ordered by the lowest eText ID using each value,
+// followed by any other names of the agent, so they do not depend on the
+// order the ebooks were added.
+type Agent struct {
+	ID        int                       `json:"id"`
+	Name      string                    `json:"name"`
+	Aliases   []string                  `json:"aliases,omitempty"`
+	Born      int                       `json:"born_year,omitempty"`
+	Died      int                       `json:"died_year,omitempty"`
+	WebPages  []string                  `json:"webpages,omitempty"`
+	Roles     map[pgrdf.MarcRelator]int `json:"roles"`  // number of ebooks per role
+	EbookIDs  []int                     `json:"ebooks"` // sorted eText IDs
+	Conflicts []AgentConflict           `json:"conflicts,omitempty"`
+}
+
+// AgentConflict lists the different values found for an agent field, by
+// its JSON name, e.g. "born_year", along with the eText IDs using each value.
+type AgentConflict struct {
+	Field  string           `json:"field"`
+	Values map[string][]int `json:"values"`
+}
+
+// AgentRegistry merges the creators of many ebooks by their agent ID.
+// Creators without an agent ID are ignored.
+//
+// An AgentRegistry is not safe for concurrent use.
+type AgentRegistry struct {
+	agents map[int]*agentRecord
+}
+
+// agentRecord collects the values seen for an agent.
+type agentRecord struct {
+	id       int
+	names    *valueCounts
+	born     *valueCounts
+	died     *valueCounts
+	aliases  *listValues
+	webPages *listValues
+	roles    map[pgrdf.MarcRelator][]int
+	ebookIDs []int
+}
+
+// NewAgentRegistry returns a registry of the creators of the given ebooks.
+func NewAgentRegistry(ebooks ...*pgrdf.Ebook) *AgentRegistry {
+	r := &AgentRegistry{agents: make(map[int]*agentRecord)}
+	for _, e := range ebooks {
+		r.Add(e)
+	}
+	return r
+}
+
+// Agents returns a registry of all the creators in the catalog.
+func (c *Catalog) Agents() *AgentRegistry {
+	return NewAgentRegistry(c.All()...)
+}
+
+// Add the creators of the ebook to the registry. Adding the same ebook again
+// does not change the registry.
+func (r *AgentRegistry) Add(e *pgrdf.Ebook) {
+	for _, c := range e.Creators {
+		if c.ID <= 0 {
+			continue
+		}
+		rec, ok := r.agents[c.ID]
+		if !ok {
+			rec = &agentRecord{
+				id:       c.ID,
+				names:    newValueCounts(),
+				born:     newValueCounts(),
+				died:     newValueCounts(),
+				aliases:  newListValues(),
+				webPages: newListValues(),
+				roles:    make(map[pgrdf.MarcRelator][]int),
+			}
+			r.agents[c.ID] = rec
+		}
+		rec.add(e.ID, c)
+	}
+}
+
+// Len returns the number of agents in the registry.
+func (r *AgentRegistry) Len() int {
+	return len(r.agents)
+}
+
+// Get returns the agent with the ID.
+func (r *AgentRegistry) Get(id int) (*Agent, bool) {
+	rec, ok := r.agents[id]
+	if !ok {
+		return nil, false
+	}
+	return rec.agent(), true
+}
+
+// All returns all the agents, ordered by agent ID.
+func (r *AgentRegistry) All() []*Agent {
+	ids := make([]int, 0, len(r.agents))
+	for id := range r.agents {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	agents := make([]*Agent, len(ids))
+	for i, id := range ids {
+		agents[i] = r.agents[id].agent()
+	}
+	return agents
+}
+
+// Conflicts returns the agents having differing names or years across their
+// ebooks, ordered by agent ID.
+func (r *AgentRegistry) Conflicts() []*Agent {
+	var agents []*Agent
+	for _, a := range r.All() {
+		if len(a.Conflicts) > 0 {
+			agents = append(agents, a)
+		}
+	}
+	return agents
+}
+
+// WriteJSON writes all the agents as a JSON array, ordered by agent ID.
+func (r *AgentRegistry) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(r.All(), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (rec *agentRecord) add(ebookID int, c pgrdf.Creator) {
+	role := c.Role
+	if len(role) == 0 {
+		role = pgrdf.RoleAut
+	}
+	rec.roles[role] = idlist.Insert(rec.roles[role], ebookID)
+	rec.ebookIDs = idlist.Insert(rec.ebookIDs, ebookID)
+
+	rec.names.add(strings.TrimSpace(c.Name), ebookID)
+	if c.Born != 0 {
+		rec.born.add(strconv.Itoa(c.Born), ebookID)
+	}
+	if c.Died != 0 {
+		rec.died.add(strconv.Itoa(c.Died), ebookID)
+	}
+	rec.aliases.add(c.Aliases, ebookID)
+	rec.webPages.add(c.WebPages, ebookID)
+}
+
+func (rec *agentRecord) agent() *Agent {
+	a := &Agent{
+		ID:       rec.id,
+		Name:     rec.names.canonical(),
+		Aliases:  rec.aliases.values(),
+		WebPages: rec.webPages.values(),
+		Roles:    make(map[pgrdf.MarcRelator]int, len(rec.roles)),
+		EbookIDs: append([]int{}, rec.ebookIDs...),
+	}
+	a.Born, _ = strconv.Atoi(rec.born.canonical())
+	a.Died, _ = strconv.Atoi(rec.died.canonical())
+	for role, ids := range rec.roles {
+		a.Roles[role] = len(ids)
+	}
+
+	for _, field := range []struct {
+		name   string
+		values *valueCounts
+	}{{"name", rec.names}, {"born_year", rec.born}, {"died_year", rec.died}} {
+		if len(field.values.order) > 1 {
+			a.Conflicts = append(a.Conflicts, AgentConflict{Field: field.name, Values: field.values.ebooks()})
+		}
+	}
+
+	// other names of the agent are kept as aliases, ordered by the lowest
+	// eText ID using them
+	names := append([]string{}, rec.names.order...)
+	sort.Slice(names, func(i, j int) bool {
+		x, y := rec.names.ids[names[i]][0], rec.names.ids[names[j]][0]
+		return x < y || (x == y && names[i] < names[j])
+	})
+	for _, name := range names {
+		if name != a.Name {
+			a.Aliases = appendUnique(a.Aliases, name)
+		}
+	}
+
+	return a
+}
+
+// valueCounts records the eText IDs using each (non-empty) value of a field.
+type valueCounts struct {
+	order []string
+	ids   map[string][]int
+}
+
+func newValueCounts() *valueCounts {
+	return &valueCounts{ids: make(map[string][]int)}
+}
+
+func (v *valueCounts) add(value string, ebookID int) {
+	if len(value) == 0 {
+		return
+	}
+	if _, ok := v.ids[value]; !ok {
+		v.order = append(v.order, value)
+	}
+	v.ids[value] = idlist.Insert(v.ids[value], ebookID)
+}
+
+// canonical returns the value used by the most ebooks, with a tie going to
+// the value used by the ebook with the lowest eText ID, so the result does
+// not depend on the order the ebooks were added.
+func (v *valueCounts) canonical() string {
+	var best string
+	for _, value := range v.order {
+		if len(best) == 0 {
+			best = value
+			continue
+		}
+		n, m := len(v.ids[value]), len(v.ids[best])
+		if n > m || (n == m && v.ids[value][0] < v.ids[best][0]) {
+			best = value
+		}
+	}
+	return best
+}
+
+func (v *valueCounts) ebooks() map[string][]int {
+	values := make(map[string][]int, len(v.ids))
+	for value, ids := range v.ids {
+		values[value] = append([]int{}, ids...)
+	}
+	return values
+}
+
+// listValues records the first use of each (non-empty) value of a list field,
+// so the values can be ordered by the lowest eText ID using them, and then by
+// their position in that ebook, whatever order the ebooks were added in.
+type listValues struct {
+	first map[string]listPosition
+}
+
+type listPosition struct {
+	ebookID int
+	index   int
+}
+
+func newListValues() *listValues {
+	return &listValues{first: make(map[string]listPosition)}
+}
+
+func (l *listValues) add(values []string, ebookID int) {
+	for i, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			continue
+		}
+		if p, ok := l.first[value]; !ok || ebookID < p.ebookID || (ebookID == p.ebookID && i < p.index) {
+			l.first[value] = listPosition{ebookID: ebookID, index: i}
+		}
+	}
+}
+
+func (l *listValues) values() []string {
+	values := make([]string, 0, len(l.first))
+	for value := range l.first {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		x, y := l.first[values[i]], l.first[values[j]]
+		if x.ebookID != y.ebookID {
+			return x.ebookID < y.ebookID
+		}
+		if x.index != y.index {
+			return x.index < y.index
+		}
+		return values[i] < values[j]
+	})
+	return values
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			continue
+		}
+		found := false
+		for _, v := range list {
+			if v == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
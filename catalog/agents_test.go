@@ -0,0 +1,119 @@
+package catalog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/catalog"
+)
+
+func TestAgentRegistry(t *testing.T) {
+	e1 := &pgrdf.Ebook{ID: 1400}
+	e1.AddCreator(pgrdf.Creator{ID: 37, Name: "Dickens, Charles", Aliases: []string{"Boz"}, Born: 1812, Died: 1870, Role: pgrdf.RoleAut})
+	e2 := &pgrdf.Ebook{ID: 98}
+	e2.AddCreator(pgrdf.Creator{ID: 37, Name: "Dickens, Charles", Aliases: []string{"Boz", "Dickens, Charles John Huffam"}, Born: 1812, Died: 1871, WebPages: []string{"https://en.wikipedia.org/wiki/Charles_Dickens"}})
+	e2.AddCreator(pgrdf.Creator{ID: 8397, Name: "Hablot, K. Browne", Role: pgrdf.RoleIll})
+	e3 := &pgrdf.Ebook{ID: 580}
+	e3.AddCreator(pgrdf.Creator{ID: 37, Name: "Dickens, C.", Role: pgrdf.RoleEdt})
+	e3.AddCreator(pgrdf.Creator{Name: "Anonymous"})
+
+	r := catalog.NewAgentRegistry(e1, e2, e3)
+	r.Add(e2) // adding again is ignored
+
+	if r.Len() != 2 {
+		t.Fatalf("expected 2 agents, got %d", r.Len())
+	}
+
+	a, ok := r.Get(37)
+	if !ok {
+		t.Fatal("expected agent 37 to be found")
+	}
+	if a.Name != "Dickens, Charles" {
+		t.Errorf("unexpected name, got '%s'", a.Name)
+	}
+	if !reflect.DeepEqual(a.Aliases, []string{"Boz", "Dickens, Charles John Huffam", "Dickens, C."}) {
+		t.Errorf("unexpected aliases, got %v", a.Aliases)
+	}
+	if a.Born != 1812 || a.Died != 1871 {
+		t.Errorf("unexpected years, got %d-%d", a.Born, a.Died)
+	}
+	if !reflect.DeepEqual(a.EbookIDs, []int{98, 580, 1400}) {
+		t.Errorf("unexpected eText IDs, got %v", a.EbookIDs)
+	}
+	if !reflect.DeepEqual(a.Roles, map[pgrdf.MarcRelator]int{pgrdf.RoleAut: 2, pgrdf.RoleEdt: 1}) {
+		t.Errorf("unexpected roles, got %v", a.Roles)
+	}
+
+	expected := []catalog.AgentConflict{
+		{Field: "name", Values: map[string][]int{"Dickens, Charles": {98, 1400}, "Dickens, C.": {580}}},
+		{Field: "died_year", Values: map[string][]int{"1870": {1400}, "1871": {98}}},
+	}
+	if !reflect.DeepEqual(a.Conflicts, expected) {
+		t.Errorf("unexpected conflicts, got %v", a.Conflicts)
+	}
+
+	conflicts := r.Conflicts()
+	if len(conflicts) != 1 || conflicts[0].ID != 37 {
+		t.Errorf("expected only agent 37 to have conflicts, got %d agents", len(conflicts))
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatalf("unexpected error writing JSON: %s", err)
+	}
+	var agents []catalog.Agent
+	if err := json.Unmarshal(buf.Bytes(), &agents); err != nil {
+		t.Fatalf("unexpected error reading JSON: %s", err)
+	}
+	if len(agents) != 2 || agents[0].ID != 37 || agents[1].ID != 8397 || agents[1].Roles[pgrdf.RoleIll] != 1 {
+		t.Errorf("unexpected JSON agents, got %+v", agents)
+	}
+
+}
+
+func TestAgentRegistry_Order(t *testing.T) {
+	e1 := &pgrdf.Ebook{ID: 1400}
+	e1.AddCreator(pgrdf.Creator{ID: 37, Name: "Dickens, C.", Aliases: []string{"Boz"}, WebPages: []string{"https://example.org/dickens"}})
+	e2 := &pgrdf.Ebook{ID: 98}
+	e2.AddCreator(pgrdf.Creator{ID: 37, Name: "Dickens, Charles", Aliases: []string{"Dickens, Charles John Huffam", "Boz"}, WebPages: []string{"https://en.wikipedia.org/wiki/Charles_Dickens"}})
+	e3 := &pgrdf.Ebook{ID: 580}
+	e3.AddCreator(pgrdf.Creator{ID: 37, Name: "Dickens, Charles"})
+
+	var expected bytes.Buffer
+	if err := catalog.NewAgentRegistry(e1, e2, e3).WriteJSON(&expected); err != nil {
+		t.Fatalf("unexpected error writing JSON: %s", err)
+	}
+	var reversed bytes.Buffer
+	if err := catalog.NewAgentRegistry(e3, e2, e1).WriteJSON(&reversed); err != nil {
+		t.Fatalf("unexpected error writing JSON: %s", err)
+	}
+	if !bytes.Equal(reversed.Bytes(), expected.Bytes()) {
+		t.Errorf("expected the same JSON for any ebook order, got %s", reversed.String())
+	}
+
+	a, _ := catalog.NewAgentRegistry(e1, e2, e3).Get(37)
+	if !reflect.DeepEqual(a.Aliases, []string{"Dickens, Charles John Huffam", "Boz", "Dickens, C."}) {
+		t.Errorf("unexpected aliases, got %v", a.Aliases)
+	}
+	if !reflect.DeepEqual(a.WebPages, []string{"https://en.wikipedia.org/wiki/Charles_Dickens", "https://example.org/dickens"}) {
+		t.Errorf("unexpected webpages, got %v", a.WebPages)
+	}
+}
+
+func TestCatalog_Agents(t *testing.T) {
+	r := testCatalog(t).Agents()
+
+	a, ok := r.Get(37)
+	if !ok {
+		t.Fatal("expected agent 37 to be found")
+	}
+	if !reflect.DeepEqual(a.EbookIDs, []int{5, 1400}) {
+		t.Errorf("unexpected eText IDs, got %v", a.EbookIDs)
+	}
+	if a.Born != 1812 || a.Died != 1870 {
+		t.Errorf("unexpected years, got %d-%d", a.Born, a.Died)
+	}
+}
@@ -0,0 +1,321 @@
+// Package catalog holds a collection of ebooks in memory, with secondary
+// indexes for looking up the ebooks by creator, subject, LCC class, bookshelf,
+// language, release year, and type.
+package catalog
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/archive"
+	"github.com/mrcook/pgrdf/internal/idlist"
+)
+
+// Index is a secondary index of the catalog.
+type Index string
+
+const (
+	IndexCreator     Index = "creator"      // agent ID, e.g. "37"
+	IndexSubject     Index = "subject"      // LCSH heading, e.g. "Orphans -- Fiction"
+	IndexLCC         Index = "lcc"          // LCC class, e.g. "PR"
+	IndexBookshelf   Index = "bookshelf"    // bookshelf name
+	IndexLanguage    Index = "language"     // language code, e.g. "en"
+	IndexReleaseYear Index = "release_year" // year of the release date, e.g. "1998"
+	IndexType        Index = "type"         // book type, e.g. "Text"
+)
+
+// Indexes lists all the secondary indexes.
+var Indexes = []Index{IndexCreator, IndexSubject, IndexLCC, IndexBookshelf, IndexLanguage, IndexReleaseYear, IndexType}
+
+// Catalog is an in-memory collection of ebooks, keyed by eText ID. All query
+// results are ordered by eText ID, so they are stable for the same catalog.
+//
+// A Catalog is safe for concurrent use.
+type Catalog struct {
+	mu      sync.RWMutex
+	ebooks  map[int]*pgrdf.Ebook
+	ids     []int
+	indexes map[Index]map[string][]int
+	keys    map[int]map[Index][]string // the indexed keys of each ebook
+}
+
+// New returns a catalog of the given ebooks.
+func New(ebooks ...*pgrdf.Ebook) *Catalog {
+	c := &Catalog{
+		ebooks:  make(map[int]*pgrdf.Ebook),
+		indexes: make(map[Index]map[string][]int),
+		keys:    make(map[int]map[Index][]string),
+	}
+	for _, index := range Indexes {
+		c.indexes[index] = make(map[string][]int)
+	}
+	for _, e := range ebooks {
+		c.Add(e)
+	}
+	return c
+}
+
+// FromTarArchive returns a catalog of all the ebooks in an RDF archive.
+func FromTarArchive(archiveFile io.Reader) (*Catalog, error) {
+	c := New()
+	err := archive.WalkTarArchive(archiveFile, func(e *pgrdf.Ebook) error {
+		c.Add(e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// FromDirectory returns a catalog of all the ebooks in an extracted RDF
+// archive directory, see `archive.FromDirectory`.
+func FromDirectory(baseDir string) (*Catalog, error) {
+	c := New()
+	err := archive.WalkDirectory(baseDir, func(e *pgrdf.Ebook) error {
+		c.Add(e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Add the ebook to the catalog, replacing any ebook with the same eText ID.
+func (c *Catalog) Add(e *pgrdf.Ebook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.ebooks[e.ID]; ok {
+		c.unindex(e.ID)
+	} else {
+		c.ids = idlist.Insert(c.ids, e.ID)
+	}
+	c.ebooks[e.ID] = e
+	c.index(e)
+}
+
+// Remove the ebook with the eText ID, returning false if it was not found.
+func (c *Catalog) Remove(id int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.ebooks[id]; !ok {
+		return false
+	}
+	c.unindex(id)
+	delete(c.ebooks, id)
+	c.ids = idlist.Remove(c.ids, id)
+
+	return true
+}
+
+// Get returns the ebook with the eText ID. The ebook is shared with the
+// catalog, so any changes must be added again for the indexes to be updated.
+func (c *Catalog) Get(id int) (*pgrdf.Ebook, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := c.ebooks[id]
+	return e, ok
+}
+
+// Len returns the number of ebooks in the catalog.
+func (c *Catalog) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.ids)
+}
+
+// IDs returns the eText IDs of all the ebooks, in ascending order.
+func (c *Catalog) IDs() []int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]int{}, c.ids...)
+}
+
+// All returns all the ebooks, ordered by eText ID.
+func (c *Catalog) All() []*pgrdf.Ebook {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.lookup(c.ids)
+}
+
+// Query returns the ebooks with the key in the index, e.g. all the ebooks
+// with the language "fi".
+func (c *Catalog) Query(index Index, key string) []*pgrdf.Ebook {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.lookup(c.indexes[index][NormaliseKey(index, key)])
+}
+
+// Criterion is a single index key condition of a Find query.
+type Criterion struct {
+	Index Index
+	Key   string
+}
+
+// Find returns the ebooks matching all the criteria, e.g. all the ebooks on
+// a bookshelf in a given language. No criteria returns all ebooks.
+func (c *Catalog) Find(criteria ...Criterion) []*pgrdf.Ebook {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ids := c.ids
+	for _, cr := range criteria {
+		ids = idlist.Intersect(ids, c.indexes[cr.Index][NormaliseKey(cr.Index, cr.Key)])
+	}
+	return c.lookup(ids)
+}
+
+// Keys returns all the keys of the index, sorted by name, e.g. all the
+// bookshelf names.
+func (c *Catalog) Keys(index Index) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.indexes[index]))
+	for key := range c.indexes[index] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Count returns the number of ebooks with the key in the index.
+func (c *Catalog) Count(index Index, key string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.indexes[index][NormaliseKey(index, key)])
+}
+
+// ByCreator returns the ebooks of the agent, e.g. 37 for Charles Dickens.
+func (c *Catalog) ByCreator(agentID int) []*pgrdf.Ebook {
+	return c.Query(IndexCreator, strconv.Itoa(agentID))
+}
+
+// BySubject returns the ebooks with the LCSH subject heading.
+func (c *Catalog) BySubject(heading string) []*pgrdf.Ebook {
+	return c.Query(IndexSubject, heading)
+}
+
+// ByLCC returns the ebooks with the Library of Congress Classification,
+// e.g. "PR".
+func (c *Catalog) ByLCC(class string) []*pgrdf.Ebook {
+	return c.Query(IndexLCC, class)
+}
+
+// ByBookshelf returns the ebooks on the bookshelf.
+func (c *Catalog) ByBookshelf(name string) []*pgrdf.Ebook {
+	return c.Query(IndexBookshelf, name)
+}
+
+// ByLanguage returns the ebooks in the language, e.g. "fi".
+func (c *Catalog) ByLanguage(code string) []*pgrdf.Ebook {
+	return c.Query(IndexLanguage, code)
+}
+
+// ByReleaseYear returns the ebooks released in the year.
+func (c *Catalog) ByReleaseYear(year int) []*pgrdf.Ebook {
+	return c.Query(IndexReleaseYear, strconv.Itoa(year))
+}
+
+// ByType returns the ebooks of the book type.
+func (c *Catalog) ByType(t pgrdf.BookType) []*pgrdf.Ebook {
+	return c.Query(IndexType, string(t))
+}
+
+// IndexKeys returns the keys of the ebook for the index.
+func IndexKeys(e *pgrdf.Ebook, index Index) []string {
+	var keys []string
+	add := func(key string) {
+		if key = NormaliseKey(index, key); len(key) > 0 {
+			keys = append(keys, key)
+		}
+	}
+
+	switch index {
+	case IndexCreator:
+		for _, cr := range e.Creators {
+			if cr.ID > 0 {
+				add(strconv.Itoa(cr.ID))
+			}
+		}
+	case IndexSubject, IndexLCC:
+		for _, s := range e.Subjects {
+			if (s.Schema == pgrdf.SchemaLCC) == (index == IndexLCC) {
+				add(s.Heading)
+			}
+		}
+	case IndexBookshelf:
+		for _, b := range e.Bookshelves {
+			add(b.Name)
+		}
+	case IndexLanguage:
+		for _, lang := range e.Languages {
+			add(lang)
+		}
+	case IndexReleaseYear:
+		if len(e.ReleaseDate) >= 4 {
+			add(e.ReleaseDate[:4])
+		}
+	case IndexType:
+		add(string(e.BookType))
+	}
+	return keys
+}
+
+func (c *Catalog) index(e *pgrdf.Ebook) {
+	keys := make(map[Index][]string, len(Indexes))
+	for _, index := range Indexes {
+		keys[index] = IndexKeys(e, index)
+		for _, key := range keys[index] {
+			c.indexes[index][key] = idlist.Insert(c.indexes[index][key], e.ID)
+		}
+	}
+	c.keys[e.ID] = keys
+}
+
+// unindex removes the eText ID using the keys it was indexed with, as the
+// ebook may have been changed since by a caller of Get.
+func (c *Catalog) unindex(id int) {
+	for index, keys := range c.keys[id] {
+		for _, key := range keys {
+			ids := idlist.Remove(c.indexes[index][key], id)
+			if len(ids) == 0 {
+				delete(c.indexes[index], key)
+			} else {
+				c.indexes[index][key] = ids
+			}
+		}
+	}
+	delete(c.keys, id)
+}
+
+func (c *Catalog) lookup(ids []int) []*pgrdf.Ebook {
+	ebooks := make([]*pgrdf.Ebook, len(ids))
+	for i, id := range ids {
+		ebooks[i] = c.ebooks[id]
+	}
+	return ebooks
+}
+
+// NormaliseKey returns the key as stored in the index: trimmed, and
+// lowercased for language codes.
+func NormaliseKey(index Index, key string) string {
+	key = strings.TrimSpace(key)
+	if index == IndexLanguage {
+		key = strings.ToLower(key)
+	}
+	return key
+}
@@ -0,0 +1,121 @@
+package catalog_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/catalog"
+)
+
+func ids(ebooks []*pgrdf.Ebook) []int {
+	list := []int{}
+	for _, e := range ebooks {
+		list = append(list, e.ID)
+	}
+	return list
+}
+
+func testCatalog(t *testing.T) *catalog.Catalog {
+	t.Helper()
+	file, err := os.Open("../samples/rdf-files-test.tar")
+	if err != nil {
+		t.Fatalf("Unable to open RDF tar archive: %s", err)
+	}
+	defer file.Close()
+
+	c, err := catalog.FromTarArchive(file)
+	if err != nil {
+		t.Fatalf("unexpected error loading catalog: %s", err)
+	}
+
+	extra := &pgrdf.Ebook{ID: 5, BookType: pgrdf.BookTypeText, ReleaseDate: "1998-01-15", Languages: []string{"FI"}}
+	extra.AddCreator(pgrdf.Creator{ID: 37, Name: "Dickens, Charles", Role: pgrdf.RoleAut})
+	extra.AddBookshelf("Best Books Ever Listings", "2009/pgterms/Bookshelf")
+	extra.AddSubject("PR", "http://purl.org/dc/terms/LCC")
+	c.Add(extra)
+
+	return c
+}
+
+func TestCatalog_Queries(t *testing.T) {
+	c := testCatalog(t)
+
+	if c.Len() != 3 || !reflect.DeepEqual(c.IDs(), []int{5, 11, 1400}) {
+		t.Errorf("unexpected eText IDs, got %v", c.IDs())
+	}
+
+	tests := []struct {
+		name     string
+		ebooks   []*pgrdf.Ebook
+		expected []int
+	}{
+		{"creator", c.ByCreator(37), []int{5, 1400}},
+		{"subject", c.BySubject("Orphans -- Fiction"), []int{1400}},
+		{"lcc", c.ByLCC("PR"), []int{5, 11, 1400}},
+		{"bookshelf", c.ByBookshelf("Best Books Ever Listings"), []int{5, 1400}},
+		{"language", c.ByLanguage("fi"), []int{5}},
+		{"year", c.ByReleaseYear(1998), []int{5, 1400}},
+		{"type", c.ByType(pgrdf.BookTypeText), []int{5, 11, 1400}},
+		{"unknown", c.ByLanguage("xx"), []int{}},
+		{"find", c.Find(catalog.Criterion{Index: catalog.IndexCreator, Key: "37"}, catalog.Criterion{Index: catalog.IndexLanguage, Key: "en"}), []int{1400}},
+		{"find all", c.Find(), []int{5, 11, 1400}},
+	}
+	for _, test := range tests {
+		if got := ids(test.ebooks); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("unexpected %s query result, got %v", test.name, got)
+		}
+	}
+
+	if keys := c.Keys(catalog.IndexLanguage); !reflect.DeepEqual(keys, []string{"en", "fi"}) {
+		t.Errorf("unexpected language keys, got %v", keys)
+	}
+	if n := c.Count(catalog.IndexLCC, "PZ"); n != 1 {
+		t.Errorf("expected 1 PZ ebook, got %d", n)
+	}
+}
+
+func TestCatalog_AddRemove(t *testing.T) {
+	c := testCatalog(t)
+
+	// replacing an ebook updates its index entries
+	c.Add(&pgrdf.Ebook{ID: 5, Languages: []string{"de"}})
+	if got := ids(c.ByLanguage("fi")); len(got) != 0 {
+		t.Errorf("expected no fi ebooks after replace, got %v", got)
+	}
+	if got := ids(c.ByCreator(37)); !reflect.DeepEqual(got, []int{1400}) {
+		t.Errorf("unexpected creator ebooks after replace, got %v", got)
+	}
+	if c.Len() != 3 {
+		t.Errorf("expected 3 ebooks, got %d", c.Len())
+	}
+
+	if !c.Remove(5) || c.Remove(5) {
+		t.Error("expected the first remove only to succeed")
+	}
+	if _, ok := c.Get(5); ok {
+		t.Error("expected the ebook to be removed")
+	}
+	for _, key := range c.Keys(catalog.IndexLanguage) {
+		if key == "de" {
+			t.Error("expected empty index keys to be removed")
+		}
+	}
+}
+
+func TestCatalog_AddChangedEbook(t *testing.T) {
+	c := testCatalog(t)
+
+	// an ebook changed in place, and then added again
+	e, _ := c.Get(5)
+	e.Languages = []string{"en"}
+	c.Add(e)
+
+	if got := ids(c.ByLanguage("fi")); len(got) != 0 {
+		t.Errorf("expected no fi ebooks after the change, got %v", got)
+	}
+	if got := ids(c.ByLanguage("en")); !reflect.DeepEqual(got, []int{5, 11, 1400}) {
+		t.Errorf("unexpected en ebooks after the change, got %v", got)
+	}
+}
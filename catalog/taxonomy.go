@@ -0,0 +1,343 @@
+package catalog
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/internal/ns"
+)
+
+// TaxonomyIndexes are the catalog indexes making up the taxonomy.
+var TaxonomyIndexes = []Index{IndexBookshelf, IndexSubject, IndexLCC}
+
+// lcshSeparator separates the subdivisions of an LCSH heading.
+const lcshSeparator = " -- "
+
+// Concept is a bookshelf, LCSH heading, or LCC class of the taxonomy.
+//
+// LCSH headings with subdivisions, e.g. "Orphans -- Fiction", have the heading
+// without the last subdivision as their Broader concept, and LCC classes, e.g.
+// "PR", their main class "P". Broader concepts are part of the taxonomy even
+// when no ebook uses them directly, in which case their Count is 0.
+type Concept struct {
+	Index   Index
+	Label   string
+	Broader string
+	Count   int // number of ebooks using the concept
+}
+
+// Related is a concept used by the same ebooks as another concept, with the
+// Count being the number of ebooks using both.
+type Related struct {
+	Concept Concept
+	Count   int
+}
+
+type conceptKey struct {
+	index Index
+	label string
+}
+
+// Taxonomy collects the bookshelves, LCSH headings and LCC classes of many
+// ebooks, along with the number of ebooks using each, and how often they are
+// used together. Each ebook should only be added once.
+//
+// A Taxonomy is not safe for concurrent use.
+type Taxonomy struct {
+	concepts map[conceptKey]*Concept
+	narrower map[conceptKey][]string
+	related  map[conceptKey]map[conceptKey]int
+}
+
+// NewTaxonomy returns the taxonomy of the given ebooks.
+func NewTaxonomy(ebooks ...*pgrdf.Ebook) *Taxonomy {
+	t := &Taxonomy{
+		concepts: make(map[conceptKey]*Concept),
+		narrower: make(map[conceptKey][]string),
+		related:  make(map[conceptKey]map[conceptKey]int),
+	}
+	for _, e := range ebooks {
+		t.Add(e)
+	}
+	return t
+}
+
+// Taxonomy returns the taxonomy of all the ebooks in the catalog.
+func (c *Catalog) Taxonomy() *Taxonomy {
+	return NewTaxonomy(c.All()...)
+}
+
+// Add the bookshelves and subjects of the ebook to the taxonomy.
+func (t *Taxonomy) Add(e *pgrdf.Ebook) {
+	var keys []conceptKey
+	for _, index := range TaxonomyIndexes {
+		for _, label := range IndexKeys(e, index) {
+			key := conceptKey{index, label}
+			if containsKey(keys, key) {
+				continue
+			}
+			keys = append(keys, key)
+			t.concept(key).Count++
+		}
+	}
+
+	for _, a := range keys {
+		for _, b := range keys {
+			if a == b {
+				continue
+			}
+			if t.related[a] == nil {
+				t.related[a] = make(map[conceptKey]int)
+			}
+			t.related[a][b]++
+		}
+	}
+}
+
+// concept returns the concept of the key, adding it, and any broader
+// concepts, when not found.
+func (t *Taxonomy) concept(key conceptKey) *Concept {
+	if c, ok := t.concepts[key]; ok {
+		return c
+	}
+	c := &Concept{Index: key.index, Label: key.label, Broader: broaderLabel(key)}
+	t.concepts[key] = c
+	if len(c.Broader) > 0 {
+		broader := conceptKey{key.index, c.Broader}
+		t.concept(broader)
+		t.narrower[broader] = append(t.narrower[broader], key.label)
+	}
+	return c
+}
+
+// broaderLabel returns the label of the broader concept, if any.
+func broaderLabel(key conceptKey) string {
+	switch key.index {
+	case IndexSubject:
+		if i := strings.LastIndex(key.label, lcshSeparator); i > 0 {
+			return key.label[:i]
+		}
+	case IndexLCC:
+		if len(key.label) > 1 {
+			return key.label[:1]
+		}
+	}
+	return ""
+}
+
+// Len returns the number of concepts in the taxonomy.
+func (t *Taxonomy) Len() int {
+	return len(t.concepts)
+}
+
+// Get returns the concept with the label, e.g. `Get(catalog.IndexLCC, "PR")`.
+func (t *Taxonomy) Get(index Index, label string) (Concept, bool) {
+	c, ok := t.concepts[conceptKey{index, NormaliseKey(index, label)}]
+	if !ok {
+		return Concept{}, false
+	}
+	return *c, true
+}
+
+// Concepts returns the concepts of the index, sorted by label.
+func (t *Taxonomy) Concepts(index Index) []Concept {
+	var concepts []Concept
+	for key, c := range t.concepts {
+		if key.index == index {
+			concepts = append(concepts, *c)
+		}
+	}
+	sort.Slice(concepts, func(i, j int) bool {
+		return concepts[i].Label < concepts[j].Label
+	})
+	return concepts
+}
+
+// Narrower returns the concepts having the concept as their broader concept,
+// sorted by label.
+func (t *Taxonomy) Narrower(index Index, label string) []Concept {
+	labels := append([]string{}, t.narrower[conceptKey{index, NormaliseKey(index, label)}]...)
+	sort.Strings(labels)
+
+	concepts := make([]Concept, len(labels))
+	for i, l := range labels {
+		concepts[i] = *t.concepts[conceptKey{index, l}]
+	}
+	return concepts
+}
+
+// Related returns the concepts used by the same ebooks as the concept, of any
+// index, with the most used together first.
+func (t *Taxonomy) Related(index Index, label string) []Related {
+	key := conceptKey{index, NormaliseKey(index, label)}
+	var list []Related
+	for other, n := range t.related[key] {
+		list = append(list, Related{Concept: *t.concepts[other], Count: n})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		if list[i].Concept.Index != list[j].Concept.Index {
+			return list[i].Concept.Index < list[j].Concept.Index
+		}
+		return list[i].Concept.Label < list[j].Concept.Label
+	})
+	return list
+}
+
+// CoOccurrence returns the number of ebooks using both concepts.
+func (t *Taxonomy) CoOccurrence(index1 Index, label1 string, index2 Index, label2 string) int {
+	a := conceptKey{index1, NormaliseKey(index1, label1)}
+	b := conceptKey{index2, NormaliseKey(index2, label2)}
+	return t.related[a][b]
+}
+
+func containsKey(keys []conceptKey, key conceptKey) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// schemeTitles are the titles of the SKOS concept schemes, and the path
+// segment used in their IRIs.
+var schemeTitles = map[Index][2]string{
+	IndexBookshelf: {"Project Gutenberg Bookshelves", "bookshelves"},
+	IndexSubject:   {"Library of Congress Subject Headings", "lcsh"},
+	IndexLCC:       {"Library of Congress Classification", "lcc"},
+}
+
+// SKOSOptions for WriteSKOS.
+type SKOSOptions struct {
+	// BaseIRI of the concept schemes and concepts, e.g.
+	// "https://example.org/taxonomy/" gives the bookshelf scheme IRI
+	// "https://example.org/taxonomy/bookshelves" and the concept IRI
+	// "https://example.org/taxonomy/bookshelves/Best%20Books%20Ever%20Listings".
+	// It is required, and must be an absolute IRI ending with "/" or "#".
+	BaseIRI string
+
+	// MinRelated is the minimum number of shared ebooks for two concepts to be
+	// related, using `skos:related` within a concept scheme, and
+	// `skos:relatedMatch` across schemes. Zero does not add any related
+	// concepts.
+	MinRelated int
+}
+
+// WriteSKOS writes the taxonomy as a SKOS RDF/XML document, with a concept
+// scheme each for the bookshelves, LCSH headings and LCC classes. The
+// number of ebooks using a concept is given as its `pgrdf:ebookCount`.
+func (t *Taxonomy) WriteSKOS(w io.Writer, opts SKOSOptions) error {
+	base := opts.BaseIRI
+	if u, err := url.Parse(base); err != nil || !u.IsAbs() || !(strings.HasSuffix(base, "/") || strings.HasSuffix(base, "#")) {
+		return fmt.Errorf("SKOS base IRI must be absolute and end with '/' or '#', got '%s'", base)
+	}
+
+	doc := skosRDF{
+		NsRdf:     ns.Rdf,
+		NsSkos:    ns.Skos,
+		NsDcTerms: ns.DcTerms,
+		NsPgRdf:   ns.PgRdf,
+	}
+
+	iri := func(index Index, label string) string {
+		iri := base + schemeTitles[index][1]
+		if len(label) > 0 {
+			iri += "/" + url.PathEscape(label)
+		}
+		return iri
+	}
+
+	for _, index := range TaxonomyIndexes {
+		doc.Schemes = append(doc.Schemes, skosScheme{About: iri(index, ""), Title: schemeTitles[index][0]})
+
+		for _, c := range t.Concepts(index) {
+			concept := skosConcept{
+				About:    iri(index, c.Label),
+				Label:    c.Label,
+				InScheme: skosResource{iri(index, "")},
+				Count:    &skosInteger{Datatype: ns.Xsd + "integer", Value: strconv.Itoa(c.Count)},
+			}
+			if index == IndexLCC {
+				concept.Notation = c.Label
+			}
+			if len(c.Broader) > 0 {
+				concept.Broader = &skosResource{iri(index, c.Broader)}
+			} else {
+				concept.TopConceptOf = &skosResource{iri(index, "")}
+			}
+			for _, n := range t.Narrower(index, c.Label) {
+				concept.Narrower = append(concept.Narrower, skosResource{iri(index, n.Label)})
+			}
+			if opts.MinRelated > 0 {
+				for _, r := range t.Related(index, c.Label) {
+					if r.Count < opts.MinRelated {
+						continue
+					}
+					related := skosResource{iri(r.Concept.Index, r.Concept.Label)}
+					if r.Concept.Index == index {
+						concept.Related = append(concept.Related, related)
+					} else {
+						concept.RelatedMatch = append(concept.RelatedMatch, related)
+					}
+				}
+			}
+			doc.Concepts = append(doc.Concepts, concept)
+		}
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	_, err = w.Write(data)
+	return err
+}
+
+type skosRDF struct {
+	XMLName   xml.Name      `xml:"rdf:RDF"`
+	NsRdf     string        `xml:"xmlns:rdf,attr"`
+	NsSkos    string        `xml:"xmlns:skos,attr"`
+	NsDcTerms string        `xml:"xmlns:dcterms,attr"`
+	NsPgRdf   string        `xml:"xmlns:pgrdf,attr"`
+	Schemes   []skosScheme  `xml:"skos:ConceptScheme"`
+	Concepts  []skosConcept `xml:"skos:Concept"`
+}
+
+type skosScheme struct {
+	About string `xml:"rdf:about,attr"`
+	Title string `xml:"dcterms:title"`
+}
+
+type skosConcept struct {
+	About        string         `xml:"rdf:about,attr"`
+	Label        string         `xml:"skos:prefLabel"`
+	Notation     string         `xml:"skos:notation,omitempty"`
+	InScheme     skosResource   `xml:"skos:inScheme"`
+	TopConceptOf *skosResource  `xml:"skos:topConceptOf,omitempty"`
+	Broader      *skosResource  `xml:"skos:broader,omitempty"`
+	Narrower     []skosResource `xml:"skos:narrower,omitempty"`
+	Related      []skosResource `xml:"skos:related,omitempty"`
+	RelatedMatch []skosResource `xml:"skos:relatedMatch,omitempty"`
+	Count        *skosInteger   `xml:"pgrdf:ebookCount,omitempty"`
+}
+
+type skosResource struct {
+	Resource string `xml:"rdf:resource,attr"`
+}
+
+type skosInteger struct {
+	Datatype string `xml:"rdf:datatype,attr"`
+	Value    string `xml:",chardata"`
+}
@@ -0,0 +1,133 @@
+package catalog_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/catalog"
+	"github.com/mrcook/pgrdf/internal/triples"
+)
+
+func TestTaxonomy(t *testing.T) {
+	tx := testCatalog(t).Taxonomy()
+
+	c, ok := tx.Get(catalog.IndexLCC, "PR")
+	if !ok || c.Count != 3 || c.Broader != "P" {
+		t.Errorf("unexpected PR concept, got %+v", c)
+	}
+	if c, ok := tx.Get(catalog.IndexLCC, "P"); !ok || c.Count != 0 {
+		t.Errorf("expected a broader P concept with no ebooks, got %+v", c)
+	}
+	if c, ok := tx.Get(catalog.IndexSubject, "Orphans -- Fiction"); !ok || c.Count != 1 || c.Broader != "Orphans" {
+		t.Errorf("unexpected LCSH concept, got %+v", c)
+	}
+
+	var labels []string
+	for _, n := range tx.Narrower(catalog.IndexLCC, "P") {
+		labels = append(labels, n.Label)
+	}
+	if !reflect.DeepEqual(labels, []string{"PR", "PZ"}) {
+		t.Errorf("unexpected narrower concepts, got %v", labels)
+	}
+
+	labels = nil
+	for _, c := range tx.Concepts(catalog.IndexBookshelf) {
+		labels = append(labels, c.Label)
+	}
+	if !reflect.DeepEqual(labels, []string{"Best Books Ever Listings", "Children's Literature"}) {
+		t.Errorf("unexpected bookshelves, got %v", labels)
+	}
+
+	if n := tx.CoOccurrence(catalog.IndexBookshelf, "Best Books Ever Listings", catalog.IndexLCC, "PR"); n != 2 {
+		t.Errorf("expected bookshelf and PR to co-occur twice, got %d", n)
+	}
+	related := tx.Related(catalog.IndexBookshelf, "Best Books Ever Listings")
+	if len(related) == 0 || related[0].Concept.Label != "PR" || related[0].Count != 2 {
+		t.Errorf("unexpected most related concept, got %+v", related)
+	}
+}
+
+func TestTaxonomy_WriteSKOS(t *testing.T) {
+	tx := testCatalog(t).Taxonomy()
+
+	var buf bytes.Buffer
+	err := tx.WriteSKOS(&buf, catalog.SKOSOptions{BaseIRI: "https://example.org/", MinRelated: 2})
+	if err != nil {
+		t.Fatalf("unexpected error writing SKOS: %s", err)
+	}
+
+	g, err := triples.ParseRDFXML(&buf, "")
+	if err != nil {
+		t.Fatalf("unexpected error parsing SKOS: %s", err)
+	}
+
+	const skos = "http://www.w3.org/2004/02/skos/core#"
+	pr := triples.IRI("https://example.org/lcc/PR")
+	if label := g.Object(pr, skos+"prefLabel"); label.Value != "PR" {
+		t.Errorf("unexpected prefLabel, got '%s'", label.Value)
+	}
+	if broader := g.Object(pr, skos+"broader"); broader.Value != "https://example.org/lcc/P" {
+		t.Errorf("unexpected broader concept, got '%s'", broader.Value)
+	}
+	if count := g.Object(pr, "https://github.com/mrcook/pgrdf/ns#ebookCount"); count.Value != "3" {
+		t.Errorf("unexpected ebook count, got '%s'", count.Value)
+	}
+	if top := g.Object(triples.IRI("https://example.org/lcc/P"), skos+"topConceptOf"); top.Value != "https://example.org/lcc" {
+		t.Errorf("unexpected top concept scheme, got '%s'", top.Value)
+	}
+
+	shelf := triples.IRI("https://example.org/bookshelves/Best%20Books%20Ever%20Listings")
+	if related := g.Objects(shelf, skos+"related"); len(related) != 0 {
+		t.Errorf("expected no related concepts in the same scheme, got %v", related)
+	}
+	match := g.Objects(shelf, skos+"relatedMatch")
+	if len(match) != 1 || match[0] != pr {
+		t.Errorf("unexpected related concepts in other schemes, got %v", match)
+	}
+}
+
+func TestTaxonomy_WriteSKOS_BaseIRI(t *testing.T) {
+	tx := testCatalog(t).Taxonomy()
+
+	for _, base := range []string{"", "taxonomy/", "https://example.org/taxonomy"} {
+		var buf bytes.Buffer
+		if err := tx.WriteSKOS(&buf, catalog.SKOSOptions{BaseIRI: base}); err == nil {
+			t.Errorf("expected an error for base IRI '%s'", base)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("expected nothing to be written for base IRI '%s'", base)
+		}
+	}
+}
+
+func TestTaxonomy_WriteSKOS_RelatedInScheme(t *testing.T) {
+	var ebooks []*pgrdf.Ebook
+	for id := 1; id <= 2; id++ {
+		e := &pgrdf.Ebook{ID: id}
+		e.AddSubject("Orphans -- Fiction", "http://purl.org/dc/terms/LCSH")
+		e.AddSubject("Revenge -- Fiction", "http://purl.org/dc/terms/LCSH")
+		ebooks = append(ebooks, e)
+	}
+
+	var buf bytes.Buffer
+	err := catalog.NewTaxonomy(ebooks...).WriteSKOS(&buf, catalog.SKOSOptions{BaseIRI: "https://example.org/", MinRelated: 2})
+	if err != nil {
+		t.Fatalf("unexpected error writing SKOS: %s", err)
+	}
+	g, err := triples.ParseRDFXML(&buf, "")
+	if err != nil {
+		t.Fatalf("unexpected error parsing SKOS: %s", err)
+	}
+
+	const skos = "http://www.w3.org/2004/02/skos/core#"
+	orphans := triples.IRI("https://example.org/lcsh/Orphans%20--%20Fiction")
+	related := g.Objects(orphans, skos+"related")
+	if len(related) != 1 || related[0] != triples.IRI("https://example.org/lcsh/Revenge%20--%20Fiction") {
+		t.Errorf("unexpected related concepts, got %v", related)
+	}
+	if match := g.Objects(orphans, skos+"relatedMatch"); len(match) != 0 {
+		t.Errorf("expected no related concepts in other schemes, got %v", match)
+	}
+}
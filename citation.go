@@ -0,0 +1,248 @@
+package pgrdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// citationKey is the BibTeX key and CSL-JSON id, e.g. "pg1400".
+func (e *Ebook) citationKey() string {
+	return fmt.Sprintf("pg%d", e.ID)
+}
+
+// citationTitle joins the titles as "Main title: Subtitle".
+func (e *Ebook) citationTitle() string {
+	return strings.Join(e.Titles, ": ")
+}
+
+// citationNames returns the creator names for the author, editor, and
+// translator roles, without the parenthesised full forenames.
+func (e *Ebook) citationNames() (authors, editors, translators []string) {
+	for _, c := range e.Creators {
+		name := strings.TrimSpace(nameFullFormRE.ReplaceAllString(c.Name, ""))
+		if len(name) == 0 {
+			continue
+		}
+		switch c.Role {
+		case RoleAut, "":
+			authors = append(authors, name)
+		case RoleEdt:
+			editors = append(editors, name)
+		case RoleTrl:
+			translators = append(translators, name)
+		}
+	}
+	return authors, editors, translators
+}
+
+// WriteBibTeX marshals the Ebook to a BibTeX `@book` entry, for citing the
+// Project Gutenberg edition, and writes it to the provided `io.Writer`.
+//
+// The `date`, `origdate`, and `translator` fields are those used by BibLaTeX,
+// and are ignored by classic BibTeX styles.
+func (e *Ebook) WriteBibTeX(w io.Writer) error {
+	authors, editors, translators := e.citationNames()
+
+	var fields [][2]string
+	add := func(name, value string) {
+		if len(value) > 0 {
+			fields = append(fields, [2]string{name, value})
+		}
+	}
+
+	add("author", strings.Join(authors, " and "))
+	add("editor", strings.Join(editors, " and "))
+	add("translator", strings.Join(translators, " and "))
+	add("title", e.citationTitle())
+	add("edition", e.EditionNote)
+	add("publisher", e.Publisher)
+	add("year", marcYear(e.ReleaseDate))
+	add("date", e.ReleaseDate)
+	if e.PublishedYear > 0 {
+		add("origdate", strconv.Itoa(e.PublishedYear))
+	}
+	add("isbn", e.ISBN)
+	add("language", strings.Join(e.Languages, ", "))
+	add("url", e.URL())
+	add("note", e.PublicationNote)
+
+	b := strings.Builder{}
+	b.WriteString("@book{" + e.citationKey() + ",\n")
+	for i, f := range fields {
+		b.WriteString(fmt.Sprintf("  %s = {%s}", f[0], bibtexEscape(f[1])))
+		if i < len(fields)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// bibtexEscape escapes the LaTeX special characters of a field value.
+func bibtexEscape(value string) string {
+	r := strings.NewReplacer(
+		`\`, `\textbackslash{}`,
+		`{`, `\{`, `}`, `\}`,
+		`&`, `\&`, `%`, `\%`, `$`, `\$`, `#`, `\#`, `_`, `\_`,
+		`~`, `\textasciitilde{}`, `^`, `\textasciicircum{}`,
+	)
+	return r.Replace(value)
+}
+
+// WriteRIS marshals the Ebook to an `EBOOK` type RIS record and writes it to
+// the provided `io.Writer`.
+func (e *Ebook) WriteRIS(w io.Writer) error {
+	authors, editors, translators := e.citationNames()
+
+	b := strings.Builder{}
+	add := func(tag, value string) {
+		if value = strings.TrimSpace(value); len(value) > 0 {
+			b.WriteString(tag + "  - " + strings.ReplaceAll(value, "\n", " ") + "\r\n")
+		}
+	}
+
+	add("TY", "EBOOK")
+	add("ID", e.citationKey())
+	add("TI", e.citationTitle())
+	for _, name := range authors {
+		add("AU", name)
+	}
+	for _, name := range editors {
+		add("A2", name)
+	}
+	for _, name := range translators {
+		add("A4", name)
+	}
+	add("ET", e.EditionNote)
+	add("PY", marcYear(e.ReleaseDate))
+	add("DA", strings.ReplaceAll(e.ReleaseDate, "-", "/"))
+	if e.PublishedYear > 0 {
+		add("OP", strconv.Itoa(e.PublishedYear))
+	}
+	add("PB", e.Publisher)
+	add("SN", e.ISBN)
+	for _, lang := range e.Languages {
+		add("LA", lang)
+	}
+	for _, s := range e.Subjects {
+		add("KW", s.Heading)
+	}
+	add("AB", e.Summary)
+	add("N1", e.PublicationNote)
+	add("UR", e.URL())
+	b.WriteString("ER  - \r\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// cslItem is a CSL-JSON item, see https://citeproc-js.readthedocs.io/en/latest/csl-json/markup.html.
+type cslItem struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	Title        string    `json:"title,omitempty"`
+	Authors      []cslName `json:"author,omitempty"`
+	Editors      []cslName `json:"editor,omitempty"`
+	Translators  []cslName `json:"translator,omitempty"`
+	Edition      string    `json:"edition,omitempty"`
+	Publisher    string    `json:"publisher,omitempty"`
+	Issued       *cslDate  `json:"issued,omitempty"`
+	OriginalDate *cslDate  `json:"original-date,omitempty"`
+	ISBN         string    `json:"ISBN,omitempty"`
+	Language     string    `json:"language,omitempty"`
+	Keyword      string    `json:"keyword,omitempty"`
+	Abstract     string    `json:"abstract,omitempty"`
+	Note         string    `json:"note,omitempty"`
+	URL          string    `json:"URL"`
+	Source       string    `json:"source,omitempty"`
+}
+
+// cslName is a structured name, or a literal when the name is not in the
+// "Family, Given" form.
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// WriteCSLJSON marshals the Ebook to a CSL-JSON document, containing a single
+// `book` item, and writes it to the provided `io.Writer`.
+func (e *Ebook) WriteCSLJSON(w io.Writer) error {
+	authors, editors, translators := e.citationNames()
+
+	item := cslItem{
+		ID:          e.citationKey(),
+		Type:        "book",
+		Title:       e.citationTitle(),
+		Authors:     cslNames(authors),
+		Editors:     cslNames(editors),
+		Translators: cslNames(translators),
+		Edition:     e.EditionNote,
+		Publisher:   e.Publisher,
+		Issued:      cslDateFrom(e.ReleaseDate),
+		ISBN:        e.ISBN,
+		Abstract:    e.Summary,
+		Note:        e.PublicationNote,
+		URL:         e.URL(),
+		Source:      "Project Gutenberg",
+	}
+	if e.PublishedYear > 0 {
+		item.OriginalDate = &cslDate{DateParts: [][]int{{e.PublishedYear}}}
+	}
+	if len(e.Languages) > 0 {
+		item.Language = e.Languages[0]
+	}
+	var keywords []string
+	for _, s := range e.Subjects {
+		keywords = append(keywords, s.Heading)
+	}
+	item.Keyword = strings.Join(keywords, ", ")
+
+	data, err := json.MarshalIndent([]cslItem{item}, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	_, err = w.Write(data)
+	return err
+}
+
+func cslNames(names []string) []cslName {
+	var list []cslName
+	for _, name := range names {
+		parts := strings.SplitN(name, ",", 2)
+		if len(parts) != 2 {
+			list = append(list, cslName{Literal: name})
+			continue
+		}
+		list = append(list, cslName{Family: strings.TrimSpace(parts[0]), Given: strings.TrimSpace(parts[1])})
+	}
+	return list
+}
+
+// cslDateFrom converts an ISO 8601 date, e.g. "2008-06-27", to its date parts.
+func cslDateFrom(date string) *cslDate {
+	var parts []int
+	for _, p := range strings.Split(date, "-") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &cslDate{DateParts: [][]int{parts}}
+}
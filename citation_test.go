@@ -0,0 +1,129 @@
+package pgrdf_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+)
+
+func citationEbook() *pgrdf.Ebook {
+	ebook := generateEbook()
+	ebook.Titles = append(ebook.Titles, "Illustrated")
+	ebook.AddCreator(pgrdf.Creator{ID: 8, Name: "Tenniel, John", Role: pgrdf.RoleIll})
+	ebook.AddCreator(pgrdf.Creator{ID: 9, Name: "Snell, F. J. (Frederick John)", Role: pgrdf.RoleTrl})
+	ebook.AddCreator(pgrdf.Creator{ID: 10, Name: "Anonymous", Role: pgrdf.RoleEdt})
+	return ebook
+}
+
+func TestEbook_WriteBibTeX(t *testing.T) {
+	w := bytes.NewBuffer([]byte{})
+	if err := citationEbook().WriteBibTeX(w); err != nil {
+		t.Fatalf("error writing BibTeX: %s", err)
+	}
+
+	expected := `@book{pg11,
+  author = {Carroll, Lewis},
+  editor = {Anonymous},
+  translator = {Snell, F. J.},
+  title = {Alice's Adventures in Wonderland: Illustrated},
+  edition = {2nd Edition},
+  publisher = {Project Gutenberg},
+  year = {2008},
+  date = {2008-06-27},
+  origdate = {1909},
+  isbn = {978-0-919366-14-5},
+  language = {en},
+  url = {https://www.gutenberg.org/ebooks/11},
+  note = {United Kingdom: J. Johnson, 1794.}
+}
+`
+	if w.String() != expected {
+		t.Errorf("unexpected BibTeX, got:\n%s", w.String())
+	}
+}
+
+func TestEbook_WriteBibTeX_Escaping(t *testing.T) {
+	ebook := &pgrdf.Ebook{ID: 1, Titles: []string{"Profit & Loss: 100% {true}"}}
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteBibTeX(w); err != nil {
+		t.Fatalf("error writing BibTeX: %s", err)
+	}
+	if !strings.Contains(w.String(), `title = {Profit \& Loss: 100\% \{true\}}`) {
+		t.Errorf("expected an escaped title, got:\n%s", w.String())
+	}
+}
+
+func TestEbook_WriteRIS(t *testing.T) {
+	w := bytes.NewBuffer([]byte{})
+	if err := citationEbook().WriteRIS(w); err != nil {
+		t.Fatalf("error writing RIS: %s", err)
+	}
+	data := w.String()
+
+	if !strings.HasPrefix(data, "TY  - EBOOK\r\n") {
+		t.Errorf("expected the record to start with the type, got:\n%s", data)
+	}
+	if !strings.HasSuffix(data, "ER  - \r\n") {
+		t.Errorf("expected the record to end with ER, got:\n%s", data)
+	}
+
+	expected := []string{
+		"TI  - Alice's Adventures in Wonderland: Illustrated\r\n",
+		"AU  - Carroll, Lewis\r\n",
+		"A2  - Anonymous\r\n",
+		"A4  - Snell, F. J.\r\n",
+		"PY  - 2008\r\n",
+		"DA  - 2008/06/27\r\n",
+		"OP  - 1909\r\n",
+		"KW  - Fantasy fiction\r\n",
+		"UR  - https://www.gutenberg.org/ebooks/11\r\n",
+	}
+	for _, s := range expected {
+		if !strings.Contains(data, s) {
+			t.Errorf("expected RIS to contain: %q", s)
+		}
+	}
+	if strings.Contains(data, "Tenniel") {
+		t.Error("expected illustrators to not be included")
+	}
+}
+
+func TestEbook_WriteCSLJSON(t *testing.T) {
+	w := bytes.NewBuffer([]byte{})
+	if err := citationEbook().WriteCSLJSON(w); err != nil {
+		t.Fatalf("error writing CSL-JSON: %s", err)
+	}
+
+	var items []map[string]interface{}
+	if err := json.Unmarshal(w.Bytes(), &items); err != nil {
+		t.Fatalf("invalid JSON: %s", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	item := items[0]
+
+	if item["id"] != "pg11" || item["type"] != "book" {
+		t.Errorf("unexpected id/type, got '%v' and '%v'", item["id"], item["type"])
+	}
+	authors := item["author"].([]interface{})
+	author := authors[0].(map[string]interface{})
+	if author["family"] != "Carroll" || author["given"] != "Lewis" {
+		t.Errorf("unexpected author, got %v", author)
+	}
+	editor := item["editor"].([]interface{})[0].(map[string]interface{})
+	if editor["literal"] != "Anonymous" {
+		t.Errorf("unexpected editor, got %v", editor)
+	}
+
+	data := w.String()
+	for _, s := range []string{`"date-parts": [`, `2008,`, `1909`, `"URL": "https://www.gutenberg.org/ebooks/11"`} {
+		if !strings.Contains(data, s) {
+			t.Errorf("expected CSL-JSON to contain: %s", s)
+		}
+	}
+}
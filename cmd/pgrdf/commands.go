@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/archive"
+)
+
+func runShow(args []string, stdout, stderr io.Writer) error {
+	var src source
+	fs := newFlagSet("show", "<id|file>", stderr)
+	src.flags(fs)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	e, err := src.load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	row := func(label string, values ...string) {
+		for i, v := range values {
+			if len(v) == 0 {
+				continue
+			}
+			if i > 0 {
+				label = ""
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", label, strings.ReplaceAll(v, "\n", " "))
+		}
+	}
+
+	row("eText:", strconv.Itoa(e.ID))
+	row("Title:", e.Titles...)
+	row("Alt. titles:", e.AlternateTitles...)
+	for _, c := range e.Creators {
+		row(creatorLabel(c)+":", creatorName(c))
+	}
+	row("Type:", string(e.BookType))
+	row("Released:", e.ReleaseDate)
+	if e.PublishedYear > 0 {
+		row("Published:", strconv.Itoa(e.PublishedYear))
+	}
+	row("Language:", e.Languages...)
+	row("Series:", e.Series...)
+	var subjects []string
+	for _, s := range e.Subjects {
+		subjects = append(subjects, s.Heading)
+	}
+	row("Subjects:", subjects...)
+	var shelves []string
+	for _, b := range e.Bookshelves {
+		shelves = append(shelves, b.Name)
+	}
+	row("Bookshelves:", shelves...)
+	row("Summary:", e.Summary)
+	row("Rights:", e.Copyright)
+	row("Downloads:", strconv.Itoa(e.Downloads))
+	row("URL:", e.URL())
+	row("Files:", strconv.Itoa(len(e.Files)))
+
+	return tw.Flush()
+}
+
+func creatorLabel(c pgrdf.Creator) string {
+	term := c.Role.Term()
+	if len(term) == 0 {
+		term = pgrdf.RoleAut.Term()
+	}
+	return strings.ToUpper(term[:1]) + term[1:]
+}
+
+func creatorName(c pgrdf.Creator) string {
+	if c.Born == 0 && c.Died == 0 {
+		return c.Name
+	}
+	year := func(y int) string {
+		if y == 0 {
+			return "?"
+		}
+		return strconv.Itoa(y)
+	}
+	return fmt.Sprintf("%s (%s-%s)", c.Name, year(c.Born), year(c.Died))
+}
+
+// converters are the output formats of the convert command.
+var converters = map[string]func(e *pgrdf.Ebook, w io.Writer) error{
+	"json":    (*pgrdf.Ebook).WriteJSON,
+	"jsonld":  (*pgrdf.Ebook).WriteJSONLD,
+	"rdf":     (*pgrdf.Ebook).WriteRDF,
+	"ttl":     (*pgrdf.Ebook).WriteTurtle,
+	"nt":      (*pgrdf.Ebook).WriteNTriples,
+	"marcxml": (*pgrdf.Ebook).WriteMARCXML,
+	"marc21":  (*pgrdf.Ebook).WriteMARC21,
+	"oaidc":   (*pgrdf.Ebook).WriteOAIDC,
+	"opf":     (*pgrdf.Ebook).WriteOPFMetadata,
+	"bibtex":  (*pgrdf.Ebook).WriteBibTeX,
+	"ris":     (*pgrdf.Ebook).WriteRIS,
+	"csljson": (*pgrdf.Ebook).WriteCSLJSON,
+}
+
+func runConvert(args []string, stdout, stderr io.Writer) error {
+	var formats []string
+	for name := range converters {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+
+	var src source
+	fs := newFlagSet("convert", "<id|file>", stderr)
+	src.flags(fs)
+	to := fs.String("to", "json", "output `format`: "+strings.Join(formats, ", "))
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	convert, ok := converters[*to]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown format '%s', expected one of: %s\n", *to, strings.Join(formats, ", "))
+		return errUsage
+	}
+
+	e, err := src.load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return convert(e, stdout)
+}
+
+func runValidate(args []string, stdout, stderr io.Writer) error {
+	var src source
+	fs := newFlagSet("validate", "[<id|file> ...]", stderr)
+	src.flags(fs)
+	if err := parseFlags(fs, args, 0, -1); err != nil {
+		return err
+	}
+
+	var checked, invalid int
+	check := func(name string, e *pgrdf.Ebook) error {
+		checked++
+		problems := validate(e)
+		if len(problems) > 0 {
+			invalid++
+		}
+		for _, p := range problems {
+			fmt.Fprintf(stdout, "%s: %s\n", name, p)
+		}
+		return nil
+	}
+
+	if fs.NArg() == 0 {
+		err := src.walk(func(e *pgrdf.Ebook) error {
+			return check(fmt.Sprintf("pg%d", e.ID), e)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, arg := range fs.Args() {
+		e, err := src.load(arg)
+		if err != nil {
+			fmt.Fprintf(stdout, "%s: %s\n", arg, err)
+			checked++
+			invalid++
+			continue
+		}
+		_ = check(arg, e)
+	}
+
+	fmt.Fprintf(stderr, "%d checked, %d with problems\n", checked, invalid)
+	if invalid > 0 {
+		return errInvalid
+	}
+	return nil
+}
+
+// validate returns the problems found in the ebook metadata.
+func validate(e *pgrdf.Ebook) []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if e.ID <= 0 {
+		add("missing eText ID")
+	}
+	if len(e.Titles) == 0 {
+		add("missing title")
+	}
+	if _, err := time.Parse("2006-01-02", e.ReleaseDate); err != nil {
+		add("invalid release date '%s'", e.ReleaseDate)
+	}
+	switch e.BookType {
+	case pgrdf.BookTypeCollection, pgrdf.BookTypeDataset, pgrdf.BookTypeImage, pgrdf.BookTypeMovingImage,
+		pgrdf.BookTypeSound, pgrdf.BookTypeStillImage, pgrdf.BookTypeText:
+	default:
+		add("unknown type '%s'", e.BookType)
+	}
+	if len(e.Languages) == 0 {
+		add("missing language")
+	}
+	for i, c := range e.Creators {
+		if len(strings.TrimSpace(c.Name)) == 0 {
+			add("creator #%d (agent %d) has no name", i+1, c.ID)
+		}
+		if len(c.Role) > 0 && len(c.Role.Term()) == 0 {
+			add("creator #%d has an unknown role '%s'", i+1, c.Role)
+		}
+		if c.Born != 0 && c.Died != 0 && c.Died < c.Born {
+			add("creator #%d died (%d) before being born (%d)", i+1, c.Died, c.Born)
+		}
+	}
+	for i, s := range e.Subjects {
+		if len(strings.TrimSpace(s.Heading)) == 0 {
+			add("subject #%d has no heading", i+1)
+		}
+	}
+	for i, f := range e.Files {
+		if len(f.URL) == 0 {
+			add("file #%d has no URL", i+1)
+		}
+		if _, err := f.ModifiedTime(); len(f.Modified) > 0 && err != nil {
+			add("file #%d has an invalid modified date '%s'", i+1, f.Modified)
+		}
+	}
+
+	return problems
+}
+
+func runDiff(args []string, stdout, stderr io.Writer) error {
+	var src source
+	fs := newFlagSet("diff", "<id|file> <id|file>", stderr)
+	src.flags(fs)
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	var ebooks [2]*pgrdf.Ebook
+	for i := range ebooks {
+		e, err := src.load(fs.Arg(i))
+		if err != nil {
+			return err
+		}
+		ebooks[i] = e
+	}
+
+	changes := pgrdf.Diff(ebooks[0], ebooks[1])
+	for _, c := range changes {
+		switch c.Op {
+		case pgrdf.ChangeAdd:
+			fmt.Fprintf(stdout, "+ %s: %s\n", c.Path, changeValue(c.Value))
+		case pgrdf.ChangeRemove:
+			fmt.Fprintf(stdout, "- %s: %s\n", c.Path, changeValue(c.Old))
+		default:
+			fmt.Fprintf(stdout, "~ %s: %s => %s\n", c.Path, changeValue(c.Old), changeValue(c.Value))
+		}
+	}
+
+	if len(changes) > 0 {
+		return errInvalid
+	}
+	return nil
+}
+
+// changeValue formats a change value as JSON.
+func changeValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func runExtract(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("extract", "<archive.tar> <dir>", stderr)
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	file, err := openArchive(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := archive.Extract(file, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "%d added, %d updated, %d removed, %d unchanged\n",
+		len(result.Added), len(result.Updated), len(result.Removed), result.Unchanged)
+	return nil
+}
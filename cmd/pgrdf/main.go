@@ -0,0 +1,229 @@
+// Command pgrdf reads, converts, and validates Project Gutenberg RDF files,
+// either individually or from an RDF archive (a .tar file or directory).
+//
+//	pgrdf show --tar rdf-files.tar 1400
+//	pgrdf convert --to json pg1400.rdf
+//	pgrdf validate --dir rdf_files
+//	pgrdf diff old/pg1400.rdf new/pg1400.rdf
+//	pgrdf extract rdf-files.tar rdf_files
+package main
+
+import (
+	"compress/bzip2"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/archive"
+)
+
+const usage = `Usage: pgrdf <command> [flags] [arguments]
+
+Commands:
+  show      [--tar FILE | --dir DIR] <id|file>   print a summary of an ebook
+  convert   --to FORMAT [--tar FILE | --dir DIR] <id|file>
+                                                 convert an ebook to another format
+  validate  [--tar FILE | --dir DIR] [<id|file> ...]
+                                                 check ebooks for missing or invalid metadata
+  diff      [--tar FILE | --dir DIR] <id|file> <id|file>
+                                                 list the differences between two ebooks
+  extract   <archive.tar> <dir>                  unpack an RDF archive to a directory
+
+An eText ID is looked up in the --tar archive or --dir directory. A file is read
+using its extension: .json, .xml (MARCXML), .mrc (MARC 21), .opf, .epub, with
+any other being read as RDF. Use "-" to read RDF from stdin.
+
+Run 'pgrdf <command> --help' for the flags of a command.
+`
+
+// errUsage is returned by a command for invalid arguments, after the usage of
+// the command has been printed.
+var errUsage = errors.New("invalid arguments")
+
+// errInvalid is returned when the command was successful, but the result is
+// negative, e.g. validation problems were found.
+var errInvalid = errors.New("invalid")
+
+type command func(args []string, stdout, stderr io.Writer) error
+
+var commands = map[string]command{
+	"show":     runShow,
+	"convert":  runConvert,
+	"validate": runValidate,
+	"diff":     runDiff,
+	"extract":  runExtract,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run the command given in the arguments, returning the exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "pgrdf: unknown command '%s'\n\n%s", args[0], usage)
+		return 2
+	}
+
+	switch err := cmd(args[1:], stdout, stderr); {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	case errors.Is(err, errInvalid):
+		return 1
+	default:
+		fmt.Fprintf(stderr, "pgrdf %s: %s\n", args[0], err)
+		return 1
+	}
+}
+
+// newFlagSet returns the flags for a command, with errors and usage being
+// written to stderr.
+func newFlagSet(name, arguments string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: pgrdf %s [flags] %s\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses the command flags, checking the number of positional
+// arguments is between min and max, with a negative max being unlimited.
+func parseFlags(fs *flag.FlagSet, args []string, min, max int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() < min || (max >= 0 && fs.NArg() > max) {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// source of the ebooks to look up by eText ID.
+type source struct {
+	tarFile string
+	baseDir string
+}
+
+func (s *source) flags(fs *flag.FlagSet) {
+	fs.StringVar(&s.tarFile, "tar", "", "RDF `archive` to look up eText IDs (.tar or .tar.bz2)")
+	fs.StringVar(&s.baseDir, "dir", "", "directory of extracted RDF files to look up eText IDs")
+}
+
+// load the ebook for the argument, which is either an eText ID or a filename.
+func (s *source) load(arg string) (*pgrdf.Ebook, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return readFile(arg)
+	}
+
+	switch {
+	case len(s.baseDir) > 0:
+		return archive.FromDirectory(s.baseDir, id)
+	case len(s.tarFile) > 0:
+		file, err := openArchive(s.tarFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return archive.FromTarArchive(file, id)
+	default:
+		return nil, fmt.Errorf("a --tar or --dir flag is required to look up eText ID '%d'", id)
+	}
+}
+
+// walk calls fn for every ebook in the source.
+func (s *source) walk(fn func(e *pgrdf.Ebook) error) error {
+	switch {
+	case len(s.baseDir) > 0:
+		return archive.WalkDirectory(s.baseDir, fn)
+	case len(s.tarFile) > 0:
+		file, err := openArchive(s.tarFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return archive.WalkTarArchive(file, fn)
+	default:
+		return errors.New("a --tar or --dir flag is required")
+	}
+}
+
+// openArchive opens a tar archive, decompressing it if it is a .tar.bz2 file.
+func openArchive(name string) (io.ReadCloser, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(name, ".bz2") {
+		return struct {
+			io.Reader
+			io.Closer
+		}{bzip2.NewReader(file), file}, nil
+	}
+	return file, nil
+}
+
+// readFile reads an ebook using the format of the file extension.
+func readFile(name string) (*pgrdf.Ebook, error) {
+	if name == "-" {
+		return pgrdf.ReadRDF(os.Stdin)
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".epub" {
+		return pgrdf.ReadEPUBFile(name)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch ext {
+	case ".json":
+		return pgrdf.ReadJSON(file)
+	case ".opf":
+		return pgrdf.ReadOPF(file)
+	case ".xml", ".mrc":
+		read := pgrdf.ReadMARCXML
+		if ext == ".mrc" {
+			read = pgrdf.ReadMARC21
+		}
+		ebooks, err := read(file)
+		if err != nil {
+			return nil, err
+		}
+		if len(ebooks) == 0 {
+			return nil, fmt.Errorf("no records found in '%s'", name)
+		}
+		return ebooks[0], nil
+	default:
+		return pgrdf.ReadRDF(file)
+	}
+}
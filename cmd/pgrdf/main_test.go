@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testArchive = "../../samples/rdf-files-test.tar"
+
+func runTest(args ...string) (int, string, string) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	status := run(args, &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestRun_Usage(t *testing.T) {
+	if status, _, stderr := runTest(); status != 2 || !strings.Contains(stderr, "Commands:") {
+		t.Errorf("expected usage with status 2, got %d", status)
+	}
+	if status, _, stderr := runTest("unknown"); status != 2 || !strings.Contains(stderr, "unknown command 'unknown'") {
+		t.Errorf("unexpected unknown command status %d, got '%s'", status, stderr)
+	}
+	if status, _, _ := runTest("show"); status != 2 {
+		t.Errorf("expected status 2 for missing arguments, got %d", status)
+	}
+}
+
+func TestRun_Show(t *testing.T) {
+	status, stdout, stderr := runTest("show", "--tar", testArchive, "11")
+	if status != 0 {
+		t.Fatalf("unexpected status %d: %s", status, stderr)
+	}
+	for _, expected := range []string{"Alice's Adventures in Wonderland", "Author:", "Carroll, Lewis (1832-1898)", "Released:     2008-06-27"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("expected '%s' in output, got:\n%s", expected, stdout)
+		}
+	}
+
+	if status, _, stderr := runTest("show", "11"); status != 1 || !strings.Contains(stderr, "--tar or --dir flag is required") {
+		t.Errorf("expected a source error, got %d '%s'", status, stderr)
+	}
+}
+
+func TestRun_Convert(t *testing.T) {
+	status, stdout, stderr := runTest("convert", "--to", "json", "../../samples/cache/epub/999991234/pg999991234.rdf")
+	if status != 0 {
+		t.Fatalf("unexpected status %d: %s", status, stderr)
+	}
+	if !strings.Contains(stdout, `"id":999991234`) {
+		t.Errorf("unexpected JSON output, got:\n%s", stdout)
+	}
+
+	if status, _, stderr := runTest("convert", "--to", "docx", "11"); status != 2 || !strings.Contains(stderr, "unknown format 'docx'") {
+		t.Errorf("expected an unknown format error, got %d '%s'", status, stderr)
+	}
+}
+
+func TestRun_ValidateAndDiff(t *testing.T) {
+	if status, stdout, stderr := runTest("validate", "--tar", testArchive); status != 0 {
+		t.Errorf("unexpected status %d: %s%s", status, stdout, stderr)
+	}
+
+	dir := t.TempDir()
+	status, rdf, stderr := runTest("convert", "--to", "rdf", "--tar", testArchive, "1400")
+	if status != 0 {
+		t.Fatalf("unexpected status %d: %s", status, stderr)
+	}
+	valid, file := filepath.Join(dir, "pg1400.rdf"), filepath.Join(dir, "invalid.rdf")
+	invalid := strings.Replace(rdf, ">1998-07-01<", ">01/07/1998<", 1)
+	if err := os.WriteFile(valid, []byte(rdf), 0o644); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+	if err := os.WriteFile(file, []byte(invalid), 0o644); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+
+	status, stdout, _ := runTest("validate", file)
+	if status != 1 || !strings.Contains(stdout, "invalid release date '01/07/1998'") {
+		t.Errorf("expected an invalid release date, got %d '%s'", status, stdout)
+	}
+
+	status, stdout, _ = runTest("diff", valid, file)
+	if status != 1 || strings.TrimSpace(stdout) != `~ released: "1998-07-01" => "01/07/1998"` {
+		t.Errorf("unexpected diff, got %d '%s'", status, stdout)
+	}
+	if status, stdout, _ := runTest("diff", file, file); status != 0 || len(stdout) > 0 {
+		t.Errorf("expected no differences, got %d '%s'", status, stdout)
+	}
+}
+
+func TestRun_Extract(t *testing.T) {
+	dir := t.TempDir()
+	if status, _, stderr := runTest("extract", testArchive, dir); status != 0 {
+		t.Fatalf("unexpected status %d: %s", status, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cache", "epub", "1400", "pg1400.rdf")); err != nil {
+		t.Errorf("expected the RDF file to be extracted: %s", err)
+	}
+
+	status, stdout, _ := runTest("show", "--dir", dir, "1400")
+	if status != 0 || !strings.Contains(stdout, "Great Expectations") {
+		t.Errorf("unexpected show from extracted directory, got %d '%s'", status, stdout)
+	}
+}
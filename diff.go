@@ -0,0 +1,429 @@
+package pgrdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ChangeOp is the operation of a Change.
+type ChangeOp string
+
+const (
+	ChangeAdd     ChangeOp = "add"
+	ChangeRemove  ChangeOp = "remove"
+	ChangeReplace ChangeOp = "replace"
+)
+
+// Change is a single field-level difference between two ebooks.
+//
+// The Path uses the JSON field names, with list elements identified by a
+// quoted key rather than their index, so paths stay stable when a list is
+// reordered. Strings are keyed by their value, creators by their agent ID
+// (plus the role when an agent has more than one role), subjects by heading,
+// files by URL, bookshelves by name, book covers by filename, and author
+// links by URL. For example:
+//
+//	released
+//	titles["Great Expectations"]
+//	creators["37"].role
+//	files["https://www.gutenberg.org/ebooks/1400.epub.images"].extent
+//
+// An `add` has the added Value, which Apply appends to the list, a `remove`
+// the removed Old value, and a `replace` both. The titles, creators and
+// languages are ordered, so when a change of order can not be made by adding
+// and removing elements, the whole list is replaced, with the path `titles`.
+type Change struct {
+	Op    ChangeOp    `json:"op"`
+	Path  string      `json:"path"`
+	Old   interface{} `json:"old,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ChangeSet is the JSON document of the changes made to an ebook, for example
+// for an audit log.
+type ChangeSet struct {
+	ID      int      `json:"id"`
+	Changes []Change `json:"changes"`
+}
+
+// WriteJSON marshals the change set to JSON and writes it to the provided
+// `io.Writer`.
+func (cs *ChangeSet) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(cs, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	_, err = w.Write(data)
+	return err
+}
+
+// ReadChangeSet reads a JSON change set, as written by `ChangeSet.WriteJSON`.
+// The change values are decoded to their JSON types, and are converted to
+// the Ebook field types by Apply.
+func ReadChangeSet(r io.Reader) (*ChangeSet, error) {
+	cs := &ChangeSet{}
+	if err := json.NewDecoder(r).Decode(cs); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// orderedLists are the lists where the position of an element carries
+// meaning, e.g. the first title is the main title, and the first language
+// the primary language.
+var orderedLists = map[string]bool{
+	"titles":    true,
+	"creators":  true,
+	"languages": true,
+}
+
+// Diff returns the field-level changes needed to turn ebook a into ebook b.
+// Lists are compared as sets, so a reordering of elements is not a change,
+// except for the titles, creators and languages, where the order matters.
+// These are replaced as a whole when their elements can not be added and
+// removed to give the new order.
+func Diff(a, b *Ebook) []Change {
+	var changes []Change
+	diffStruct(&changes, "", reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem())
+	return changes
+}
+
+func diffStruct(changes *[]Change, prefix string, a, b reflect.Value) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := changeFieldName(t.Field(i))
+		if len(name) == 0 {
+			continue
+		}
+		diffValue(changes, prefix+name, a.Field(i), b.Field(i))
+	}
+}
+
+func diffValue(changes *[]Change, path string, a, b reflect.Value) {
+	if a.Kind() != reflect.Slice {
+		if a.Interface() != b.Interface() {
+			*changes = append(*changes, Change{Op: ChangeReplace, Path: path, Old: a.Interface(), Value: b.Interface()})
+		}
+		return
+	}
+
+	keysA, keysB := elementKeys(a, b)
+	if orderedLists[path] && !appendOrder(keysA, keysB) {
+		*changes = append(*changes, Change{Op: ChangeReplace, Path: path, Old: a.Interface(), Value: b.Interface()})
+		return
+	}
+
+	indexB := make(map[string]int, len(keysB))
+	for i, key := range keysB {
+		if _, ok := indexB[key]; !ok {
+			indexB[key] = i
+		}
+	}
+	seen := make(map[string]bool, len(keysA))
+
+	for i, key := range keysA {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		elementPath := path + "[" + strconv.Quote(key) + "]"
+		j, ok := indexB[key]
+		switch {
+		case !ok:
+			*changes = append(*changes, Change{Op: ChangeRemove, Path: elementPath, Old: a.Index(i).Interface()})
+		case a.Index(i).Kind() == reflect.Struct:
+			diffStruct(changes, elementPath+".", a.Index(i), b.Index(j))
+		}
+	}
+	for j, key := range keysB {
+		if !seen[key] {
+			seen[key] = true
+			*changes = append(*changes, Change{Op: ChangeAdd, Path: path + "[" + strconv.Quote(key) + "]", Value: b.Index(j).Interface()})
+		}
+	}
+}
+
+// appendOrder reports whether removing the elements of a not in b, and then
+// appending those of b not in a, as done by Apply, gives the order of b.
+func appendOrder(keysA, keysB []string) bool {
+	inA := make(map[string]bool, len(keysA))
+	for _, key := range keysA {
+		inA[key] = true
+	}
+	inB := make(map[string]bool, len(keysB))
+	for _, key := range keysB {
+		inB[key] = true
+	}
+
+	var order []string
+	for _, key := range keysA {
+		if inB[key] {
+			order = append(order, key)
+		}
+	}
+	for _, key := range keysB {
+		if !inA[key] {
+			order = append(order, key)
+		}
+	}
+	return reflect.DeepEqual(order, keysB)
+}
+
+// elementKeys returns the keys of the list elements. Creators are keyed by
+// their agent ID, with the role added for agents having more than one role in
+// either list, so the same keys are used for both.
+func elementKeys(a, b reflect.Value) ([]string, []string) {
+	creatorsA, ok := a.Interface().([]Creator)
+	if !ok {
+		return listKeys(a), listKeys(b)
+	}
+	creatorsB := b.Interface().([]Creator)
+
+	count := make(map[string][2]int)
+	for i, creators := range [][]Creator{creatorsA, creatorsB} {
+		for _, c := range creators {
+			n := count[creatorAgentKey(c)]
+			n[i]++
+			count[creatorAgentKey(c)] = n
+		}
+	}
+	keys := func(creators []Creator) []string {
+		list := make([]string, len(creators))
+		for i, c := range creators {
+			key := creatorAgentKey(c)
+			if n := count[key]; n[0] > 1 || n[1] > 1 {
+				key += ":" + string(c.Role)
+			}
+			list[i] = key
+		}
+		return list
+	}
+	return keys(creatorsA), keys(creatorsB)
+}
+
+func listKeys(list reflect.Value) []string {
+	keys := make([]string, list.Len())
+	for i := range keys {
+		keys[i] = elementKey(list.Index(i).Interface())
+	}
+	return keys
+}
+
+// elementKey returns the key of a list element, which identifies it in a path.
+func elementKey(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case Creator:
+		return creatorAgentKey(v)
+	case Subject:
+		return v.Heading
+	case File:
+		return v.URL
+	case Bookshelf:
+		return v.Name
+	case BookCover:
+		return v.Filename
+	case AuthorLink:
+		return v.URL
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// creatorAgentKey is the agent ID, or the name for creators without an ID.
+func creatorAgentKey(c Creator) string {
+	if c.ID > 0 {
+		return strconv.Itoa(c.ID)
+	}
+	return c.Name
+}
+
+// Apply the changes to the ebook, as returned by Diff, or read from a JSON
+// change set. Apply stops at the first change that can not be applied, for
+// example when removing a list element which does not exist.
+func Apply(e *Ebook, changes []Change) error {
+	for _, c := range changes {
+		if err := applyChange(reflect.ValueOf(e).Elem(), c); err != nil {
+			return fmt.Errorf("%s %s: %w", c.Op, c.Path, err)
+		}
+	}
+	return nil
+}
+
+func applyChange(v reflect.Value, c Change) error {
+	segments, err := parseChangePath(c.Path)
+	if err != nil {
+		return err
+	}
+
+	for i, seg := range segments {
+		field, ok := structField(v, seg.name)
+		if !ok {
+			return fmt.Errorf("unknown field '%s'", seg.name)
+		}
+		last := i == len(segments)-1
+
+		if !seg.keyed {
+			if !last {
+				return fmt.Errorf("field '%s' is not a list", seg.name)
+			}
+			if c.Op != ChangeReplace {
+				return fmt.Errorf("only a replace is possible for field '%s'", seg.name)
+			}
+			return setValue(field, c.Value)
+		}
+
+		if field.Kind() != reflect.Slice {
+			return fmt.Errorf("field '%s' is not a list", seg.name)
+		}
+		index := findElement(field, seg.key)
+
+		if !last {
+			if index < 0 {
+				return fmt.Errorf("element [%q] not found", seg.key)
+			}
+			v = field.Index(index)
+			continue
+		}
+
+		switch c.Op {
+		case ChangeAdd:
+			if index >= 0 {
+				return fmt.Errorf("element [%q] already exists", seg.key)
+			}
+			el := reflect.New(field.Type().Elem()).Elem()
+			if err := setValue(el, c.Value); err != nil {
+				return err
+			}
+			field.Set(reflect.Append(field, el))
+		case ChangeRemove:
+			if index < 0 {
+				return fmt.Errorf("element [%q] not found", seg.key)
+			}
+			if field.Len() == 1 {
+				field.Set(reflect.Zero(field.Type())) // as read from an RDF file
+				break
+			}
+			field.Set(reflect.AppendSlice(field.Slice(0, index), field.Slice(index+1, field.Len())))
+		default:
+			return fmt.Errorf("a list element can only be added or removed")
+		}
+	}
+	return nil
+}
+
+// findElement returns the index of the element with the key, or -1. A
+// creator key may also include the role, e.g. "37:edt".
+func findElement(list reflect.Value, key string) int {
+	for i := 0; i < list.Len(); i++ {
+		el := list.Index(i).Interface()
+		if elementKey(el) == key {
+			return i
+		}
+		if c, ok := el.(Creator); ok && creatorAgentKey(c)+":"+string(c.Role) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// setValue sets the value, converting it to the field type using its JSON
+// encoding, as values read from a change set have the JSON types.
+func setValue(field reflect.Value, value interface{}) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if v := reflect.ValueOf(value); v.Type() == field.Type() {
+		field.Set(v)
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	target := reflect.New(field.Type())
+	if err := json.Unmarshal(data, target.Interface()); err != nil {
+		return err
+	}
+	field.Set(target.Elem())
+	return nil
+}
+
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if changeFieldName(t.Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// changeFieldName returns the JSON name of the field, or an empty string for
+// a field which is not part of the JSON document.
+func changeFieldName(f reflect.StructField) string {
+	name, _ := jsonFieldName(f)
+	if !f.IsExported() || name == "-" {
+		return ""
+	}
+	return name
+}
+
+type pathSegment struct {
+	name  string
+	key   string
+	keyed bool
+}
+
+// parseChangePath splits a path, e.g. `creators["37"].role`, into segments.
+func parseChangePath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for len(path) > 0 {
+		end := strings.IndexAny(path, `.[`)
+		if end < 0 {
+			end = len(path)
+		}
+		seg := pathSegment{name: path[:end]}
+		if len(seg.name) == 0 {
+			return nil, fmt.Errorf("invalid path")
+		}
+		path = path[end:]
+
+		if strings.HasPrefix(path, "[") {
+			quoted, err := strconv.QuotedPrefix(path[1:])
+			if err != nil || !strings.HasPrefix(path[1+len(quoted):], "]") {
+				return nil, fmt.Errorf("invalid path key")
+			}
+			seg.key, _ = strconv.Unquote(quoted)
+			seg.keyed = true
+			path = path[len(quoted)+2:]
+		}
+		segments = append(segments, seg)
+
+		if strings.HasPrefix(path, ".") {
+			path = path[1:]
+			if len(path) == 0 {
+				return nil, fmt.Errorf("invalid path")
+			}
+		} else if len(path) > 0 {
+			return nil, fmt.Errorf("invalid path")
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return segments, nil
+}
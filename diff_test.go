@@ -0,0 +1,172 @@
+package pgrdf_test
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+)
+
+func modifiedSampleEbook(t *testing.T) *pgrdf.Ebook {
+	e := getEbookFromSampleRdf(t)
+	e.Titles = []string{"Great Expectations", "A Revised Subtitle"}
+	e.Downloads = 20000
+	e.AddSubject("Pirates -- Fiction", "http://purl.org/dc/terms/LCSH")
+	e.Bookshelves = nil
+	e.Files[0].Extent = 123
+	var creators []pgrdf.Creator
+	var moved pgrdf.Creator
+	for _, c := range e.Creators {
+		if c.ID == 9473 {
+			c.Role = pgrdf.RoleArt
+		}
+		if c.ID == 8397 && c.Role == pgrdf.RoleTrl {
+			moved = c
+			moved.Role = pgrdf.RoleAui
+			continue
+		}
+		creators = append(creators, c)
+	}
+	e.Creators = append(creators, moved)
+	return e
+}
+
+func TestDiff(t *testing.T) {
+	a := getEbookFromSampleRdf(t)
+	b := modifiedSampleEbook(t)
+
+	var paths []string
+	for _, c := range pgrdf.Diff(a, b) {
+		paths = append(paths, string(c.Op)+" "+c.Path)
+	}
+	sort.Strings(paths)
+
+	expected := []string{
+		`add creators["8397:aui"]`,
+		`add subjects["Pirates -- Fiction"]`,
+		`add titles["A Revised Subtitle"]`,
+		`remove bookshelves["Best Books Ever Listings"]`,
+		`remove creators["8397:trl"]`,
+		`remove titles["And a subtitle"]`,
+		`replace creators["9473"].role`,
+		`replace downloads`,
+		`replace files["https://www.example.org/files/999991234/999991234-h.zip"].extent`,
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("unexpected changes, got:\n%v", paths)
+	}
+
+	if changes := pgrdf.Diff(a, getEbookFromSampleRdf(t)); len(changes) != 0 {
+		t.Errorf("expected no changes for the same ebook, got %v", changes)
+	}
+}
+
+func TestDiff_ReorderedList(t *testing.T) {
+	a := getEbookFromSampleRdf(t)
+	b := getEbookFromSampleRdf(t)
+	b.Subjects[0], b.Subjects[1] = b.Subjects[1], b.Subjects[0]
+
+	if changes := pgrdf.Diff(a, b); len(changes) != 0 {
+		t.Errorf("expected no changes for a reordered list, got %v", changes)
+	}
+}
+
+func TestDiff_OrderedList(t *testing.T) {
+	a := getEbookFromSampleRdf(t)
+	b := getEbookFromSampleRdf(t)
+	b.Titles[0], b.Titles[1] = b.Titles[1], b.Titles[0]
+	b.Creators[0], b.Creators[1] = b.Creators[1], b.Creators[0]
+
+	changes := pgrdf.Diff(a, b)
+	if len(changes) != 2 || changes[0].Path != "titles" || changes[1].Path != "creators" {
+		t.Fatalf("expected the titles and creators to be replaced, got %v", changes)
+	}
+	if changes[0].Op != pgrdf.ChangeReplace || !reflect.DeepEqual(changes[0].Value, b.Titles) {
+		t.Errorf("unexpected titles change, got %+v", changes[0])
+	}
+}
+
+func TestApply_OrderedList(t *testing.T) {
+	tests := map[string][]string{
+		"main title": {"Old Main", "Sub"},
+		"swapped":    {"Sub", "Old Main"},
+		"appended":   {"Old Main", "Sub", "Another"},
+		"removed":    {"Sub"},
+	}
+	for name, titles := range tests {
+		a := &pgrdf.Ebook{Titles: []string{"Old Main", "Sub"}}
+		b := &pgrdf.Ebook{Titles: titles}
+		if name == "main title" {
+			b.Titles = []string{"New Main", "Sub"}
+		}
+
+		if err := pgrdf.Apply(a, pgrdf.Diff(a, b)); err != nil {
+			t.Fatalf("%s: unexpected error applying changes: %s", name, err)
+		}
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("%s: unexpected titles after apply, got %q", name, a.Titles)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	a := getEbookFromSampleRdf(t)
+	b := modifiedSampleEbook(t)
+
+	if err := pgrdf.Apply(a, pgrdf.Diff(a, b)); err != nil {
+		t.Fatalf("unexpected error applying changes: %s", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected the ebooks to be equal after apply, got %+v", a)
+	}
+	if a.Creators[len(a.Creators)-1].Role != pgrdf.RoleAui {
+		t.Errorf("expected the added creator to be appended, got %+v", a.Creators[len(a.Creators)-1])
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	tests := []pgrdf.Change{
+		{Op: pgrdf.ChangeReplace, Path: "unknown"},
+		{Op: pgrdf.ChangeAdd, Path: "downloads", Value: 1},
+		{Op: pgrdf.ChangeRemove, Path: `titles["Missing"]`},
+		{Op: pgrdf.ChangeAdd, Path: `titles["Great Expectations"]`, Value: "Great Expectations"},
+		{Op: pgrdf.ChangeReplace, Path: `creators["404"].role`, Value: "edt"},
+		{Op: pgrdf.ChangeReplace, Path: `titles["Great Expectations`},
+		{Op: pgrdf.ChangeReplace, Path: "downloads", Value: "many"},
+	}
+	for _, change := range tests {
+		if err := pgrdf.Apply(getEbookFromSampleRdf(t), []pgrdf.Change{change}); err == nil {
+			t.Errorf("expected an error for %s %s", change.Op, change.Path)
+		}
+	}
+}
+
+func TestChangeSet_JSON_RoundTrip(t *testing.T) {
+	a := getEbookFromSampleRdf(t)
+	b := modifiedSampleEbook(t)
+
+	buf := bytes.Buffer{}
+	cs := &pgrdf.ChangeSet{ID: a.ID, Changes: pgrdf.Diff(a, b)}
+	if err := cs.WriteJSON(&buf); err != nil {
+		t.Fatalf("unexpected error writing JSON: %s", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"path": "creators[\"9473\"].role"`)) {
+		t.Errorf("expected the creator role change in JSON, got:\n%s", buf.String())
+	}
+
+	read, err := pgrdf.ReadChangeSet(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading JSON: %s", err)
+	}
+	if read.ID != 999991234 || len(read.Changes) != len(cs.Changes) {
+		t.Fatalf("unexpected change set, got ID %d with %d changes", read.ID, len(read.Changes))
+	}
+	if err := pgrdf.Apply(a, read.Changes); err != nil {
+		t.Fatalf("unexpected error applying changes: %s", err)
+	}
+	if changes := pgrdf.Diff(a, b); len(changes) != 0 {
+		t.Errorf("expected no changes after apply, got %v", changes)
+	}
+}
@@ -2,6 +2,7 @@ package pgrdf
 
 import (
 	"encoding/xml"
+	"fmt"
 	"io"
 	"regexp"
 )
@@ -77,7 +78,7 @@ type Ebook struct {
 
 	// Distributed Proofreaders clearance code, e.g. "20050213050736stahl".
 	// `<pgterms:marc905>`
-	CopyrightClearanceCode string `json:"pg_dp_clearance"`
+	CopyrightClearanceCode string `json:"copyright_clearance_code"`
 
 	// Type of this work, one of:
 	//   Collection, Dataset, Image, MovingImage, Sound, StillImage, Text
@@ -86,7 +87,7 @@ type Ebook struct {
 
 	// Additional notes about this eText.
 	// `<dcterms:description>`
-	Notes []string `json:"note"`
+	Notes []string `json:"notes"`
 
 	// A description of the physical attributes of the source of this work, e.g. "5 pages : illustrations, map, portraits".
 	// `<pgterms:marc300>`
@@ -94,7 +95,7 @@ type Ebook struct {
 
 	// URLs to information about the source of this work, e.g. image scans on Internet Archive website.
 	// `<pgterms:marc904>`
-	SourceLinks []string `json:"source_link"`
+	SourceLinks []string `json:"source_links"`
 
 	// Library of Congress Control Number
 	// `<pgterms:marc010>`
@@ -105,9 +106,9 @@ type Ebook struct {
 	ISBN string `json:"isbn"`
 
 	// Book covers, or images acting as a book cover, i.e. this could be a title page.
-	// A URL or file path in the HTML ebook directory.
+	// Each includes the filename in the HTML ebook directory and the original URL.
 	// `<pgterms:marc901>`
-	BookCovers []string `json:"book_covers"`
+	BookCovers []BookCover `json:"book_covers"`
 
 	// URL of an image file representing the title page of a book.
 	// `<pgterms:marc902>`
@@ -184,6 +185,11 @@ func (e *Ebook) WriteRDF(w io.Writer) error {
 	return nil
 }
 
+// URL returns the gutenberg.org web page for this eText.
+func (e *Ebook) URL() string {
+	return fmt.Sprintf("https://www.gutenberg.org/ebooks/%d", e.ID)
+}
+
 func (e *Ebook) AddSubject(heading, schema string) {
 	sub := Subject{
 		Heading: heading,
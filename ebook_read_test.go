@@ -1,8 +1,11 @@
 package pgrdf_test
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/mrcook/pgrdf"
@@ -114,8 +117,13 @@ func TestReadRDF(t *testing.T) {
 	}
 	if len(ebook.BookCovers) != 1 {
 		t.Errorf("expected 1 book cover, got %d", len(ebook.BookCovers))
-	} else if ebook.BookCovers[0] != "images/cover.jpg" {
-		t.Errorf("unexpected book cover filename, got '%s'", ebook.BookCovers[0])
+	} else {
+		if ebook.BookCovers[0].Filename != "images/cover.jpg" {
+			t.Errorf("unexpected book cover filename, got '%s'", ebook.BookCovers[0].Filename)
+		}
+		if ebook.BookCovers[0].Source != "file:///files/999991234/999991234-h/images/cover.jpg" {
+			t.Errorf("unexpected book cover source, got '%s'", ebook.BookCovers[0].Source)
+		}
 	}
 	if ebook.TitlePageImage != "https://example.org/ebook1/title.jpg" {
 		t.Errorf("unexpected ebook title page image, got '%s'", ebook.TitlePageImage)
@@ -229,7 +237,8 @@ func TestEbookReadCreators(t *testing.T) {
 		{id: 21, role: pgrdf.RolePrt, name: ""},
 		{id: 22, role: pgrdf.RoleRes, name: ""},
 		{id: 23, role: pgrdf.RoleTrc, name: ""},
-		{id: 8397, role: pgrdf.RoleTrl, name: ""},
+		// agent details are shared across all roles referencing the same agent
+		{id: 8397, role: pgrdf.RoleTrl, name: "Snell, F. J. (Frederick John)"},
 		{id: 1736, role: pgrdf.RoleTrl, name: "Wyllie, David"},
 	}
 
@@ -315,6 +324,84 @@ func TestEbookReadBookshelves(t *testing.T) {
 	}
 }
 
+func TestReadRDF_NonCanonicalLayout(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="utf-8"?>
+<rdf:RDF xml:base="http://www.gutenberg.org/"
+  xmlns:dcam="http://purl.org/dc/dcam/"
+  xmlns:dcterms="http://purl.org/dc/terms/"
+  xmlns:pgterms="http://www.gutenberg.org/2009/pgterms/"
+  xmlns:marcrel="http://id.loc.gov/vocabulary/relators/"
+  xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="ebooks/1400">
+    <rdf:type rdf:resource="http://www.gutenberg.org/2009/pgterms/ebook"/>
+    <dcterms:title>Great Expectations</dcterms:title>
+    <dcterms:creator rdf:resource="2009/agents/37"/>
+    <marcrel:ill rdf:nodeID="illustrator"/>
+    <dcterms:subject rdf:parseType="Resource">
+      <rdf:value>Orphans -- Fiction</rdf:value>
+      <dcam:memberOf rdf:resource="http://purl.org/dc/terms/LCSH"/>
+    </dcterms:subject>
+    <dcterms:language rdf:value="en"/>
+    <pgterms:bookshelf rdf:nodeID="shelf"/>
+  </rdf:Description>
+  <pgterms:agent rdf:about="2009/agents/37" pgterms:name="Dickens, Charles"/>
+  <rdf:Description rdf:nodeID="illustrator" pgterms:name="Unknown Artist"/>
+  <rdf:Description rdf:nodeID="shelf">
+    <rdf:value>Best Books Ever Listings</rdf:value>
+    <dcam:memberOf rdf:resource="2009/pgterms/Bookshelf"/>
+  </rdf:Description>
+</rdf:RDF>`
+
+	ebook, err := pgrdf.ReadRDF(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("error reading RDF: %s", err)
+	}
+
+	if ebook.ID != 1400 {
+		t.Errorf("unexpected ebook ID, got %d", ebook.ID)
+	}
+	if len(ebook.Titles) != 1 || ebook.Titles[0] != "Great Expectations" {
+		t.Errorf("unexpected titles, got %v", ebook.Titles)
+	}
+	if len(ebook.Creators) != 2 {
+		t.Fatalf("expected 2 creators, got %d", len(ebook.Creators))
+	}
+	if c := ebook.Creators[0]; c.ID != 37 || c.Name != "Dickens, Charles" || c.Role != pgrdf.RoleAut {
+		t.Errorf("unexpected author, got %+v", c)
+	}
+	if c := ebook.Creators[1]; c.ID != 0 || c.Name != "Unknown Artist" || c.Role != pgrdf.RoleIll {
+		t.Errorf("unexpected illustrator, got %+v", c)
+	}
+	if len(ebook.Subjects) != 1 || ebook.Subjects[0].Heading != "Orphans -- Fiction" {
+		t.Errorf("unexpected subjects, got %v", ebook.Subjects)
+	}
+	if len(ebook.Languages) != 1 || ebook.Languages[0] != "en" {
+		t.Errorf("unexpected languages, got %v", ebook.Languages)
+	}
+	if len(ebook.Bookshelves) != 1 || ebook.Bookshelves[0].Resource != "2009/pgterms/Bookshelf" {
+		t.Errorf("unexpected bookshelves, got %v", ebook.Bookshelves)
+	}
+}
+
+func TestReadRDF_WriteRDF_RoundTrip(t *testing.T) {
+	original := generateEbook()
+	original.Creators[0].Role = pgrdf.RoleAut // the default role when reading
+
+	w := bytes.NewBuffer([]byte{})
+	if err := original.WriteRDF(w); err != nil {
+		t.Fatalf("error writing RDF: %s", err)
+	}
+
+	ebook, err := pgrdf.ReadRDF(w)
+	if err != nil {
+		t.Fatalf("error reading RDF: %s", err)
+	}
+
+	if !reflect.DeepEqual(ebook, original) {
+		t.Errorf("expected round trip to be lossless\nwant: %+v\ngot:  %+v", original, ebook)
+	}
+}
+
 func getEbookFromSampleRdf(t *testing.T) *pgrdf.Ebook {
 	t.Helper()
 
@@ -59,3 +59,19 @@ func TestEbook_AddAuthorLink(t *testing.T) {
 		t.Errorf("unexpected url: '%s'", link.URL)
 	}
 }
+
+func TestFile_ModifiedTime(t *testing.T) {
+	f := pgrdf.File{Modified: "2021-02-01T05:39:04"}
+	modified, err := f.ModifiedTime()
+	if err != nil {
+		t.Fatalf("unexpected error parsing modified date: %s", err)
+	}
+	if got := modified.Format("2006-01-02 15:04:05"); got != "2021-02-01 05:39:04" {
+		t.Errorf("unexpected modified time, got '%s'", got)
+	}
+
+	f.Modified = "2021-02-01"
+	if _, err := f.ModifiedTime(); err == nil {
+		t.Error("expected an error for an invalid modified date")
+	}
+}
@@ -3,6 +3,7 @@ package pgrdf_test
 import (
 	"bytes"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/mrcook/pgrdf"
@@ -43,6 +44,20 @@ func TestEbook_WriteRDF(t *testing.T) {
 	t.Errorf("unexpected marshaled output at position %d\n%s\n", index, data[0:index])
 }
 
+func TestEbook_WriteRDF_BookCoverSource(t *testing.T) {
+	ebook := getEbookFromSampleRdf(t)
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteRDF(w); err != nil {
+		t.Fatalf("error marshaling ebook: %s", err)
+	}
+
+	expected := "<pgterms:marc901>file:///files/999991234/999991234-h/images/cover.jpg</pgterms:marc901>"
+	if !strings.Contains(w.String(), expected) {
+		t.Errorf("expected original marc901 value to be written, got:\n%s", w.String())
+	}
+}
+
 func generateEbook() *pgrdf.Ebook {
 	return &pgrdf.Ebook{
 		ID:                      11,
@@ -68,7 +83,7 @@ func generateEbook() *pgrdf.Ebook {
 		SourceLinks:             []string{"https://example.com/ebooks/11/something"},
 		LCCN:                    "77177892",
 		ISBN:                    "978-0-919366-14-5",
-		BookCovers:              []string{"https://www.gutenberg.org/cache/epub/11/pg11.cover.medium.jpg"},
+		BookCovers:              []pgrdf.BookCover{pgrdf.NewBookCover("https://www.gutenberg.org/cache/epub/11/pg11.cover.medium.jpg")},
 		TitlePageImage:          "https://example.org/ebook11/title.jpg",
 		BackCover:               "https://example.org/ebook11/back.jpg",
 		Creators: []pgrdf.Creator{{
@@ -0,0 +1,84 @@
+package pgrdf
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// epubContainer is the `META-INF/container.xml` document of an EPUB.
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// ReadEPUB reads the OPF package metadata from an EPUB (zip) file and
+// unmarshals it to an Ebook. The OPF document is located using the
+// `META-INF/container.xml` of the EPUB.
+func ReadEPUB(r io.ReaderAt, size int64) (*Ebook, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	container, err := openZipFile(zr, "META-INF/container.xml")
+	if err != nil {
+		return nil, err
+	}
+	defer container.Close()
+
+	var c epubContainer
+	if err := xml.NewDecoder(container).Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid EPUB container: %w", err)
+	}
+
+	var opfPath string
+	for _, rootfile := range c.Rootfiles {
+		if rootfile.MediaType == "application/oebps-package+xml" {
+			opfPath = rootfile.FullPath
+			break
+		}
+	}
+	if len(opfPath) == 0 {
+		return nil, errors.New("no OPF rootfile found in EPUB container")
+	}
+
+	opf, err := openZipFile(zr, opfPath)
+	if err != nil {
+		return nil, err
+	}
+	defer opf.Close()
+
+	return ReadOPF(opf)
+}
+
+// ReadEPUBFile opens the EPUB file with the given name and reads its OPF
+// package metadata, as with ReadEPUB.
+func ReadEPUBFile(name string) (*Ebook, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return ReadEPUB(file, info.Size())
+}
+
+func openZipFile(zr *zip.Reader, name string) (io.ReadCloser, error) {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("%s not found in EPUB", name)
+}
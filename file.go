@@ -1,6 +1,9 @@
 package pgrdf
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // File is a resource for the ebook such as .txt, .tei, .zip, etc.
 type File struct {
@@ -21,6 +24,15 @@ type File struct {
 	Encodings []string `json:"encoding"`
 }
 
+// fileModifiedLayout is the time layout of the File Modified date.
+const fileModifiedLayout = "2006-01-02T15:04:05"
+
+// ModifiedTime returns the Modified date of the file as a time, or an error
+// when it is missing or invalid.
+func (f *File) ModifiedTime() (time.Time, error) {
+	return time.Parse(fileModifiedLayout, f.Modified)
+}
+
 func (f *File) AddEncoding(encoding string) {
 	encoding = strings.TrimSpace(encoding)
 	if len(encoding) == 0 {
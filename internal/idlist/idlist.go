@@ -0,0 +1,44 @@
+// Package idlist contains helpers for the sorted lists of eText IDs used by
+// the catalog and store indexes.
+package idlist
+
+import "sort"
+
+// Insert inserts the ID into the sorted list, if not already present.
+func Insert(ids []int, id int) []int {
+	i := sort.SearchInts(ids, id)
+	if i < len(ids) && ids[i] == id {
+		return ids
+	}
+	ids = append(ids, 0)
+	copy(ids[i+1:], ids[i:])
+	ids[i] = id
+	return ids
+}
+
+// Remove removes the ID from the sorted list, if present.
+func Remove(ids []int, id int) []int {
+	i := sort.SearchInts(ids, id)
+	if i == len(ids) || ids[i] != id {
+		return ids
+	}
+	return append(ids[:i], ids[i+1:]...)
+}
+
+// Intersect returns the IDs in both sorted lists.
+func Intersect(a, b []int) []int {
+	var ids []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			ids = append(ids, a[i])
+			i++
+			j++
+		}
+	}
+	return ids
+}
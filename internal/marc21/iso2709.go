@@ -0,0 +1,194 @@
+package marc21
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ISO 2709 structural characters.
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+)
+
+// WriteISO2709 writes the records in the ISO 2709 exchange format, i.e. a
+// binary `.mrc` file. The record length and base address in the leader are
+// calculated automatically.
+func WriteISO2709(w io.Writer, records ...*Record) error {
+	for _, r := range records {
+		data, err := encodeISO2709(r)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxFieldLength is the largest field, including its terminator, that fits
+// in the 4 digit length of a directory entry.
+const maxFieldLength = 9999
+
+// truncateField shortens the field data to fit in a directory entry, without
+// splitting a UTF-8 character or leaving an empty trailing subfield. Long
+// notes, such as a 505 table of contents, would otherwise make the whole
+// record invalid.
+func truncateField(data []byte) []byte {
+	if len(data) < maxFieldLength {
+		return data
+	}
+	n := maxFieldLength - 1
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	data = data[:n]
+	if i := bytes.LastIndexByte(data, subfieldDelimiter); i >= 0 && i >= len(data)-2 {
+		data = data[:i]
+	}
+	return data
+}
+
+func encodeISO2709(r *Record) ([]byte, error) {
+	if len(r.Leader) != 24 {
+		return nil, fmt.Errorf("invalid leader length %d, must be 24", len(r.Leader))
+	}
+
+	var directory, fields bytes.Buffer
+	addField := func(tag string, data []byte) error {
+		if len(tag) != 3 {
+			return fmt.Errorf("invalid field tag '%s'", tag)
+		}
+		data = append(truncateField(data), fieldTerminator)
+		fmt.Fprintf(&directory, "%s%04d%05d", tag, len(data), fields.Len())
+		fields.Write(data)
+		return nil
+	}
+
+	for _, f := range r.ControlFields {
+		if err := addField(f.Tag, []byte(f.Value)); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range r.DataFields {
+		data := []byte{f.Ind1, f.Ind2}
+		for _, s := range f.Subfields {
+			data = append(data, subfieldDelimiter, s.Code)
+			data = append(data, s.Value...)
+		}
+		if err := addField(f.Tag, data); err != nil {
+			return nil, err
+		}
+	}
+	directory.WriteByte(fieldTerminator)
+
+	baseAddress := 24 + directory.Len()
+	length := baseAddress + fields.Len() + 1
+	if length > 99999 {
+		return nil, fmt.Errorf("record exceeds the maximum length")
+	}
+
+	leader := fmt.Sprintf("%05d%s%05d%s", length, r.Leader[5:12], baseAddress, r.Leader[17:])
+
+	var out bytes.Buffer
+	out.WriteString(leader)
+	out.Write(directory.Bytes())
+	out.Write(fields.Bytes())
+	out.WriteByte(recordTerminator)
+	return out.Bytes(), nil
+}
+
+// ReadISO2709 reads all records from an ISO 2709 exchange format stream.
+func ReadISO2709(r io.Reader) ([]*Record, error) {
+	var records []*Record
+
+	br := bufio.NewReader(r)
+	for {
+		// skip any whitespace between records, e.g. a trailing newline.
+		b, err := br.Peek(1)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b[0] == '\n' || b[0] == '\r' || b[0] == ' ' {
+			_, _ = br.ReadByte()
+			continue
+		}
+
+		prefix, err := br.Peek(5)
+		if err != nil {
+			return nil, fmt.Errorf("reading record length: %w", err)
+		}
+		length, err := strconv.Atoi(string(prefix))
+		if err != nil || length < 25 {
+			return nil, fmt.Errorf("invalid record length '%s'", prefix)
+		}
+
+		data := make([]byte, length)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return nil, fmt.Errorf("reading record: %w", err)
+		}
+
+		record, err := decodeISO2709(data)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+func decodeISO2709(data []byte) (*Record, error) {
+	if data[len(data)-1] != recordTerminator {
+		return nil, fmt.Errorf("missing record terminator")
+	}
+
+	leader := string(data[:24])
+	baseAddress, err := strconv.Atoi(leader[12:17])
+	if err != nil || baseAddress < 25 || baseAddress > len(data) {
+		return nil, fmt.Errorf("invalid base address '%s'", leader[12:17])
+	}
+
+	r := &Record{Leader: leader}
+
+	directory := data[24 : baseAddress-1]
+	for i := 0; i+12 <= len(directory); i += 12 {
+		entry := string(directory[i : i+12])
+		tag := entry[0:3]
+		length, err1 := strconv.Atoi(entry[3:7])
+		start, err2 := strconv.Atoi(entry[7:12])
+		if err1 != nil || err2 != nil || baseAddress+start+length > len(data) {
+			return nil, fmt.Errorf("invalid directory entry '%s'", entry)
+		}
+
+		field := data[baseAddress+start : baseAddress+start+length]
+		field = bytes.TrimSuffix(field, []byte{fieldTerminator})
+
+		if tag < "010" {
+			r.ControlFields = append(r.ControlFields, ControlField{Tag: tag, Value: string(field)})
+			continue
+		}
+
+		df := DataField{Tag: tag, Ind1: ' ', Ind2: ' '}
+		if len(field) >= 2 {
+			df.Ind1, df.Ind2 = field[0], field[1]
+			field = field[2:]
+		}
+		for _, sub := range bytes.Split(field, []byte{subfieldDelimiter}) {
+			if len(sub) == 0 {
+				continue
+			}
+			df.Subfields = append(df.Subfields, Subfield{Code: sub[0], Value: string(sub[1:])})
+		}
+		r.DataFields = append(r.DataFields, df)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,166 @@
+package marc21_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mrcook/pgrdf/internal/marc21"
+)
+
+func sampleRecord() *marc21.Record {
+	r := &marc21.Record{Leader: "00000nam a2200000 i 4500"}
+	r.AddControlField("001", "11")
+	r.AddDataField("100", '1', ' ', marc21.NewSubfield('a', "Carroll, Lewis"), marc21.NewSubfield('4', "aut"))
+	r.AddDataField("245", '1', '0', marc21.NewSubfield('a', "Alice’s Adventures in Wonderland"))
+	r.AddDataField("500", ' ', ' ', marc21.NewSubfield('a', "")) // dropped
+	return r
+}
+
+func TestRecord_AddDataField_DropsEmpty(t *testing.T) {
+	r := sampleRecord()
+	if len(r.DataFields) != 2 {
+		t.Errorf("expected 2 data fields, got %d", len(r.DataFields))
+	}
+}
+
+func TestWriteXML(t *testing.T) {
+	w := bytes.NewBuffer([]byte{})
+	if err := marc21.WriteXML(w, sampleRecord()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{
+		`<collection xmlns="http://www.loc.gov/MARC21/slim">`,
+		`<leader>00000nam a2200000 i 4500</leader>`,
+		`<controlfield tag="001">11</controlfield>`,
+		`<datafield tag="100" ind1="1" ind2=" ">`,
+		`<subfield code="a">Carroll, Lewis</subfield>`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(w.String(), s) {
+			t.Errorf("expected MARCXML to contain: %s", s)
+		}
+	}
+}
+
+func TestWriteISO2709(t *testing.T) {
+	w := bytes.NewBuffer([]byte{})
+	if err := marc21.WriteISO2709(w, sampleRecord()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	data := w.Bytes()
+
+	length, _ := strconv.Atoi(string(data[0:5]))
+	if length != len(data) {
+		t.Errorf("expected record length %d, got %d", len(data), length)
+	}
+	if data[len(data)-1] != 0x1D {
+		t.Errorf("expected a record terminator")
+	}
+
+	base, _ := strconv.Atoi(string(data[12:17]))
+	directory := string(data[24 : base-1])
+	if len(directory) != 3*12 {
+		t.Fatalf("expected 3 directory entries, got '%s'", directory)
+	}
+
+	// the 245 field, with the multibyte title lengths counted in bytes
+	entry := directory[24:36]
+	if entry[0:3] != "245" {
+		t.Fatalf("unexpected directory tag, got '%s'", entry[0:3])
+	}
+	fieldLength, _ := strconv.Atoi(entry[3:7])
+	start, _ := strconv.Atoi(entry[7:12])
+	field := data[base+start : base+start+fieldLength]
+	if string(field) != "10\x1faAlice’s Adventures in Wonderland\x1e" {
+		t.Errorf("unexpected 245 field data, got '%q'", field)
+	}
+}
+
+func TestWriteISO2709_InvalidLeader(t *testing.T) {
+	r := &marc21.Record{Leader: "short"}
+	if err := marc21.WriteISO2709(bytes.NewBuffer(nil), r); err == nil {
+		t.Error("expected an error for an invalid leader")
+	}
+}
+
+func TestWriteISO2709_LongField(t *testing.T) {
+	r := sampleRecord()
+	r.AddDataField("520", ' ', ' ', marc21.NewSubfield('a', strings.Repeat("é", 6000)))
+
+	w := bytes.NewBuffer([]byte{})
+	if err := marc21.WriteISO2709(w, r); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	records, err := marc21.ReadISO2709(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	summary := records[0].DataFields[2].Subfields[0].Value
+	if len(summary) == 0 || len(summary) > 9995 || !utf8.ValidString(summary) {
+		t.Errorf("expected a truncated valid UTF-8 summary, got %d bytes", len(summary))
+	}
+}
+
+func TestReadXML(t *testing.T) {
+	w := bytes.NewBuffer([]byte{})
+	if err := marc21.WriteXML(w, sampleRecord(), sampleRecord()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	records, err := marc21.ReadXML(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	assertSampleRecord(t, records[0])
+}
+
+func TestReadISO2709(t *testing.T) {
+	w := bytes.NewBuffer([]byte{})
+	if err := marc21.WriteISO2709(w, sampleRecord(), sampleRecord()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	records, err := marc21.ReadISO2709(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	assertSampleRecord(t, records[1])
+}
+
+func TestReadISO2709_Invalid(t *testing.T) {
+	if _, err := marc21.ReadISO2709(strings.NewReader("00010nam\x1d")); err == nil {
+		t.Error("expected an error for a truncated record")
+	}
+}
+
+func assertSampleRecord(t *testing.T, r *marc21.Record) {
+	t.Helper()
+
+	if r.ControlField("001") != "11" {
+		t.Errorf("unexpected control number, got '%s'", r.ControlField("001"))
+	}
+	fields := r.Fields("100")
+	if len(fields) != 1 {
+		t.Fatalf("expected one 100 field, got %d", len(fields))
+	}
+	if fields[0].Ind1 != '1' || fields[0].Ind2 != ' ' {
+		t.Errorf("unexpected indicators, got '%c%c'", fields[0].Ind1, fields[0].Ind2)
+	}
+	if fields[0].Subfield('a') != "Carroll, Lewis" {
+		t.Errorf("unexpected name, got '%s'", fields[0].Subfield('a'))
+	}
+	if title := r.Fields("245")[0].Subfield('a'); title != "Alice’s Adventures in Wonderland" {
+		t.Errorf("unexpected title, got '%s'", title)
+	}
+}
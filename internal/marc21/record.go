@@ -0,0 +1,102 @@
+// Package marc21 contains a minimal MARC 21 bibliographic record model, along
+// with readers and writers for the MARCXML and ISO 2709 (binary) formats.
+package marc21
+
+// Record is a single MARC 21 record.
+type Record struct {
+	// Leader is the 24 character record leader.
+	Leader string
+
+	ControlFields []ControlField
+	DataFields    []DataField
+}
+
+// ControlField is a `00X` field, which has no indicators or subfields.
+type ControlField struct {
+	Tag   string
+	Value string
+}
+
+// DataField is a variable data field with indicators and subfields.
+type DataField struct {
+	Tag       string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+// Subfield of a data field, e.g. `$a`.
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// AddControlField appends a control field, unless the value is empty.
+func (r *Record) AddControlField(tag, value string) {
+	if len(value) == 0 {
+		return
+	}
+	r.ControlFields = append(r.ControlFields, ControlField{Tag: tag, Value: value})
+}
+
+// AddDataField appends a data field. Empty subfields are dropped, and the
+// field is not added when no subfields remain.
+func (r *Record) AddDataField(tag string, ind1, ind2 byte, subfields ...Subfield) {
+	var subs []Subfield
+	for _, s := range subfields {
+		if len(s.Value) > 0 {
+			subs = append(subs, s)
+		}
+	}
+	if len(subs) == 0 {
+		return
+	}
+	r.DataFields = append(r.DataFields, DataField{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: subs})
+}
+
+// ControlField returns the value of the first control field with the tag.
+func (r *Record) ControlField(tag string) string {
+	for _, f := range r.ControlFields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Fields returns all data fields with the given tag.
+func (r *Record) Fields(tag string) []DataField {
+	var fields []DataField
+	for _, f := range r.DataFields {
+		if f.Tag == tag {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Subfield returns the value of the first subfield with the code.
+func (f DataField) Subfield(code byte) string {
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+// SubfieldValues returns the values of all subfields with the code.
+func (f DataField) SubfieldValues(code byte) []string {
+	var values []string
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			values = append(values, s.Value)
+		}
+	}
+	return values
+}
+
+// NewSubfield is a helper for creating a subfield.
+func NewSubfield(code byte, value string) Subfield {
+	return Subfield{Code: code, Value: value}
+}
@@ -0,0 +1,128 @@
+package marc21
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Namespace is the MARCXML (MARC 21 slim) namespace.
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+// NOTE: the element names have no namespace so that the namespace is only
+// declared once on the root element when marshaling.
+type xmlCollection struct {
+	XMLName xml.Name    `xml:"collection"`
+	Xmlns   string      `xml:"xmlns,attr,omitempty"`
+	Records []xmlRecord `xml:"record"`
+}
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteXML writes the records as a MARCXML `<collection>` document.
+func WriteXML(w io.Writer, records ...*Record) error {
+	collection := xmlCollection{Xmlns: Namespace}
+	for _, r := range records {
+		collection.Records = append(collection.Records, toXMLRecord(r))
+	}
+
+	data, err := xml.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	_, err = w.Write(data)
+	return err
+}
+
+func toXMLRecord(r *Record) xmlRecord {
+	rec := xmlRecord{Leader: r.Leader}
+	for _, f := range r.ControlFields {
+		rec.ControlFields = append(rec.ControlFields, xmlControlField{Tag: f.Tag, Value: f.Value})
+	}
+	for _, f := range r.DataFields {
+		field := xmlDataField{Tag: f.Tag, Ind1: string(f.Ind1), Ind2: string(f.Ind2)}
+		for _, s := range f.Subfields {
+			field.Subfields = append(field.Subfields, xmlSubfield{Code: string(s.Code), Value: s.Value})
+		}
+		rec.DataFields = append(rec.DataFields, field)
+	}
+	return rec
+}
+
+// ReadXML reads all records from a MARCXML document, which may have either a
+// `<collection>` or a single `<record>` root element.
+func ReadXML(r io.Reader) ([]*Record, error) {
+	var records []*Record
+
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var rec xmlRecord
+		if err := d.DecodeElement(&rec, &start); err != nil {
+			return nil, err
+		}
+		records = append(records, fromXMLRecord(&rec))
+	}
+}
+
+func fromXMLRecord(rec *xmlRecord) *Record {
+	r := &Record{Leader: rec.Leader}
+	for _, f := range rec.ControlFields {
+		r.ControlFields = append(r.ControlFields, ControlField{Tag: f.Tag, Value: f.Value})
+	}
+	for _, f := range rec.DataFields {
+		field := DataField{Tag: f.Tag, Ind1: indicator(f.Ind1), Ind2: indicator(f.Ind2)}
+		for _, s := range f.Subfields {
+			if len(s.Code) == 0 {
+				continue
+			}
+			field.Subfields = append(field.Subfields, Subfield{Code: s.Code[0], Value: s.Value})
+		}
+		r.DataFields = append(r.DataFields, field)
+	}
+	return r
+}
+
+// indicator returns the first character of an indicator attribute, with
+// missing values being blank.
+func indicator(value string) byte {
+	if len(value) == 0 {
+		return ' '
+	}
+	return value[0]
+}
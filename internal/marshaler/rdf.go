@@ -1,16 +1,9 @@
 // Package marshaler contains a set of structs for generating a Project
-// Gutenberg RDF XML document.
-//
-// NOTE: due to limitations in the Go xml package and the namespace complexity
-// of the RDF documents, a separate set of marshaler and unmarshaler structs
-// are required.
+// Gutenberg RDF XML document. Documents are read with the generic RDF/XML
+// parser of the `triples` package instead.
 package marshaler
 
-import (
-	"encoding/xml"
-
-	"github.com/mrcook/pgrdf/internal/unmarshaler"
-)
+import "encoding/xml"
 
 // RDF <rdf:RDF /> is the main document struct
 type RDF struct {
@@ -237,262 +230,3 @@ type Value struct {
 type MemberOf struct {
 	Resource string `xml:"rdf:resource,attr,omitempty"`
 }
-
-// FromUnmarshaler is a helper function for mapping an unmarshaler.RDF to
-// this marshaler.RDF object so that we have both marshal and unmarshal
-// functionality for an RDF XML document.
-// This is required due to the Go xml package limitations, as described at the
-// top of this file.
-func FromUnmarshaler(in *unmarshaler.RDF) *RDF {
-	out := &RDF{
-		NsBase:    in.NsBase,
-		NsDcTerms: in.NsDcTerms,
-		NsPgTerms: in.NsPgTerms,
-		NsRdf:     in.NsRdf,
-		NsRdfs:    in.NsRdfs,
-		NsCC:      in.NsCC,
-		NsMarcRel: in.NsMarcRel,
-		NsDcDcam:  in.NsDcDcam,
-		Ebook: Ebook{
-			About:                   in.Ebook.About,
-			Titles:                  in.Ebook.Titles,
-			Alternatives:            in.Ebook.Alternatives,
-			TableOfContents:         in.Ebook.TableOfContents,
-			Publisher:               in.Ebook.Publisher,
-			PublishedYear:           in.Ebook.PublishedYear,
-			Summary:                 in.Ebook.Summary,
-			Series:                  in.Ebook.Series,
-			LanguageDialect:         in.Ebook.LanguageDialect,
-			LanguageNotes:           in.Ebook.LanguageNotes,
-			PublicationNote:         in.Ebook.PublicationNote,
-			EditionNote:             in.Ebook.EditionNote,
-			ProductionNotes:         in.Ebook.ProductionNotes,
-			License:                 License{Resource: in.Ebook.License.Resource},
-			Rights:                  in.Ebook.Rights,
-			DpClearanceCode:         in.Ebook.DpClearanceCode,
-			Type:                    Type{Description: description(&in.Ebook.Type.Description)},
-			Descriptions:            in.Ebook.Descriptions,
-			PhysicalDescriptionNote: in.Ebook.PhysicalDescriptionNote,
-			SourceLinks:             in.Ebook.SourceLinks,
-			LCCN:                    in.Ebook.LCCN,
-			ISBN:                    in.Ebook.ISBN,
-			BookCoverImages:         in.Ebook.BookCoverImages,
-			TitlePageImage:          in.Ebook.TitlePageImage,
-			BackCoverImage:          in.Ebook.BackCoverImage,
-		},
-		Work: Work{
-			About:   in.Work.About,
-			Comment: in.Work.Comment,
-			License: CCLicense{Resource: in.Work.License.Resource},
-		},
-	}
-
-	if in.Ebook.Issued != nil {
-		out.Ebook.Issued = &Issued{
-			DataType: in.Ebook.Issued.DataType,
-			Value:    in.Ebook.Issued.Value,
-		}
-	}
-
-	for _, lang := range in.Ebook.Languages {
-		out.Ebook.Languages = append(out.Ebook.Languages, Language{Description: description(&lang.Description)})
-	}
-
-	for _, c := range in.Ebook.Creators {
-		creator := Creator{
-			Resource: c.Resource,
-			Agent:    createAgent(&c.Agent),
-		}
-		out.Ebook.Creators = append(out.Ebook.Creators, creator)
-	}
-
-	for _, c := range in.Ebook.RelAdapters {
-		out.Ebook.RelAdapters = append(out.Ebook.RelAdapters, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelAfterwords {
-		out.Ebook.RelAfterwords = append(out.Ebook.RelAfterwords, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelAnnotators {
-		out.Ebook.RelAnnotators = append(out.Ebook.RelAnnotators, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelArrangers {
-		out.Ebook.RelArrangers = append(out.Ebook.RelArrangers, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelArtists {
-		out.Ebook.RelArtists = append(out.Ebook.RelArtists, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelIntroductions {
-		out.Ebook.RelIntroductions = append(out.Ebook.RelIntroductions, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelCommentators {
-		out.Ebook.RelCommentators = append(out.Ebook.RelCommentators, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelComposers {
-		out.Ebook.RelComposers = append(out.Ebook.RelComposers, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelConductors {
-		out.Ebook.RelConductors = append(out.Ebook.RelConductors, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelCompilers {
-		out.Ebook.RelCompilers = append(out.Ebook.RelCompilers, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelContributors {
-		out.Ebook.RelContributors = append(out.Ebook.RelContributors, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelDubious {
-		out.Ebook.RelDubious = append(out.Ebook.RelDubious, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelEditors {
-		out.Ebook.RelEditors = append(out.Ebook.RelEditors, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelEngravers {
-		out.Ebook.RelEngravers = append(out.Ebook.RelEngravers, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelIllustrators {
-		out.Ebook.RelIllustrators = append(out.Ebook.RelIllustrators, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelLibrettists {
-		out.Ebook.RelLibrettists = append(out.Ebook.RelLibrettists, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelOther {
-		out.Ebook.RelOther = append(out.Ebook.RelOther, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelPublishers {
-		out.Ebook.RelPublishers = append(out.Ebook.RelPublishers, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelPhotographers {
-		out.Ebook.RelPhotographers = append(out.Ebook.RelPhotographers, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelPerformers {
-		out.Ebook.RelPerformers = append(out.Ebook.RelPerformers, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelPrinters {
-		out.Ebook.RelPrinters = append(out.Ebook.RelPrinters, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelResearchers {
-		out.Ebook.RelResearchers = append(out.Ebook.RelResearchers, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelTranscribers {
-		out.Ebook.RelTranscribers = append(out.Ebook.RelTranscribers, generateMarcRelator(&c))
-	}
-	for _, c := range in.Ebook.RelTranslators {
-		out.Ebook.RelTranslators = append(out.Ebook.RelTranslators, generateMarcRelator(&c))
-	}
-
-	for _, s := range in.Ebook.Subjects {
-		subject := Subject{
-			Description: description(&s.Description),
-		}
-		out.Ebook.Subjects = append(out.Ebook.Subjects, subject)
-	}
-
-	for _, s := range in.Ebook.HasFormats {
-		format := HasFormat{
-			File: File{
-				About: s.File.About,
-				Extent: Extent{
-					DataType: s.File.Extent.DataType,
-					Value:    s.File.Extent.Value,
-				},
-				Modified: Modified{
-					DataType: s.File.Modified.DataType,
-					Value:    s.File.Modified.Value,
-				},
-				Formats: nil,
-				IsFormatOf: IsFormatOf{
-					Resource: s.File.IsFormatOf.Resource,
-				},
-			},
-		}
-
-		for _, f := range s.File.Formats {
-			fileFormat := Format{
-				Description: description(&f.Description),
-			}
-			format.File.Formats = append(format.File.Formats, fileFormat)
-		}
-
-		out.Ebook.HasFormats = append(out.Ebook.HasFormats, format)
-	}
-
-	for _, s := range in.Ebook.Bookshelves {
-		shelf := Bookshelf{
-			Description: description(&s.Description),
-		}
-		out.Ebook.Bookshelves = append(out.Ebook.Bookshelves, shelf)
-	}
-
-	if len(in.Ebook.Downloads.DataType) > 0 || in.Ebook.Downloads.Value > 0 {
-		out.Ebook.Downloads = &Downloads{
-			DataType: in.Ebook.Downloads.DataType,
-			Value:    in.Ebook.Downloads.Value,
-		}
-	}
-
-	for _, s := range in.Descriptions {
-		out.Descriptions = append(out.Descriptions, description(&s))
-	}
-
-	return out
-}
-
-// maps the unmarshaler description
-func description(d *unmarshaler.Description) Description {
-	desc := Description{
-		About:       d.About,
-		NodeID:      d.NodeID,
-		Description: d.Description,
-	}
-	if d.Value != nil {
-		desc.Value = &Value{
-			DataType: d.Value.DataType,
-			Data:     d.Value.Data,
-		}
-	}
-	if d.MemberOf != nil {
-		desc.MemberOf = &MemberOf{
-			Resource: d.MemberOf.Resource,
-		}
-	}
-	return desc
-}
-
-func createAgent(in *unmarshaler.Agent) Agent {
-	if in == nil {
-		return Agent{}
-	}
-
-	out := Agent{
-		About:   in.About,
-		Name:    in.Name,
-		Aliases: in.Aliases,
-	}
-	if in.BirthYear != nil {
-		out.BirthYear = &Year{
-			DataType: in.BirthYear.DataType,
-			Value:    in.BirthYear.Value,
-		}
-	}
-	if in.DeathYear != nil {
-		out.DeathYear = &Year{
-			DataType: in.DeathYear.DataType,
-			Value:    in.DeathYear.Value,
-		}
-	}
-	for _, webpage := range in.Webpages {
-		if len(webpage.Resource) > 0 {
-			out.Webpages = append(out.Webpages, Webpage{Resource: webpage.Resource})
-		}
-	}
-	return out
-}
-
-// NOTE: the assumption here is that a Resource will only ever exist on a
-// MarcRelator when there is no Agent info!
-func generateMarcRelator(c *unmarshaler.MarcRelator) MarcRelator {
-	if c.Agent == nil {
-		return MarcRelator{Resource: c.Resource}
-	}
-	agent := createAgent(c.Agent)
-	return MarcRelator{Agent: &agent}
-}
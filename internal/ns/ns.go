@@ -0,0 +1,23 @@
+// Package ns holds the RDF namespaces, shared by the ebook RDF documents and
+// the SKOS taxonomy.
+package ns
+
+// Namespaces used in the Project Gutenberg RDF documents.
+const (
+	DcTerms = "http://purl.org/dc/terms/"
+	PgTerms = "http://www.gutenberg.org/2009/pgterms/"
+	Rdf     = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	Rdfs    = "http://www.w3.org/2000/01/rdf-schema#"
+	CC      = "http://web.resource.org/cc/"
+	DcDcam  = "http://purl.org/dc/dcam/"
+	MarcRel = "http://id.loc.gov/vocabulary/relators/"
+	Xsd     = "http://www.w3.org/2001/XMLSchema#"
+)
+
+// Skos is the namespace of the SKOS taxonomy.
+const Skos = "http://www.w3.org/2004/02/skos/core#"
+
+// PgRdf is the namespace of the terms defined by this library, for values
+// with no suitable term in a common vocabulary, such as the ebook count of a
+// taxonomy concept.
+const PgRdf = "https://github.com/mrcook/pgrdf/ns#"
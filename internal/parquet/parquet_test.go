@@ -0,0 +1,195 @@
+package parquet_test
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/pgrdf/internal/parquet"
+)
+
+var testSchema = []parquet.Field{
+	{Name: "id", Type: parquet.Int32},
+	{Name: "title", Type: parquet.ByteArray, Logical: parquet.String, Optional: true},
+	{Name: "tags", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	{Name: "people", Type: parquet.Group, List: true, Fields: []parquet.Field{
+		{Name: "name", Type: parquet.ByteArray, Logical: parquet.String},
+		{Name: "born", Type: parquet.Int32, Optional: true},
+		{Name: "aliases", Type: parquet.ByteArray, Logical: parquet.String, List: true},
+	}},
+	{Name: "size", Type: parquet.Int64},
+}
+
+func testRows() [][]interface{} {
+	return [][]interface{}{
+		{int32(1), "One", []interface{}{"a", "b"}, []interface{}{
+			[]interface{}{"Ann", int32(1850), []interface{}{"A.", "Annie"}},
+			[]interface{}{"Bob", nil, nil},
+		}, int64(100)},
+		{int32(2), nil, nil, nil, int64(200)},
+		{int32(3), "Three", []interface{}{"c"}, []interface{}{
+			[]interface{}{"Cat", int32(-500), []interface{}{"C."}},
+		}, int64(300)},
+	}
+}
+
+func writeTestFile(t *testing.T, rowGroupSize int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := parquet.NewWriter(&buf, testSchema)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %s", err)
+	}
+	w.RowGroupSize = rowGroupSize
+
+	for _, row := range testRows() {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("unexpected error writing row: %s", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing writer: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriter_FileMetaData(t *testing.T) {
+	data := writeTestFile(t, 2)
+
+	if string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		t.Fatalf("missing PAR1 magic")
+	}
+	meta := readFooter(t, data)
+
+	if meta[3].(int64) != 3 {
+		t.Errorf("expected 3 rows, got %d", meta[3])
+	}
+	var names []string
+	for _, el := range meta[2].([]interface{}) {
+		names = append(names, string(el.(map[int16]interface{})[4].([]byte)))
+	}
+	expected := []string{"schema", "id", "title", "tags", "list", "element", "people", "list", "element", "name", "born", "aliases", "list", "element", "size"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("unexpected schema, got %v", names)
+	}
+	if groups := meta[4].([]interface{}); len(groups) != 2 {
+		t.Errorf("expected 2 row groups, got %d", len(groups))
+	}
+}
+
+func TestWriter_Columns(t *testing.T) {
+	data := writeTestFile(t, 2)
+	columns, rows := readFile(t, data)
+
+	if rows != 3 {
+		t.Errorf("expected 3 rows, got %d", rows)
+	}
+	if len(columns) != 7 {
+		t.Fatalf("expected 7 columns, got %d", len(columns))
+	}
+
+	tests := []struct {
+		path   string
+		maxRep int
+		maxDef int
+		levels []string // rep:def:value
+	}{
+		{"id", 0, 0, []string{"0:0:1", "0:0:2", "0:0:3"}},
+		{"title", 0, 1, []string{"0:1:One", "0:0:", "0:1:Three"}},
+		{"tags.list.element", 1, 1, []string{"0:1:a", "1:1:b", "0:0:", "0:1:c"}},
+		{"people.list.element.name", 1, 1, []string{"0:1:Ann", "1:1:Bob", "0:0:", "0:1:Cat"}},
+		{"people.list.element.born", 1, 2, []string{"0:2:1850", "1:1:", "0:0:", "0:2:-500"}},
+		{"people.list.element.aliases.list.element", 2, 2, []string{"0:2:A.", "2:2:Annie", "1:1:", "0:0:", "0:2:C."}},
+		{"size", 0, 0, []string{"0:0:100", "0:0:200", "0:0:300"}},
+	}
+	for _, test := range tests {
+		c, ok := columns[test.path]
+		if !ok {
+			t.Errorf("missing column %s", test.path)
+			continue
+		}
+		if c.maxRep != test.maxRep || c.maxDef != test.maxDef {
+			t.Errorf("unexpected column %s levels, got %d/%d", test.path, c.maxRep, c.maxDef)
+		}
+		if levels := c.levels(); !reflect.DeepEqual(levels, test.levels) {
+			t.Errorf("unexpected column %s values, got %v", test.path, levels)
+		}
+	}
+}
+
+func TestWriter_InvalidRow(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := parquet.NewWriter(&buf, testSchema)
+
+	if err := w.Write([]interface{}{int32(1), "One"}); err == nil {
+		t.Error("expected an error for a short row")
+	}
+	if err := w.Write([]interface{}{nil, nil, nil, nil, int64(1)}); err == nil {
+		t.Error("expected an error for a null required value")
+	}
+	// the invalid row must not be written to the columns
+	if err := w.Write([]interface{}{int32(1), "One", nil, nil, "100"}); err == nil {
+		t.Error("expected an error for the wrong value type")
+	}
+	if err := w.Write(testRows()[1]); err != nil {
+		t.Fatalf("unexpected error writing row: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing writer: %s", err)
+	}
+
+	columns, _ := readFile(t, buf.Bytes())
+	if levels := columns["title"].levels(); !reflect.DeepEqual(levels, []string{"0:0:"}) {
+		t.Errorf("unexpected column values, got %v", levels)
+	}
+}
+
+// archiveFixture is the sample RDF archive written by archive.WriteParquetCatalog.
+const archiveFixture = "../../archive/testdata/rdf-files-test.parquet"
+
+func TestReader_ArchiveFixture(t *testing.T) {
+	data, err := os.ReadFile(archiveFixture)
+	if err != nil {
+		t.Fatalf("unable to read fixture: %s", err)
+	}
+	columns, rows := readFile(t, data)
+	if rows != 2 {
+		t.Fatalf("expected 2 rows, got %d", rows)
+	}
+
+	tests := []struct {
+		path   string
+		values [][]interface{} // per row, in archive order
+	}{
+		{"id", [][]interface{}{{int32(1400)}, {int32(11)}}},
+		{"titles.list.element", [][]interface{}{{"Great Expectations"}, {"Alice's Adventures in Wonderland"}}},
+		{"languages.list.element", [][]interface{}{{"en"}, {"en"}}},
+		{"creators.list.element.id", [][]interface{}{{int32(37)}, {int32(7)}}},
+		{"creators.list.element.name", [][]interface{}{{"Dickens, Charles"}, {"Carroll, Lewis"}}},
+		{"creators.list.element.born_year", [][]interface{}{{int32(1812)}, {int32(1832)}}},
+		{"creators.list.element.died_year", [][]interface{}{{int32(1870)}, {int32(1898)}}},
+	}
+	for _, test := range tests {
+		c, ok := columns[test.path]
+		if !ok {
+			t.Errorf("missing column %s", test.path)
+			continue
+		}
+		if values := c.rows(); !reflect.DeepEqual(values, test.values) {
+			t.Errorf("unexpected column %s values, got %v", test.path, values)
+		}
+	}
+
+	headings := columns["subjects.list.element.heading"].rows()
+	found := false
+	for _, h := range headings[0] {
+		if h == "Orphans -- Fiction" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected subject 'Orphans -- Fiction', got %v", headings[0])
+	}
+}
@@ -0,0 +1,408 @@
+package parquet_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// The reader below decodes files using only the Parquet format specification,
+// rather than the writer's own types: the levels of each column are derived
+// from the schema in the footer, every column chunk and page header is
+// checked, and both the RLE and bit-packed runs of the level encoding are
+// supported.
+//
+// See https://github.com/apache/parquet-format for the specification.
+
+// column is a decoded leaf column of all the row groups in a file.
+type column struct {
+	path   string // dotted path_in_schema, e.g. "people.list.element.name"
+	typ    int64
+	maxRep int
+	maxDef int
+	values []levelValue
+}
+
+// levelValue is a column entry; the value is nil unless def equals maxDef.
+type levelValue struct {
+	rep   int
+	def   int
+	value interface{}
+}
+
+// levels returns the column entries formatted as "rep:def:value".
+func (c *column) levels() []string {
+	var levels []string
+	for _, v := range c.values {
+		value := ""
+		if v.value != nil {
+			value = fmt.Sprint(v.value)
+		}
+		levels = append(levels, fmt.Sprintf("%d:%d:%s", v.rep, v.def, value))
+	}
+	return levels
+}
+
+// rows returns the non-null values of each row, flattening any nested lists.
+func (c *column) rows() [][]interface{} {
+	var rows [][]interface{}
+	for _, v := range c.values {
+		if v.rep == 0 {
+			rows = append(rows, []interface{}{})
+		}
+		if v.value != nil {
+			rows[len(rows)-1] = append(rows[len(rows)-1], v.value)
+		}
+	}
+	return rows
+}
+
+// readFile decodes every column of the file, returning them by path along
+// with the number of rows.
+func readFile(t *testing.T, data []byte) (map[string]*column, int64) {
+	t.Helper()
+
+	if len(data) < 12 || string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		t.Fatalf("missing PAR1 magic")
+	}
+	meta := readFooter(t, data)
+
+	elements := meta[2].([]interface{})
+	var leaves []*column
+	if n := schemaLeaves(t, elements, 0, nil, 0, 0, &leaves); n != len(elements) {
+		t.Fatalf("schema has %d elements, but only %d are in the tree", len(elements), n)
+	}
+	columns := make(map[string]*column)
+	for _, c := range leaves {
+		columns[c.path] = c
+	}
+
+	var rows int64
+	for g, group := range meta[4].([]interface{}) {
+		rg := group.(map[int16]interface{})
+		chunks := rg[1].([]interface{})
+		if len(chunks) != len(leaves) {
+			t.Fatalf("row group %d has %d columns, expected %d", g, len(chunks), len(leaves))
+		}
+		numRows := rg[3].(int64)
+		for i, chunk := range chunks {
+			c := leaves[i]
+			readColumnChunk(t, data, chunk.(map[int16]interface{})[3].(map[int16]interface{}), c)
+
+			groupRows := int64(0)
+			for _, v := range c.values {
+				if v.rep == 0 {
+					groupRows++
+				}
+			}
+			if groupRows != rows+numRows {
+				t.Fatalf("column %s has %d rows after row group %d, expected %d", c.path, groupRows, g, rows+numRows)
+			}
+		}
+		rows += numRows
+	}
+	if rows != meta[3].(int64) {
+		t.Fatalf("row groups have %d rows, but the file has %d", rows, meta[3])
+	}
+	return columns, rows
+}
+
+// schemaLeaves walks the depth-first schema elements from index i, adding the
+// leaf columns and returning the index of the next element.
+func schemaLeaves(t *testing.T, elements []interface{}, i int, path []string, rep, def int, leaves *[]*column) int {
+	t.Helper()
+
+	el := elements[i].(map[int16]interface{})
+	if i > 0 {
+		path = append(path, string(el[4].([]byte)))
+		switch el[3].(int64) {
+		case 1: // OPTIONAL
+			def++
+		case 2: // REPEATED
+			rep++
+			def++
+		}
+	}
+	children, ok := el[5].(int64)
+	if !ok || children == 0 {
+		if i == 0 {
+			t.Fatalf("schema root has no children")
+		}
+		*leaves = append(*leaves, &column{path: strings.Join(path, "."), typ: el[1].(int64), maxRep: rep, maxDef: def})
+		return i + 1
+	}
+	next := i + 1
+	for k := int64(0); k < children; k++ {
+		if next >= len(elements) {
+			t.Fatalf("schema element %s has missing children", strings.Join(path, "."))
+		}
+		next = schemaLeaves(t, elements, next, append([]string(nil), path...), rep, def, leaves)
+	}
+	return next
+}
+
+// readColumnChunk decodes the data pages of a column chunk, appending the
+// entries to the column.
+func readColumnChunk(t *testing.T, data []byte, md map[int16]interface{}, c *column) {
+	t.Helper()
+
+	if md[1].(int64) != c.typ {
+		t.Fatalf("column %s has type %d, but the schema has %d", c.path, md[1], c.typ)
+	}
+	var path []string
+	for _, p := range md[3].([]interface{}) {
+		path = append(path, string(p.([]byte)))
+	}
+	if strings.Join(path, ".") != c.path {
+		t.Fatalf("column chunk path %v does not match the schema column %s", path, c.path)
+	}
+	codec := md[4].(int64)
+	numValues := md[5].(int64)
+
+	r := bytes.NewReader(data[md[9].(int64):])
+	for read := int64(0); read < numValues; {
+		header := readStruct(t, r)
+		if header[1].(int64) != 0 {
+			t.Fatalf("column %s has unsupported page type %d", c.path, header[1])
+		}
+		page := make([]byte, header[3].(int64))
+		if _, err := io.ReadFull(r, page); err != nil {
+			t.Fatalf("unable to read page: %s", err)
+		}
+		raw := decompress(t, codec, page)
+		if len(raw) != int(header[2].(int64)) {
+			t.Fatalf("column %s page is %d bytes uncompressed, header says %d", c.path, len(raw), header[2])
+		}
+
+		dataPage := header[5].(map[int16]interface{})
+		count := int(dataPage[1].(int64))
+		if dataPage[2].(int64) != 0 { // PLAIN
+			t.Fatalf("column %s has unsupported value encoding %d", c.path, dataPage[2])
+		}
+		if dataPage[3].(int64) != 3 || dataPage[4].(int64) != 3 { // RLE
+			t.Fatalf("column %s has unsupported level encodings %d/%d", c.path, dataPage[3], dataPage[4])
+		}
+
+		pr := bytes.NewReader(raw)
+		repLevels := readLevels(t, pr, count, c.maxRep)
+		defLevels := readLevels(t, pr, count, c.maxDef)
+		for k := 0; k < count; k++ {
+			v := levelValue{rep: repLevels[k], def: defLevels[k]}
+			if v.def == c.maxDef {
+				v.value = readPlain(t, pr, c.typ)
+			}
+			c.values = append(c.values, v)
+		}
+		if pr.Len() != 0 {
+			t.Fatalf("column %s page has %d trailing bytes", c.path, pr.Len())
+		}
+		read += int64(count)
+		if read > numValues {
+			t.Fatalf("column %s pages have more than %d values", c.path, numValues)
+		}
+	}
+}
+
+func decompress(t *testing.T, codec int64, page []byte) []byte {
+	t.Helper()
+	switch codec {
+	case 0: // UNCOMPRESSED
+		return page
+	case 2: // GZIP
+		zr, err := gzip.NewReader(bytes.NewReader(page))
+		if err != nil {
+			t.Fatalf("unable to read gzip page: %s", err)
+		}
+		raw, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("unable to read gzip page: %s", err)
+		}
+		return raw
+	}
+	t.Fatalf("unsupported compression codec %d", codec)
+	return nil
+}
+
+// readLevels decodes the length prefixed RLE/bit-packing hybrid levels of a
+// data page. No levels are written when the maximum level is zero.
+func readLevels(t *testing.T, r *bytes.Reader, count, max int) []int {
+	t.Helper()
+
+	levels := make([]int, 0, count)
+	if max == 0 {
+		return levels[:count]
+	}
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		t.Fatalf("unable to read levels length: %s", err)
+	}
+	if int(size) > r.Len() {
+		t.Fatalf("levels length %d exceeds the page", size)
+	}
+	width := 0
+	for m := max; m > 0; m >>= 1 {
+		width++
+	}
+
+	end := r.Len() - int(size)
+	for r.Len() > end {
+		header, err := binary.ReadUvarint(r)
+		if err != nil {
+			t.Fatalf("unable to read levels run: %s", err)
+		}
+		if header&1 == 1 {
+			// bit-packed groups of 8 values, least significant bit first
+			b := make([]byte, int(header>>1)*width)
+			if _, err := io.ReadFull(r, b); err != nil {
+				t.Fatalf("unable to read bit-packed run: %s", err)
+			}
+			for bit := 0; bit+width <= len(b)*8; bit += width {
+				value := 0
+				for k := 0; k < width; k++ {
+					if b[(bit+k)/8]&(1<<((bit+k)%8)) != 0 {
+						value |= 1 << k
+					}
+				}
+				levels = append(levels, value)
+			}
+			continue
+		}
+		value := 0
+		for k := 0; k < (width+7)/8; k++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				t.Fatalf("unable to read RLE run value: %s", err)
+			}
+			value |= int(b) << (8 * k)
+		}
+		for i := 0; i < int(header>>1); i++ {
+			levels = append(levels, value)
+		}
+	}
+	if r.Len() != end {
+		t.Fatalf("levels overrun their length by %d bytes", end-r.Len())
+	}
+	// the last bit-packed group may be padded
+	if len(levels) < count {
+		t.Fatalf("expected %d levels, got %d", count, len(levels))
+	}
+	levels = levels[:count]
+	for _, l := range levels {
+		if l > max {
+			t.Fatalf("level %d exceeds the maximum %d", l, max)
+		}
+	}
+	return levels
+}
+
+// readPlain decodes a PLAIN encoded value of the physical type.
+func readPlain(t *testing.T, r *bytes.Reader, typ int64) interface{} {
+	t.Helper()
+	switch typ {
+	case 1: // INT32
+		var v int32
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			t.Fatalf("unable to read INT32: %s", err)
+		}
+		return v
+	case 2: // INT64
+		var v int64
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			t.Fatalf("unable to read INT64: %s", err)
+		}
+		return v
+	case 6: // BYTE_ARRAY
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			t.Fatalf("unable to read BYTE_ARRAY length: %s", err)
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			t.Fatalf("unable to read BYTE_ARRAY: %s", err)
+		}
+		return string(b)
+	}
+	t.Fatalf("unsupported physical type %d", typ)
+	return nil
+}
+
+func readFooter(t *testing.T, data []byte) map[int16]interface{} {
+	t.Helper()
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if size > len(data)-12 {
+		t.Fatalf("footer length %d exceeds the file", size)
+	}
+	r := bytes.NewReader(data[len(data)-8-size : len(data)-8])
+	meta := readStruct(t, r)
+	if r.Len() != 0 {
+		t.Fatalf("footer has %d trailing bytes", r.Len())
+	}
+	return meta
+}
+
+// readStruct decodes a Thrift compact protocol struct to its field values.
+func readStruct(t *testing.T, r *bytes.Reader) map[int16]interface{} {
+	t.Helper()
+	fields := make(map[int16]interface{})
+	var id int16
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			t.Fatalf("unexpected end of struct: %s", err)
+		}
+		if b == 0 {
+			return fields
+		}
+		if delta := int16(b >> 4); delta > 0 {
+			id += delta
+		} else {
+			id = int16(readVarint(r))
+		}
+		fields[id] = readValue(t, r, b&0x0F)
+	}
+}
+
+func readValue(t *testing.T, r *bytes.Reader, typ byte) interface{} {
+	t.Helper()
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case 5, 6:
+		return readVarint(r)
+	case 8:
+		n, _ := binary.ReadUvarint(r)
+		if n > uint64(r.Len()) {
+			t.Fatalf("binary length %d exceeds the data", n)
+		}
+		b := make([]byte, n)
+		_, _ = io.ReadFull(r, b)
+		return b
+	case 9:
+		h, _ := r.ReadByte()
+		size := uint64(h >> 4)
+		if size == 15 {
+			size, _ = binary.ReadUvarint(r)
+		}
+		if size > uint64(r.Len()) {
+			t.Fatalf("list size %d exceeds the data", size)
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = readValue(t, r, h&0x0F)
+		}
+		return list
+	case 12:
+		return readStruct(t, r)
+	}
+	t.Fatalf("unsupported thrift type %d", typ)
+	return nil
+}
+
+func readVarint(r *bytes.Reader) int64 {
+	u, _ := binary.ReadUvarint(r)
+	return int64(u>>1) ^ -int64(u&1)
+}
@@ -0,0 +1,211 @@
+package parquet
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Type is the physical type of a leaf field.
+type Type int32
+
+// The physical types supported by the Writer.
+const (
+	Group     Type = -1
+	Int32     Type = 1
+	Int64     Type = 2
+	ByteArray Type = 6
+)
+
+// Logical is the annotation of a leaf field, written as its converted type.
+type Logical int32
+
+// Logical annotations, the values being the Parquet converted types plus one,
+// so that the zero value is no annotation.
+const (
+	None            Logical = 0
+	String          Logical = 1 // UTF8, for a ByteArray
+	Date            Logical = 7 // days since the Unix epoch, for an Int32
+	TimestampMillis Logical = 10
+
+	logicalList Logical = 4
+)
+
+// repetition types
+const (
+	required int32 = 0
+	optional int32 = 1
+	repeated int32 = 2
+)
+
+// Field of a schema. A field is a leaf column of the given Type, or a Group of
+// Fields. List fields are written using the standard three-level LIST
+// structure, with the elements being the field type, and an empty slice
+// written as an empty list.
+//
+// Row values are given as an `int32`, `int64`, or `string` for a leaf field,
+// a `[]interface{}` of the field values for a group, and a `[]interface{}` of
+// the elements for a list. A nil value is written as null for Optional fields.
+type Field struct {
+	Name     string
+	Type     Type
+	Logical  Logical
+	Optional bool
+	List     bool
+	Fields   []Field
+}
+
+// node is a compiled schema field, with the definition and repetition levels
+// for a value of the field.
+type node struct {
+	Field
+	def      int
+	rep      int
+	children []*node
+	column   *column // leaf fields only
+}
+
+// compile the fields to nodes, appending the leaf columns in schema order.
+func compile(fields []Field, path []string, def, rep int, columns *[]*column) ([]*node, error) {
+	var nodes []*node
+	for _, f := range fields {
+		n := &node{Field: f, def: def, rep: rep}
+		p := append(append([]string{}, path...), f.Name)
+
+		switch {
+		case f.List && f.Optional:
+			return nil, fmt.Errorf("list field %s can not be optional", f.Name)
+		case f.List:
+			n.def++
+			n.rep++
+			p = append(p, "list", "element")
+		case f.Optional:
+			n.def++
+		}
+
+		if f.Type == Group {
+			if len(f.Fields) == 0 {
+				return nil, fmt.Errorf("group field %s has no fields", f.Name)
+			}
+			children, err := compile(f.Fields, p, n.def, n.rep, columns)
+			if err != nil {
+				return nil, err
+			}
+			n.children = children
+		} else {
+			n.column = &column{path: p, typ: f.Type, maxDef: n.def, maxRep: n.rep}
+			*columns = append(*columns, n.column)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// shred writes the value of the field to its leaf columns, with rep being the
+// repetition level of the first value, and def the definition level of the
+// parent field.
+func (n *node) shred(v interface{}, rep, def int) error {
+	if n.List {
+		elements, ok := v.([]interface{})
+		if !ok && v != nil {
+			return fmt.Errorf("list field %s: unexpected value type %T", n.Name, v)
+		}
+		if len(elements) == 0 {
+			n.null(rep, def)
+			return nil
+		}
+		for i, el := range elements {
+			if i > 0 {
+				rep = n.rep
+			}
+			if err := n.shredValue(el, rep, n.def); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if v == nil {
+		if !n.Optional {
+			return fmt.Errorf("required field %s has no value", n.Name)
+		}
+		n.null(rep, def)
+		return nil
+	}
+	return n.shredValue(v, rep, n.def)
+}
+
+func (n *node) shredValue(v interface{}, rep, def int) error {
+	if v == nil {
+		return fmt.Errorf("field %s has a null element", n.Name)
+	}
+	if n.column != nil {
+		return n.column.add(v, rep, def)
+	}
+
+	values, ok := v.([]interface{})
+	if !ok || len(values) != len(n.children) {
+		return fmt.Errorf("group field %s: expected %d values, got %v", n.Name, len(n.children), v)
+	}
+	for i, child := range n.children {
+		if err := child.shred(values[i], rep, def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// null writes a null, or empty list, to all the leaf columns of the field.
+func (n *node) null(rep, def int) {
+	if n.column != nil {
+		n.column.levels(rep, def)
+		return
+	}
+	for _, child := range n.children {
+		child.null(rep, def)
+	}
+}
+
+// writeSchema writes the schema elements of the nodes, depth-first.
+func writeSchema(t *thriftWriter, nodes []*node) {
+	for _, n := range nodes {
+		children := len(n.children)
+
+		if n.List {
+			schemaElement(t, n.Name, Group, required, 1, logicalList)
+			schemaElement(t, "list", Group, repeated, 1, 0)
+			schemaElement(t, "element", n.Type, required, children, n.Logical)
+		} else {
+			repetition := required
+			if n.Optional {
+				repetition = optional
+			}
+			schemaElement(t, n.Name, n.Type, repetition, children, n.Logical)
+		}
+		writeSchema(t, n.children)
+	}
+}
+
+// schemaElement writes a SchemaElement, with a negative repetition for the
+// root element, which has none.
+func schemaElement(t *thriftWriter, name string, typ Type, repetition int32, children int, converted Logical) {
+	t.structBegin()
+	if typ != Group {
+		t.i32(1, int32(typ))
+	}
+	if repetition >= 0 {
+		t.i32(3, repetition)
+	}
+	t.string(4, name)
+	if children > 0 {
+		t.i32(5, int32(children))
+	}
+	if converted > 0 {
+		t.i32(6, int32(converted-1))
+	}
+	t.structEnd()
+}
+
+// bitWidth returns the number of bits needed for levels up to max.
+func bitWidth(max int) int {
+	return bits.Len(uint(max))
+}
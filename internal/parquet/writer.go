@@ -0,0 +1,389 @@
+// Package parquet implements a minimal Apache Parquet file writer, supporting
+// nested groups and lists, with each column chunk written as a single
+// gzip compressed, PLAIN encoded, data page.
+//
+// See https://github.com/apache/parquet-format for the file format.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const magic = "PAR1"
+
+// DefaultRowGroupSize is the number of rows buffered for each row group.
+const DefaultRowGroupSize = 10000
+
+// Parquet metadata enum values.
+const (
+	encodingPlain = 0
+	encodingRLE   = 3
+	codecGzip     = 2
+	pageTypeData  = 0
+)
+
+// Writer writes rows to a Parquet file. Rows are buffered in memory and
+// written as a row group every RowGroupSize rows, and the file metadata is
+// written on Close.
+type Writer struct {
+	RowGroupSize int
+
+	w         io.Writer
+	offset    int64
+	nodes     []*node
+	columns   []*column
+	rows      int64
+	rowGroups []rowGroup
+	numRows   int64
+	closed    bool
+}
+
+type rowGroup struct {
+	chunks    []columnChunk
+	numRows   int64
+	totalSize int64
+}
+
+type columnChunk struct {
+	column           *column
+	numValues        int64
+	uncompressedSize int64
+	compressedSize   int64
+	offset           int64
+}
+
+// NewWriter returns a Writer for the schema fields.
+func NewWriter(w io.Writer, fields []Field) (*Writer, error) {
+	pw := &Writer{RowGroupSize: DefaultRowGroupSize, w: w}
+
+	nodes, err := compile(fields, nil, 0, 0, &pw.columns)
+	if err != nil {
+		return nil, err
+	}
+	pw.nodes = nodes
+
+	return pw, nil
+}
+
+// Write a row, with a value for each of the schema fields.
+func (pw *Writer) Write(row []interface{}) error {
+	if pw.closed {
+		return errors.New("parquet writer is closed")
+	}
+	if len(row) != len(pw.nodes) {
+		return fmt.Errorf("expected %d values in row, got %d", len(pw.nodes), len(row))
+	}
+
+	for _, col := range pw.columns {
+		col.mark()
+	}
+	for i, n := range pw.nodes {
+		if err := n.shred(row[i], 0, 0); err != nil {
+			// discard the partially written row
+			for _, col := range pw.columns {
+				col.rollback()
+			}
+			return err
+		}
+	}
+	pw.rows++
+
+	if pw.rows >= int64(pw.RowGroupSize) {
+		return pw.flush()
+	}
+	return nil
+}
+
+// Close writes any buffered rows and the file metadata. It does not close
+// the underlying writer.
+func (pw *Writer) Close() error {
+	if pw.closed {
+		return nil
+	}
+	if err := pw.flush(); err != nil {
+		return err
+	}
+	pw.closed = true
+
+	if pw.offset == 0 {
+		if err := pw.write([]byte(magic)); err != nil {
+			return err
+		}
+	}
+
+	metadata := pw.fileMetaData()
+	footer := make([]byte, 4, 4+len(magic))
+	binary.LittleEndian.PutUint32(footer, uint32(len(metadata)))
+	footer = append(footer, magic...)
+
+	if err := pw.write(metadata); err != nil {
+		return err
+	}
+	return pw.write(footer)
+}
+
+// flush writes the buffered rows as a row group.
+func (pw *Writer) flush() error {
+	if pw.rows == 0 {
+		return nil
+	}
+	if pw.offset == 0 {
+		if err := pw.write([]byte(magic)); err != nil {
+			return err
+		}
+	}
+
+	group := rowGroup{numRows: pw.rows}
+	for _, col := range pw.columns {
+		header, page, err := col.page()
+		if err != nil {
+			return err
+		}
+
+		chunk := columnChunk{
+			column:           col,
+			numValues:        int64(col.count),
+			uncompressedSize: int64(len(header)) + int64(col.size),
+			compressedSize:   int64(len(header) + len(page)),
+			offset:           pw.offset,
+		}
+		if err := pw.write(header); err != nil {
+			return err
+		}
+		if err := pw.write(page); err != nil {
+			return err
+		}
+
+		group.chunks = append(group.chunks, chunk)
+		group.totalSize += chunk.uncompressedSize
+	}
+
+	pw.rowGroups = append(pw.rowGroups, group)
+	pw.numRows += pw.rows
+	pw.reset()
+
+	return nil
+}
+
+func (pw *Writer) reset() {
+	pw.rows = 0
+	for _, col := range pw.columns {
+		col.reset()
+	}
+}
+
+func (pw *Writer) write(data []byte) error {
+	n, err := pw.w.Write(data)
+	pw.offset += int64(n)
+	return err
+}
+
+// fileMetaData encodes the FileMetaData of the file footer.
+func (pw *Writer) fileMetaData() []byte {
+	t := &thriftWriter{}
+	t.structBegin()
+
+	t.i32(1, 1) // version
+	t.list(2, thriftStruct, 1+countElements(pw.nodes))
+	schemaElement(t, "schema", Group, -1, len(pw.nodes), None)
+	writeSchema(t, pw.nodes)
+	t.i64(3, pw.numRows)
+
+	t.list(4, thriftStruct, len(pw.rowGroups))
+	for _, group := range pw.rowGroups {
+		t.structBegin()
+		t.list(1, thriftStruct, len(group.chunks))
+		for _, chunk := range group.chunks {
+			t.structBegin()
+			t.i64(2, chunk.offset) // file_offset
+			t.structField(3)       // meta_data
+			t.i32(1, int32(chunk.column.typ))
+			t.list(2, thriftI32, 2)
+			t.varint(encodingPlain)
+			t.varint(encodingRLE)
+			t.list(3, thriftBinary, len(chunk.column.path))
+			for _, name := range chunk.column.path {
+				t.binary(name)
+			}
+			t.i32(4, codecGzip)
+			t.i64(5, chunk.numValues)
+			t.i64(6, chunk.uncompressedSize)
+			t.i64(7, chunk.compressedSize)
+			t.i64(9, chunk.offset) // data_page_offset
+			t.structEnd()
+			t.structEnd()
+		}
+		t.i64(2, group.totalSize)
+		t.i64(3, group.numRows)
+		t.structEnd()
+	}
+
+	t.string(6, "pgrdf")
+	t.structEnd()
+
+	return t.buf.Bytes()
+}
+
+// countElements returns the number of schema elements for the nodes, with a
+// list being written as three elements.
+func countElements(nodes []*node) int {
+	count := 0
+	for _, n := range nodes {
+		count++
+		if n.List {
+			count += 2
+		}
+		count += countElements(n.children)
+	}
+	return count
+}
+
+// column buffers the levels and values of a leaf field for a row group.
+type column struct {
+	path   []string
+	typ    Type
+	maxDef int
+	maxRep int
+
+	repLevels []int
+	defLevels []int
+	values    bytes.Buffer
+	count     int // number of levels, i.e. values including nulls
+	size      int // uncompressed page size
+
+	marked [4]int // lengths at the start of the current row
+}
+
+// add a value, PLAIN encoded, with its levels.
+func (c *column) add(v interface{}, rep, def int) error {
+	var b [8]byte
+
+	switch c.typ {
+	case Int32:
+		i, ok := v.(int32)
+		if !ok {
+			return fmt.Errorf("column %v: expected int32 value, got %T", c.path, v)
+		}
+		binary.LittleEndian.PutUint32(b[:], uint32(i))
+		c.values.Write(b[:4])
+	case Int64:
+		i, ok := v.(int64)
+		if !ok {
+			return fmt.Errorf("column %v: expected int64 value, got %T", c.path, v)
+		}
+		binary.LittleEndian.PutUint64(b[:], uint64(i))
+		c.values.Write(b[:8])
+	case ByteArray:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("column %v: expected string value, got %T", c.path, v)
+		}
+		binary.LittleEndian.PutUint32(b[:], uint32(len(s)))
+		c.values.Write(b[:4])
+		c.values.WriteString(s)
+	default:
+		return fmt.Errorf("column %v: unsupported type %d", c.path, c.typ)
+	}
+
+	c.levels(rep, def)
+	return nil
+}
+
+// levels adds the repetition and definition levels of a value, or of a null
+// when no value was added.
+func (c *column) levels(rep, def int) {
+	if c.maxRep > 0 {
+		c.repLevels = append(c.repLevels, rep)
+	}
+	if c.maxDef > 0 {
+		c.defLevels = append(c.defLevels, def)
+	}
+	c.count++
+}
+
+// mark the start of a row, for a rollback when the row is invalid.
+func (c *column) mark() {
+	c.marked = [4]int{len(c.repLevels), len(c.defLevels), c.values.Len(), c.count}
+}
+
+func (c *column) rollback() {
+	c.repLevels = c.repLevels[:c.marked[0]]
+	c.defLevels = c.defLevels[:c.marked[1]]
+	c.values.Truncate(c.marked[2])
+	c.count = c.marked[3]
+}
+
+func (c *column) reset() {
+	c.repLevels = c.repLevels[:0]
+	c.defLevels = c.defLevels[:0]
+	c.values.Reset()
+	c.count = 0
+	c.size = 0
+}
+
+// page returns the encoded page header and the compressed data page.
+func (c *column) page() (header, page []byte, err error) {
+	var data bytes.Buffer
+	if c.maxRep > 0 {
+		writeLevels(&data, c.repLevels, bitWidth(c.maxRep))
+	}
+	if c.maxDef > 0 {
+		writeLevels(&data, c.defLevels, bitWidth(c.maxDef))
+	}
+	data.Write(c.values.Bytes())
+	c.size = data.Len()
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(data.Bytes()); err != nil {
+		return nil, nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	t := &thriftWriter{}
+	t.structBegin()
+	t.i32(1, pageTypeData)
+	t.i32(2, int32(c.size))
+	t.i32(3, int32(compressed.Len()))
+	t.structField(5) // data_page_header
+	t.i32(1, int32(c.count))
+	t.i32(2, encodingPlain)
+	t.i32(3, encodingRLE)
+	t.i32(4, encodingRLE)
+	t.structEnd()
+	t.structEnd()
+
+	return t.buf.Bytes(), compressed.Bytes(), nil
+}
+
+// writeLevels writes the levels using the RLE/bit-packing hybrid encoding,
+// with only RLE runs, prefixed by the encoded length.
+func writeLevels(w *bytes.Buffer, levels []int, width int) {
+	var runs bytes.Buffer
+	var b [binary.MaxVarintLen64]byte
+	valueBytes := (width + 7) / 8
+
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		n := binary.PutUvarint(b[:], uint64(j-i)<<1)
+		runs.Write(b[:n])
+		for k := 0; k < valueBytes; k++ {
+			runs.WriteByte(byte(levels[i] >> (8 * k)))
+		}
+		i = j
+	}
+
+	binary.LittleEndian.PutUint32(b[:4], uint32(runs.Len()))
+	w.Write(b[:4])
+	w.Write(runs.Bytes())
+}
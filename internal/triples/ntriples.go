@@ -0,0 +1,67 @@
+package triples
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// WriteNTriples writes the graph in the N-Triples format, one triple per line.
+func WriteNTriples(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range g.Triples {
+		line := ntTerm(t.Subject) + " " + ntTerm(t.Predicate) + " " + ntTerm(t.Object) + " .\n"
+		if _, err := bw.WriteString(line); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ntTerm formats a term using the N-Triples syntax, which is also valid Turtle.
+func ntTerm(t Term) string {
+	switch t.Kind {
+	case KindIRI:
+		return "<" + escapeIRI(t.Value) + ">"
+	case KindBlank:
+		return "_:" + t.Value
+	default:
+		lit := `"` + escapeLiteral(t.Value) + `"`
+		if len(t.Language) > 0 {
+			return lit + "@" + t.Language
+		}
+		if len(t.Datatype) > 0 {
+			return lit + "^^<" + escapeIRI(t.Datatype) + ">"
+		}
+		return lit
+	}
+}
+
+var literalEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+func escapeLiteral(s string) string {
+	return literalEscaper.Replace(s)
+}
+
+var iriEscaper = strings.NewReplacer(
+	" ", "%20",
+	"<", "%3C",
+	">", "%3E",
+	`"`, "%22",
+	"{", "%7B",
+	"}", "%7D",
+	"|", "%7C",
+	`\`, "%5C",
+	"^", "%5E",
+	"`", "%60",
+)
+
+func escapeIRI(s string) string {
+	return iriEscaper.Replace(s)
+}
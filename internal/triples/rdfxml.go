@@ -0,0 +1,401 @@
+package triples
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	nsRDF = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsXML = "http://www.w3.org/XML/1998/namespace"
+)
+
+// ParseRDFXML reads an RDF/XML document and returns the graph it describes.
+//
+// Unlike the struct based unmarshaler, this does not depend on a particular
+// nesting of the elements, so typed node elements, `rdf:Description` nodes,
+// `rdf:parseType` ("Resource", "Literal", "Collection"), `rdf:nodeID`
+// references, property attributes, and `rdf:li` container members are all
+// supported.
+//
+// The base IRI is used for resolving relative IRIs when the document does
+// not declare its own `xml:base`.
+func ParseRDFXML(r io.Reader, base string) (*Graph, error) {
+	p := &rdfxmlParser{
+		d:       xml.NewDecoder(r),
+		g:       &Graph{},
+		nodeIDs: map[string]Term{},
+	}
+
+	for {
+		tok, err := p.d.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no RDF/XML root element found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if isRDF(start.Name, "RDF") {
+			base, lang := scope(start, base, "")
+			if err := p.nodeElements(base, lang); err != nil {
+				return nil, err
+			}
+		} else if _, err := p.nodeElement(start, base, ""); err != nil {
+			return nil, err
+		}
+		return p.g, nil
+	}
+}
+
+type rdfxmlParser struct {
+	d       *xml.Decoder
+	g       *Graph
+	nodeIDs map[string]Term // maps `rdf:nodeID` values to the graph blank nodes
+}
+
+// nodeElements parses all child node elements until the parent end element.
+func (p *rdfxmlParser) nodeElements(base, lang string) error {
+	for {
+		tok, err := p.d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if _, err := p.nodeElement(t, base, lang); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// nodeElement parses a node element and its property elements, returning the
+// subject term for the node.
+func (p *rdfxmlParser) nodeElement(start xml.StartElement, base, lang string) (Term, error) {
+	base, lang = scope(start, base, lang)
+
+	subject := p.g.NewBlank()
+	for _, attr := range start.Attr {
+		switch {
+		case isRDF(attr.Name, "about"):
+			subject = IRI(resolve(base, attr.Value))
+		case isRDF(attr.Name, "ID"):
+			subject = IRI(resolve(base, "#"+attr.Value))
+		case isRDF(attr.Name, "nodeID"):
+			subject = p.blank(attr.Value)
+		}
+	}
+
+	if !isRDF(start.Name, "Description") {
+		p.g.Add(subject, IRI(nsRDF+"type"), IRI(start.Name.Space+start.Name.Local))
+	}
+	p.propertyAttributes(subject, start.Attr, base, lang)
+
+	li := 0
+	for {
+		tok, err := p.d.Token()
+		if err != nil {
+			return Term{}, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := p.propertyElement(subject, t, base, lang, &li); err != nil {
+				return Term{}, err
+			}
+		case xml.EndElement:
+			return subject, nil
+		}
+	}
+}
+
+// propertyAttributes adds a triple for every non-syntax attribute of an element.
+func (p *rdfxmlParser) propertyAttributes(subject Term, attrs []xml.Attr, base, lang string) {
+	for _, attr := range attrs {
+		if isSyntaxAttr(attr.Name) {
+			continue
+		}
+		if isRDF(attr.Name, "type") {
+			p.g.Add(subject, IRI(nsRDF+"type"), IRI(resolve(base, attr.Value)))
+			continue
+		}
+		p.g.Add(subject, IRI(attr.Name.Space+attr.Name.Local), literal(attr.Value, "", lang))
+	}
+}
+
+// propertyElement parses a single property element of the given subject.
+func (p *rdfxmlParser) propertyElement(subject Term, start xml.StartElement, base, lang string, li *int) error {
+	base, lang = scope(start, base, lang)
+
+	predicate := IRI(start.Name.Space + start.Name.Local)
+	if isRDF(start.Name, "li") {
+		*li++
+		predicate = IRI(nsRDF + "_" + strconv.Itoa(*li))
+	}
+
+	var parseType, datatype string
+	var object Term
+	hasPropertyAttrs := false
+	for _, attr := range start.Attr {
+		switch {
+		case isRDF(attr.Name, "parseType"):
+			parseType = attr.Value
+		case isRDF(attr.Name, "datatype"):
+			datatype = resolve(base, attr.Value)
+		case isRDF(attr.Name, "resource"):
+			object = IRI(resolve(base, attr.Value))
+		case isRDF(attr.Name, "nodeID"):
+			object = p.blank(attr.Value)
+		case isRDF(attr.Name, "ID"), isSyntaxAttr(attr.Name):
+			// reification is not supported
+		default:
+			hasPropertyAttrs = true
+		}
+	}
+
+	switch parseType {
+	case "":
+		// handled below
+	case "Resource":
+		node := p.g.NewBlank()
+		p.g.Add(subject, predicate, node)
+		nodeLi := 0
+		return p.propertyElements(node, base, lang, &nodeLi)
+	case "Collection":
+		return p.collection(subject, predicate, base, lang)
+	default: // "Literal", and any unknown values, are handled as XML literals
+		data, err := p.innerXML()
+		if err != nil {
+			return err
+		}
+		p.g.Add(subject, predicate, TypedLiteral(data, nsRDF+"XMLLiteral"))
+		return nil
+	}
+
+	var text strings.Builder
+	for {
+		tok, err := p.d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			node, err := p.nodeElement(t, base, lang)
+			if err != nil {
+				return err
+			}
+			p.g.Add(subject, predicate, node)
+			return p.skipToEnd()
+		case xml.EndElement:
+			// an empty property element with property attributes describes a blank node
+			if object.IsZero() && hasPropertyAttrs {
+				object = p.g.NewBlank()
+			}
+			if object.IsZero() {
+				p.g.Add(subject, predicate, literal(text.String(), datatype, lang))
+				return nil
+			}
+			p.g.Add(subject, predicate, object)
+			p.propertyAttributes(object, start.Attr, base, lang)
+			return nil
+		}
+	}
+}
+
+// propertyElements parses child property elements until the parent end element.
+func (p *rdfxmlParser) propertyElements(subject Term, base, lang string, li *int) error {
+	for {
+		tok, err := p.d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := p.propertyElement(subject, t, base, lang, li); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// collection parses the node elements of an `rdf:parseType="Collection"`
+// property into an RDF list.
+func (p *rdfxmlParser) collection(subject, predicate Term, base, lang string) error {
+	var nodes []Term
+	for {
+		tok, err := p.d.Token()
+		if err != nil {
+			return err
+		}
+		if t, ok := tok.(xml.StartElement); ok {
+			node, err := p.nodeElement(t, base, lang)
+			if err != nil {
+				return err
+			}
+			nodes = append(nodes, node)
+			continue
+		}
+		if _, ok := tok.(xml.EndElement); ok {
+			break
+		}
+	}
+
+	if len(nodes) == 0 {
+		p.g.Add(subject, predicate, IRI(nsRDF+"nil"))
+		return nil
+	}
+
+	list := p.g.NewBlank()
+	p.g.Add(subject, predicate, list)
+	for i, node := range nodes {
+		p.g.Add(list, IRI(nsRDF+"first"), node)
+		if i == len(nodes)-1 {
+			p.g.Add(list, IRI(nsRDF+"rest"), IRI(nsRDF+"nil"))
+			break
+		}
+		next := p.g.NewBlank()
+		p.g.Add(list, IRI(nsRDF+"rest"), next)
+		list = next
+	}
+	return nil
+}
+
+// innerXML re-encodes the content of the current element as an XML string.
+func (p *rdfxmlParser) innerXML() (string, error) {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+
+	depth := 0
+	for {
+		tok, err := p.d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				if err := enc.Flush(); err != nil {
+					return "", err
+				}
+				return buf.String(), nil
+			}
+			depth--
+		}
+		if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return "", err
+		}
+	}
+}
+
+// skipToEnd consumes tokens until the end of the current element.
+func (p *rdfxmlParser) skipToEnd() error {
+	depth := 0
+	for {
+		tok, err := p.d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return nil
+			}
+			depth--
+		}
+	}
+}
+
+// blank returns the graph blank node for an `rdf:nodeID` value.
+func (p *rdfxmlParser) blank(nodeID string) Term {
+	if node, ok := p.nodeIDs[nodeID]; ok {
+		return node
+	}
+	node := p.g.NewBlank()
+	p.nodeIDs[nodeID] = node
+	return node
+}
+
+// scope applies any `xml:base` and `xml:lang` attributes of the element.
+func scope(start xml.StartElement, base, lang string) (string, string) {
+	for _, attr := range start.Attr {
+		if !isXMLAttr(attr.Name) {
+			continue
+		}
+		switch attr.Name.Local {
+		case "base":
+			base = resolve(base, attr.Value)
+		case "lang":
+			lang = attr.Value
+		}
+	}
+	return base, lang
+}
+
+// resolve a (possibly relative) IRI reference against the base IRI.
+func resolve(base, ref string) string {
+	if len(base) == 0 {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil || r.IsAbs() {
+		return ref
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+func literal(value, datatype, lang string) Term {
+	if len(datatype) > 0 {
+		return TypedLiteral(value, datatype)
+	}
+	if len(lang) > 0 {
+		return LangLiteral(value, lang)
+	}
+	return Literal(value)
+}
+
+func isRDF(name xml.Name, local string) bool {
+	return name.Space == nsRDF && name.Local == local
+}
+
+func isXMLAttr(name xml.Name) bool {
+	return name.Space == nsXML || name.Space == "xml"
+}
+
+// isSyntaxAttr reports whether the attribute is an XML or namespace declaration,
+// or one of the RDF syntax attributes, i.e. not a property attribute.
+func isSyntaxAttr(name xml.Name) bool {
+	if isXMLAttr(name) || name.Space == "xmlns" || (name.Space == "" && name.Local == "xmlns") {
+		return true
+	}
+	if name.Space != nsRDF {
+		return false
+	}
+	switch name.Local {
+	case "about", "ID", "nodeID", "resource", "datatype", "parseType", "bagID", "aboutEach", "aboutEachPrefix":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package triples_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf/internal/triples"
+)
+
+const (
+	rdfNs     = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	dcTermsNs = "http://purl.org/dc/terms/"
+)
+
+func parse(t *testing.T, doc string) *triples.Graph {
+	t.Helper()
+
+	g, err := triples.ParseRDFXML(strings.NewReader(doc), "http://www.gutenberg.org/")
+	if err != nil {
+		t.Fatalf("unexpected error parsing RDF/XML: %s", err)
+	}
+	return g
+}
+
+func TestParseRDFXML_TypedNodesAndBase(t *testing.T) {
+	g := parse(t, `<rdf:RDF xml:base="http://example.org/" xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:pgterms="http://www.gutenberg.org/2009/pgterms/">
+  <pgterms:ebook rdf:about="ebooks/1" dcterms:publisher="Project Gutenberg">
+    <dcterms:title xml:lang="en">A Title</dcterms:title>
+    <dcterms:issued rdf:datatype="http://www.w3.org/2001/XMLSchema#date">2001-01-01</dcterms:issued>
+  </pgterms:ebook>
+</rdf:RDF>`)
+
+	book := triples.IRI("http://example.org/ebooks/1")
+	if obj := g.Object(book, rdfNs+"type"); obj.Value != "http://www.gutenberg.org/2009/pgterms/ebook" {
+		t.Errorf("unexpected rdf:type, got '%s'", obj.Value)
+	}
+	if obj := g.Object(book, dcTermsNs+"publisher"); obj != triples.Literal("Project Gutenberg") {
+		t.Errorf("unexpected property attribute value, got %v", obj)
+	}
+	if obj := g.Object(book, dcTermsNs+"title"); obj != triples.LangLiteral("A Title", "en") {
+		t.Errorf("unexpected title, got %v", obj)
+	}
+	if obj := g.Object(book, dcTermsNs+"issued"); obj.Datatype != "http://www.w3.org/2001/XMLSchema#date" {
+		t.Errorf("unexpected datatype, got '%s'", obj.Datatype)
+	}
+}
+
+func TestParseRDFXML_ParseTypeResource(t *testing.T) {
+	g := parse(t, `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:dcam="http://purl.org/dc/dcam/">
+  <rdf:Description rdf:about="ebooks/1">
+    <dcterms:subject rdf:parseType="Resource">
+      <rdf:value>Fiction</rdf:value>
+      <dcam:memberOf rdf:resource="http://purl.org/dc/terms/LCSH"/>
+    </dcterms:subject>
+  </rdf:Description>
+</rdf:RDF>`)
+
+	subject := g.Object(triples.IRI("http://www.gutenberg.org/ebooks/1"), dcTermsNs+"subject")
+	if !subject.IsBlank() {
+		t.Fatalf("expected a blank node subject, got %v", subject)
+	}
+	if obj := g.Object(subject, rdfNs+"value"); obj.Value != "Fiction" {
+		t.Errorf("unexpected rdf:value, got '%s'", obj.Value)
+	}
+	if obj := g.Object(subject, "http://purl.org/dc/dcam/memberOf"); obj.Value != "http://purl.org/dc/terms/LCSH" {
+		t.Errorf("unexpected dcam:memberOf, got '%s'", obj.Value)
+	}
+}
+
+func TestParseRDFXML_NodeIDReferences(t *testing.T) {
+	g := parse(t, `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:dcterms="http://purl.org/dc/terms/">
+  <rdf:Description rdf:about="ebooks/1">
+    <dcterms:language rdf:nodeID="lang1"/>
+  </rdf:Description>
+  <rdf:Description rdf:nodeID="lang1">
+    <rdf:value>en</rdf:value>
+  </rdf:Description>
+</rdf:RDF>`)
+
+	lang := g.Object(triples.IRI("http://www.gutenberg.org/ebooks/1"), dcTermsNs+"language")
+	if !lang.IsBlank() {
+		t.Fatalf("expected a blank node, got %v", lang)
+	}
+	if obj := g.Object(lang, rdfNs+"value"); obj.Value != "en" {
+		t.Errorf("unexpected rdf:value, got '%s'", obj.Value)
+	}
+}
+
+func TestParseRDFXML_CollectionAndListItems(t *testing.T) {
+	g := parse(t, `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.org/">
+  <rdf:Description rdf:about="http://example.org/a">
+    <ex:list rdf:parseType="Collection">
+      <rdf:Description rdf:about="http://example.org/b"/>
+    </ex:list>
+  </rdf:Description>
+  <rdf:Bag rdf:about="http://example.org/bag">
+    <rdf:li>one</rdf:li>
+    <rdf:li>two</rdf:li>
+  </rdf:Bag>
+</rdf:RDF>`)
+
+	list := g.Object(triples.IRI("http://example.org/a"), "http://example.org/list")
+	if obj := g.Object(list, rdfNs+"first"); obj.Value != "http://example.org/b" {
+		t.Errorf("unexpected rdf:first, got '%s'", obj.Value)
+	}
+	if obj := g.Object(list, rdfNs+"rest"); obj.Value != rdfNs+"nil" {
+		t.Errorf("unexpected rdf:rest, got '%s'", obj.Value)
+	}
+
+	bag := triples.IRI("http://example.org/bag")
+	if obj := g.Object(bag, rdfNs+"_2"); obj.Value != "two" {
+		t.Errorf("unexpected rdf:_2, got '%s'", obj.Value)
+	}
+}
+
+func TestParseRDFXML_InvalidDocument(t *testing.T) {
+	if _, err := triples.ParseRDFXML(strings.NewReader(""), ""); err == nil {
+		t.Error("expected an error for an empty document")
+	}
+}
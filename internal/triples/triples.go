@@ -0,0 +1,133 @@
+// Package triples contains a minimal RDF graph model of subject, predicate,
+// object triples, along with writers for the N-Triples and Turtle formats.
+package triples
+
+import "fmt"
+
+// Kind of RDF term.
+type Kind int
+
+const (
+	KindIRI Kind = iota
+	KindBlank
+	KindLiteral
+)
+
+// Term is an RDF term: an IRI, a blank node, or a literal.
+type Term struct {
+	Kind  Kind
+	Value string
+
+	// Datatype IRI and Language are only used by literals.
+	Datatype string
+	Language string
+}
+
+// IRI creates a new IRI term.
+func IRI(value string) Term {
+	return Term{Kind: KindIRI, Value: value}
+}
+
+// Blank creates a blank node term with the given label.
+func Blank(label string) Term {
+	return Term{Kind: KindBlank, Value: label}
+}
+
+// Literal creates a plain literal term.
+func Literal(value string) Term {
+	return Term{Kind: KindLiteral, Value: value}
+}
+
+// TypedLiteral creates a literal term with the given datatype IRI.
+func TypedLiteral(value, datatype string) Term {
+	return Term{Kind: KindLiteral, Value: value, Datatype: datatype}
+}
+
+// LangLiteral creates a literal term with the given language tag.
+func LangLiteral(value, language string) Term {
+	return Term{Kind: KindLiteral, Value: value, Language: language}
+}
+
+func (t Term) IsIRI() bool     { return t.Kind == KindIRI }
+func (t Term) IsBlank() bool   { return t.Kind == KindBlank }
+func (t Term) IsLiteral() bool { return t.Kind == KindLiteral }
+
+// IsZero reports whether the term has not been set.
+func (t Term) IsZero() bool {
+	return t == Term{}
+}
+
+// Triple is a single RDF statement.
+type Triple struct {
+	Subject   Term
+	Predicate Term
+	Object    Term
+}
+
+// Graph is an ordered set of triples. The order in which triples are added
+// is preserved by the writers.
+type Graph struct {
+	Triples []Triple
+
+	blankNodes int
+	index      map[Term][]Triple // triples by subject, built on first lookup
+}
+
+// Add a new triple to the graph.
+func (g *Graph) Add(subject, predicate, object Term) {
+	g.Triples = append(g.Triples, Triple{Subject: subject, Predicate: predicate, Object: object})
+	g.index = nil
+}
+
+// Outgoing returns all triples for the given subject, in the order they were added.
+func (g *Graph) Outgoing(subject Term) []Triple {
+	if g.index == nil {
+		g.index = make(map[Term][]Triple)
+		for _, t := range g.Triples {
+			g.index[t.Subject] = append(g.index[t.Subject], t)
+		}
+	}
+	return g.index[subject]
+}
+
+// Objects returns the objects of all triples matching the subject and predicate IRI.
+func (g *Graph) Objects(subject Term, predicate string) []Term {
+	var objects []Term
+	for _, t := range g.Outgoing(subject) {
+		if t.Predicate.Value == predicate {
+			objects = append(objects, t.Object)
+		}
+	}
+	return objects
+}
+
+// Object returns the first object matching the subject and predicate IRI,
+// or a zero Term when none is found.
+func (g *Graph) Object(subject Term, predicate string) Term {
+	for _, t := range g.Outgoing(subject) {
+		if t.Predicate.Value == predicate {
+			return t.Object
+		}
+	}
+	return Term{}
+}
+
+// Subjects returns the unique subjects of all triples matching the predicate
+// IRI and object, in the order they were added.
+func (g *Graph) Subjects(predicate string, object Term) []Term {
+	var subjects []Term
+	seen := map[Term]bool{}
+	for _, t := range g.Triples {
+		if t.Predicate.Value == predicate && t.Object == object && !seen[t.Subject] {
+			seen[t.Subject] = true
+			subjects = append(subjects, t.Subject)
+		}
+	}
+	return subjects
+}
+
+// NewBlank generates a blank node with a label unique to this graph.
+func (g *Graph) NewBlank() Term {
+	g.blankNodes++
+	return Blank(fmt.Sprintf("b%d", g.blankNodes))
+}
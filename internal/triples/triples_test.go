@@ -0,0 +1,64 @@
+package triples_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mrcook/pgrdf/internal/triples"
+)
+
+func sampleGraph() *triples.Graph {
+	g := &triples.Graph{}
+	book := triples.IRI("http://www.gutenberg.org/ebooks/11")
+	g.Add(book, triples.IRI("http://www.w3.org/1999/02/22-rdf-syntax-ns#type"), triples.IRI("http://www.gutenberg.org/2009/pgterms/ebook"))
+	g.Add(book, triples.IRI("http://purl.org/dc/terms/title"), triples.Literal("Alice's \"Adventures\"\nin Wonderland"))
+	lang := g.NewBlank()
+	g.Add(book, triples.IRI("http://purl.org/dc/terms/language"), lang)
+	g.Add(lang, triples.IRI("http://www.w3.org/1999/02/22-rdf-syntax-ns#value"), triples.TypedLiteral("en", "http://purl.org/dc/terms/RFC4646"))
+	return g
+}
+
+func TestWriteNTriples(t *testing.T) {
+	w := bytes.NewBuffer([]byte{})
+	if err := triples.WriteNTriples(w, sampleGraph()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `<http://www.gutenberg.org/ebooks/11> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.gutenberg.org/2009/pgterms/ebook> .
+<http://www.gutenberg.org/ebooks/11> <http://purl.org/dc/terms/title> "Alice's \"Adventures\"\nin Wonderland" .
+<http://www.gutenberg.org/ebooks/11> <http://purl.org/dc/terms/language> _:b1 .
+_:b1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#value> "en"^^<http://purl.org/dc/terms/RFC4646> .
+`
+	if w.String() != expected {
+		t.Errorf("unexpected N-Triples output:\n%s", w.String())
+	}
+}
+
+func TestWriteTurtle(t *testing.T) {
+	prefixes := []triples.Prefix{
+		{Name: "dcterms", IRI: "http://purl.org/dc/terms/"},
+		{Name: "pgterms", IRI: "http://www.gutenberg.org/2009/pgterms/"},
+		{Name: "rdf", IRI: "http://www.w3.org/1999/02/22-rdf-syntax-ns#"},
+	}
+
+	w := bytes.NewBuffer([]byte{})
+	if err := triples.WriteTurtle(w, sampleGraph(), "http://www.gutenberg.org/", prefixes); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `@base <http://www.gutenberg.org/> .
+@prefix dcterms: <http://purl.org/dc/terms/> .
+@prefix pgterms: <http://www.gutenberg.org/2009/pgterms/> .
+@prefix rdf: <http://www.w3.org/1999/02/22-rdf-syntax-ns#> .
+
+<ebooks/11>
+    a pgterms:ebook ;
+    dcterms:title "Alice's \"Adventures\"\nin Wonderland" ;
+    dcterms:language [
+        rdf:value "en"^^dcterms:RFC4646
+    ] .
+`
+	if w.String() != expected {
+		t.Errorf("unexpected Turtle output:\n%s", w.String())
+	}
+}
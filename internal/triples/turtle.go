@@ -0,0 +1,152 @@
+package triples
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const rdfType = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+
+// Prefix maps a Turtle prefix name to a namespace IRI, e.g. `dcterms` to
+// `http://purl.org/dc/terms/`.
+type Prefix struct {
+	Name string
+	IRI  string
+}
+
+// localNameRE matches the local part of a prefixed name which is safe to write.
+var localNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// WriteTurtle writes the graph in the Turtle format. Triples are grouped by
+// subject, and any blank node which is the object of only one triple is
+// nested in place using the `[ ... ]` syntax.
+//
+// When a base IRI is given, IRIs within the base which do not match any of
+// the prefixes are written relative to it.
+func WriteTurtle(w io.Writer, g *Graph, base string, prefixes []Prefix) error {
+	t := &turtle{
+		base:      base,
+		prefixes:  prefixes,
+		bySubject: map[Term][]Triple{},
+		objects:   map[Term]int{},
+	}
+	for _, triple := range g.Triples {
+		if _, ok := t.bySubject[triple.Subject]; !ok {
+			t.subjects = append(t.subjects, triple.Subject)
+		}
+		t.bySubject[triple.Subject] = append(t.bySubject[triple.Subject], triple)
+		if triple.Object.IsBlank() {
+			t.objects[triple.Object]++
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	if len(base) > 0 {
+		bw.WriteString("@base <" + escapeIRI(base) + "> .\n")
+	}
+	for _, p := range prefixes {
+		bw.WriteString("@prefix " + p.Name + ": <" + escapeIRI(p.IRI) + "> .\n")
+	}
+
+	for _, subject := range t.subjects {
+		if t.isNested(subject) {
+			continue
+		}
+		bw.WriteString("\n" + t.term(subject) + "\n")
+		bw.WriteString(t.predicates(subject, "    ", map[Term]bool{subject: true}))
+		bw.WriteString(" .\n")
+	}
+
+	return bw.Flush()
+}
+
+type turtle struct {
+	base      string
+	prefixes  []Prefix
+	subjects  []Term
+	bySubject map[Term][]Triple
+	objects   map[Term]int // number of times a blank node is used as an object
+}
+
+// isNested reports whether a blank node can be written in place.
+func (t *turtle) isNested(term Term) bool {
+	return term.IsBlank() && t.objects[term] == 1
+}
+
+// predicates writes the predicate/object list for a subject.
+// The visited set guards against cycles between nested blank nodes.
+func (t *turtle) predicates(subject Term, indent string, visited map[Term]bool) string {
+	var order []Term
+	objects := map[Term][]Term{}
+	for _, triple := range t.bySubject[subject] {
+		if _, ok := objects[triple.Predicate]; !ok {
+			order = append(order, triple.Predicate)
+		}
+		objects[triple.Predicate] = append(objects[triple.Predicate], triple.Object)
+	}
+
+	var lines []string
+	for _, predicate := range order {
+		var objs []string
+		separator := ",\n" + indent + "    "
+		for _, obj := range objects[predicate] {
+			if t.isNested(obj) {
+				separator = ", " // keeps nested blank nodes aligned: `], [`
+			}
+			objs = append(objs, t.object(obj, indent, visited))
+		}
+		pred := t.term(predicate)
+		if predicate.Value == rdfType {
+			pred = "a"
+		}
+		lines = append(lines, indent+pred+" "+strings.Join(objs, separator))
+	}
+	return strings.Join(lines, " ;\n")
+}
+
+func (t *turtle) object(obj Term, indent string, visited map[Term]bool) string {
+	if !t.isNested(obj) || visited[obj] {
+		return t.term(obj)
+	}
+	if len(t.bySubject[obj]) == 0 {
+		return "[]"
+	}
+	visited[obj] = true
+	return "[\n" + t.predicates(obj, indent+"    ", visited) + "\n" + indent + "]"
+}
+
+func (t *turtle) term(term Term) string {
+	switch term.Kind {
+	case KindIRI:
+		return t.iri(term.Value)
+	case KindLiteral:
+		if len(term.Datatype) > 0 && len(term.Language) == 0 {
+			return `"` + escapeLiteral(term.Value) + `"^^` + t.iri(term.Datatype)
+		}
+		return ntTerm(term)
+	default:
+		return ntTerm(term)
+	}
+}
+
+// iri writes an IRI as a prefixed name when possible, using the longest
+// matching namespace, otherwise relative to the base, or as a full IRI.
+func (t *turtle) iri(value string) string {
+	var match *Prefix
+	for i, p := range t.prefixes {
+		if strings.HasPrefix(value, p.IRI) && localNameRE.MatchString(value[len(p.IRI):]) {
+			if match == nil || len(p.IRI) > len(match.IRI) {
+				match = &t.prefixes[i]
+			}
+		}
+	}
+	if match != nil {
+		return match.Name + ":" + value[len(match.IRI):]
+	}
+	if len(t.base) > 0 && strings.HasPrefix(value, t.base) && len(value) > len(t.base) {
+		return "<" + escapeIRI(value[len(t.base):]) + ">"
+	}
+	return "<" + escapeIRI(value) + ">"
+}
@@ -0,0 +1,159 @@
+package pgrdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONSchemaVersion is the current version of the pgrdf JSON format, which is
+// written to the `schema` field of the JSON envelope.
+const JSONSchemaVersion = "pgrdf/v1"
+
+// jsonLegacyVersion identifies JSON documents written before the envelope was
+// introduced, i.e. the result of calling `json.Marshal` on an Ebook (v1.8.0 and earlier).
+const jsonLegacyVersion = "pgrdf/v0"
+
+// jsonEnvelope wraps an Ebook with the schema version. The Ebook fields are
+// promoted so the JSON document stays flat: `{"schema":"pgrdf/v1","id":1400,...}`.
+type jsonEnvelope struct {
+	Schema string `json:"schema"`
+	*Ebook
+}
+
+// jsonMigration upgrades a decoded JSON document by one version, returning
+// the version it was migrated to.
+type jsonMigration func(doc map[string]json.RawMessage) (string, error)
+
+// jsonMigrations is keyed by the version each migration upgrades from.
+var jsonMigrations = map[string]jsonMigration{
+	jsonLegacyVersion: migrateJSONv0,
+}
+
+// WriteJSON marshals the Ebook to a versioned JSON document and writes it to
+// the provided `io.Writer`.
+func (e *Ebook) WriteJSON(w io.Writer) error {
+	data, err := json.Marshal(jsonEnvelope{Schema: JSONSchemaVersion, Ebook: e})
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReadJSON document from the given `io.Reader` and unmarshal to an Ebook.
+// Documents written with an older schema version, including those without
+// a `schema` field, are migrated to the current version before decoding.
+func ReadJSON(r io.Reader) (*Ebook, error) {
+	var doc map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	version := jsonLegacyVersion
+	if raw, ok := doc["schema"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, fmt.Errorf("invalid JSON schema field: %w", err)
+		}
+	}
+
+	for version != JSONSchemaVersion {
+		migrate, ok := jsonMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("unsupported JSON schema version '%s'", version)
+		}
+		next, err := migrate(doc)
+		if err != nil {
+			return nil, fmt.Errorf("migrating JSON from '%s': %w", version, err)
+		}
+		version = next
+	}
+	delete(doc, "schema")
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	ebook := &Ebook{}
+	if err := json.Unmarshal(data, ebook); err != nil {
+		return nil, err
+	}
+
+	return ebook, nil
+}
+
+// migrateJSONv0 upgrades a document without a schema version to v1:
+//
+//   - renames `pg_dp_clearance`, `note`, and `source_link` fields,
+//   - renames the bookshelf `subject` field to `name`,
+//   - converts `book_covers` from strings to BookCover objects.
+func migrateJSONv0(doc map[string]json.RawMessage) (string, error) {
+	renameJSONField(doc, "pg_dp_clearance", "copyright_clearance_code")
+	renameJSONField(doc, "note", "notes")
+	renameJSONField(doc, "source_link", "source_links")
+
+	if raw, ok := doc["bookshelves"]; ok && !isJSONNull(raw) {
+		var shelves []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &shelves); err != nil {
+			return "", fmt.Errorf("bookshelves: %w", err)
+		}
+		for _, shelf := range shelves {
+			renameJSONField(shelf, "subject", "name")
+		}
+		data, err := json.Marshal(shelves)
+		if err != nil {
+			return "", err
+		}
+		doc["bookshelves"] = data
+	}
+
+	if raw, ok := doc["book_covers"]; ok && !isJSONNull(raw) {
+		var covers []json.RawMessage
+		if err := json.Unmarshal(raw, &covers); err != nil {
+			return "", fmt.Errorf("book_covers: %w", err)
+		}
+		var migrated []BookCover
+		for _, c := range covers {
+			var source string
+			if err := json.Unmarshal(c, &source); err != nil {
+				// already a BookCover object
+				var cover BookCover
+				if err := json.Unmarshal(c, &cover); err != nil {
+					return "", fmt.Errorf("book_covers: %w", err)
+				}
+				migrated = append(migrated, cover)
+				continue
+			}
+			migrated = append(migrated, NewBookCover(source))
+		}
+		data, err := json.Marshal(migrated)
+		if err != nil {
+			return "", err
+		}
+		doc["book_covers"] = data
+	}
+
+	return "pgrdf/v1", nil
+}
+
+// renameJSONField moves a field to its new name, unless the new name is already present.
+func renameJSONField(doc map[string]json.RawMessage, from, to string) {
+	raw, ok := doc[from]
+	if !ok {
+		return
+	}
+	delete(doc, from)
+	if _, exists := doc[to]; !exists {
+		doc[to] = raw
+	}
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
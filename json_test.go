@@ -0,0 +1,120 @@
+package pgrdf_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+)
+
+var updateSchema = flag.Bool("update-schema", false, "regenerate the published JSON Schema document")
+
+const publishedSchemaFile = "schema/pgrdf-v1.schema.json"
+
+func TestEbook_WriteJSON(t *testing.T) {
+	ebook := generateEbook()
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteJSON(w); err != nil {
+		t.Fatalf("error writing JSON: %s", err)
+	}
+
+	if !strings.HasPrefix(w.String(), `{"schema":"pgrdf/v1","id":11,`) {
+		t.Errorf("unexpected JSON envelope, got: %s", w.String())
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	ebook := generateEbook()
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteJSON(w); err != nil {
+		t.Fatalf("error writing JSON: %s", err)
+	}
+
+	e, err := pgrdf.ReadJSON(w)
+	if err != nil {
+		t.Fatalf("error reading JSON: %s", err)
+	}
+
+	if e.ID != 11 {
+		t.Errorf("unexpected ID, got %d", e.ID)
+	}
+	if e.CopyrightClearanceCode != "20001231235959randomthing" {
+		t.Errorf("unexpected clearance code, got '%s'", e.CopyrightClearanceCode)
+	}
+	if len(e.Bookshelves) != 1 || e.Bookshelves[0].Name != "Children's Literature" {
+		t.Errorf("unexpected bookshelves, got %v", e.Bookshelves)
+	}
+}
+
+func TestReadJSON_MigratesLegacyDocument(t *testing.T) {
+	legacy := `{
+		"id": 1400,
+		"titles": ["Great Expectations"],
+		"pg_dp_clearance": "20050213050736stahl",
+		"note": ["A note"],
+		"source_link": ["https://example.com/scans"],
+		"book_covers": ["file:///files/1400/1400-h/images/cover.jpg"],
+		"bookshelves": [{"subject": "Best Books Ever Listings", "resource": "2009/pgterms/Bookshelf"}]
+	}`
+
+	e, err := pgrdf.ReadJSON(strings.NewReader(legacy))
+	if err != nil {
+		t.Fatalf("error reading JSON: %s", err)
+	}
+
+	if e.CopyrightClearanceCode != "20050213050736stahl" {
+		t.Errorf("unexpected clearance code, got '%s'", e.CopyrightClearanceCode)
+	}
+	if len(e.Notes) != 1 || e.Notes[0] != "A note" {
+		t.Errorf("unexpected notes, got %v", e.Notes)
+	}
+	if len(e.SourceLinks) != 1 {
+		t.Errorf("expected 1 source link, got %d", len(e.SourceLinks))
+	}
+	if len(e.Bookshelves) != 1 || e.Bookshelves[0].Name != "Best Books Ever Listings" {
+		t.Errorf("unexpected bookshelves, got %v", e.Bookshelves)
+	}
+	if len(e.BookCovers) != 1 {
+		t.Fatalf("expected 1 book cover, got %d", len(e.BookCovers))
+	}
+	if e.BookCovers[0].Filename != "images/cover.jpg" {
+		t.Errorf("unexpected book cover filename, got '%s'", e.BookCovers[0].Filename)
+	}
+	if e.BookCovers[0].Source != "file:///files/1400/1400-h/images/cover.jpg" {
+		t.Errorf("unexpected book cover source, got '%s'", e.BookCovers[0].Source)
+	}
+}
+
+func TestReadJSON_UnsupportedVersion(t *testing.T) {
+	_, err := pgrdf.ReadJSON(strings.NewReader(`{"schema":"pgrdf/v99","id":1}`))
+	if err == nil {
+		t.Fatal("expected an error for an unsupported schema version")
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := pgrdf.JSONSchema()
+	if err != nil {
+		t.Fatalf("error generating JSON Schema: %s", err)
+	}
+	schema = append(schema, '\n')
+
+	if *updateSchema {
+		if err := os.WriteFile(publishedSchemaFile, schema, 0644); err != nil {
+			t.Fatalf("error writing JSON Schema: %s", err)
+		}
+	}
+
+	published, err := os.ReadFile(publishedSchemaFile)
+	if err != nil {
+		t.Fatalf("error reading published JSON Schema: %s", err)
+	}
+	if !bytes.Equal(schema, published) {
+		t.Errorf("published JSON Schema is out of date, regenerate with: go test -run TestJSONSchema -update-schema")
+	}
+}
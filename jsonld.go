@@ -0,0 +1,196 @@
+package pgrdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// jsonLDBook is a schema.org `Book`, see https://schema.org/Book.
+type jsonLDBook struct {
+	Context         string             `json:"@context"`
+	Type            string             `json:"@type"`
+	ID              string             `json:"@id"`
+	URL             string             `json:"url"`
+	Name            string             `json:"name,omitempty"`
+	AlternateNames  []string           `json:"alternateName,omitempty"`
+	Description     string             `json:"description,omitempty"`
+	BookFormat      string             `json:"bookFormat"`
+	ISBN            string             `json:"isbn,omitempty"`
+	BookEdition     string             `json:"bookEdition,omitempty"`
+	DatePublished   string             `json:"datePublished,omitempty"`
+	Publisher       *jsonLDAgent       `json:"publisher,omitempty"`
+	Authors         []jsonLDAgent      `json:"author,omitempty"`
+	Illustrators    []jsonLDAgent      `json:"illustrator,omitempty"`
+	Translators     []jsonLDAgent      `json:"translator,omitempty"`
+	Editors         []jsonLDAgent      `json:"editor,omitempty"`
+	Contributors    []jsonLDAgent      `json:"contributor,omitempty"`
+	Languages       []string           `json:"inLanguage,omitempty"`
+	About           []jsonLDTerm       `json:"about,omitempty"`
+	Genres          []string           `json:"genre,omitempty"`
+	CopyrightNotice string             `json:"copyrightNotice,omitempty"`
+	License         string             `json:"license,omitempty"`
+	Image           []string           `json:"image,omitempty"`
+	Encodings       []jsonLDMedia      `json:"encoding,omitempty"`
+	Interactions    *jsonLDInteraction `json:"interactionStatistic,omitempty"`
+}
+
+// jsonLDAgent is a schema.org `Person` or `Organization`.
+type jsonLDAgent struct {
+	Type           string   `json:"@type"`
+	ID             string   `json:"@id,omitempty"`
+	Name           string   `json:"name,omitempty"`
+	AlternateNames []string `json:"alternateName,omitempty"`
+	BirthDate      string   `json:"birthDate,omitempty"`
+	DeathDate      string   `json:"deathDate,omitempty"`
+	SameAs         []string `json:"sameAs,omitempty"`
+}
+
+// jsonLDTerm is a schema.org `DefinedTerm`, used for the LCSH/LCC subjects.
+type jsonLDTerm struct {
+	Type             string `json:"@type"`
+	Name             string `json:"name"`
+	InDefinedTermSet string `json:"inDefinedTermSet,omitempty"`
+}
+
+// jsonLDMedia is a schema.org `MediaObject`, used for the ebook files.
+type jsonLDMedia struct {
+	Type           string `json:"@type"`
+	ContentURL     string `json:"contentUrl"`
+	EncodingFormat string `json:"encodingFormat,omitempty"`
+	ContentSize    string `json:"contentSize,omitempty"`
+	DateModified   string `json:"dateModified,omitempty"`
+}
+
+// jsonLDInteraction is a schema.org `InteractionCounter`, used for the downloads count.
+type jsonLDInteraction struct {
+	Type                 string `json:"@type"`
+	InteractionType      string `json:"interactionType"`
+	UserInteractionCount int    `json:"userInteractionCount"`
+}
+
+// WriteJSONLD marshals the Ebook to a schema.org `Book` JSON-LD document and
+// writes it to the provided `io.Writer`.
+//
+// Creators are mapped to `author`, `illustrator`, `translator`, and `editor`
+// by their role, with all other roles added as a `contributor`.
+func (e *Ebook) WriteJSONLD(w io.Writer) error {
+	data, err := json.MarshalIndent(e.jsonLD(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *Ebook) jsonLD() *jsonLDBook {
+	url := e.URL()
+
+	book := &jsonLDBook{
+		Context:         "https://schema.org",
+		Type:            "Book",
+		ID:              url,
+		URL:             url,
+		Description:     e.Summary,
+		BookFormat:      "https://schema.org/EBook",
+		ISBN:            e.ISBN,
+		BookEdition:     e.EditionNote,
+		DatePublished:   e.ReleaseDate,
+		Languages:       e.Languages,
+		CopyrightNotice: e.Copyright,
+		License:         e.CCLicense,
+	}
+
+	if len(e.Titles) > 0 {
+		book.Name = e.Titles[0]
+		book.AlternateNames = append(book.AlternateNames, e.Titles[1:]...)
+	}
+	book.AlternateNames = append(book.AlternateNames, e.AlternateTitles...)
+
+	if len(e.Publisher) > 0 {
+		book.Publisher = &jsonLDAgent{Type: "Organization", Name: e.Publisher}
+	}
+
+	for _, c := range e.Creators {
+		agent := jsonLDPerson(c)
+		switch c.Role {
+		case RoleAut, "":
+			book.Authors = append(book.Authors, agent)
+		case RoleIll:
+			book.Illustrators = append(book.Illustrators, agent)
+		case RoleTrl:
+			book.Translators = append(book.Translators, agent)
+		case RoleEdt:
+			book.Editors = append(book.Editors, agent)
+		default:
+			book.Contributors = append(book.Contributors, agent)
+		}
+	}
+
+	for _, s := range e.Subjects {
+		book.About = append(book.About, jsonLDTerm{
+			Type:             "DefinedTerm",
+			Name:             s.Heading,
+			InDefinedTermSet: s.Schema,
+		})
+	}
+
+	for _, s := range e.Bookshelves {
+		book.Genres = append(book.Genres, s.Name)
+	}
+
+	for _, cover := range e.BookCovers {
+		if url := cover.AbsoluteURL(); len(url) > 0 {
+			book.Image = append(book.Image, url)
+		}
+	}
+
+	for _, f := range e.Files {
+		media := jsonLDMedia{
+			Type:         "MediaObject",
+			ContentURL:   f.URL,
+			DateModified: f.Modified,
+		}
+		if len(f.Encodings) > 0 {
+			media.EncodingFormat = f.Encodings[0]
+		}
+		if f.Extent > 0 {
+			media.ContentSize = strconv.Itoa(f.Extent)
+		}
+		book.Encodings = append(book.Encodings, media)
+	}
+
+	if e.Downloads > 0 {
+		book.Interactions = &jsonLDInteraction{
+			Type:                 "InteractionCounter",
+			InteractionType:      "https://schema.org/DownloadAction",
+			UserInteractionCount: e.Downloads,
+		}
+	}
+
+	return book
+}
+
+func jsonLDPerson(c Creator) jsonLDAgent {
+	agent := jsonLDAgent{
+		Type:           "Person",
+		Name:           c.Name,
+		AlternateNames: c.Aliases,
+		SameAs:         c.WebPages,
+	}
+	if c.ID > 0 {
+		agent.ID = fmt.Sprintf("%s2009/agents/%d", gutenbergBaseURL, c.ID)
+	}
+	if c.Born != 0 {
+		agent.BirthDate = strconv.Itoa(c.Born)
+	}
+	if c.Died != 0 {
+		agent.DeathDate = strconv.Itoa(c.Died)
+	}
+	return agent
+}
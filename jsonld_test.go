@@ -0,0 +1,77 @@
+package pgrdf_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+)
+
+func TestEbook_WriteJSONLD(t *testing.T) {
+	ebook := generateEbook()
+	ebook.AddCreator(pgrdf.Creator{ID: 8, Name: "Tenniel, John", Role: pgrdf.RoleIll})
+	ebook.AddCreator(pgrdf.Creator{ID: 9, Name: "Smith, Jane", Role: pgrdf.RoleTrl})
+	ebook.AddCreator(pgrdf.Creator{ID: 10, Name: "Doe, John", Role: pgrdf.RoleCom})
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteJSONLD(w); err != nil {
+		t.Fatalf("error writing JSON-LD: %s", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(w.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON-LD output: %s", err)
+	}
+
+	if doc["@context"] != "https://schema.org" {
+		t.Errorf("unexpected @context, got '%v'", doc["@context"])
+	}
+	if doc["@type"] != "Book" {
+		t.Errorf("unexpected @type, got '%v'", doc["@type"])
+	}
+	if doc["url"] != "https://www.gutenberg.org/ebooks/11" {
+		t.Errorf("unexpected url, got '%v'", doc["url"])
+	}
+	if doc["name"] != "Alice's Adventures in Wonderland" {
+		t.Errorf("unexpected name, got '%v'", doc["name"])
+	}
+	if doc["datePublished"] != "2008-06-27" {
+		t.Errorf("unexpected datePublished, got '%v'", doc["datePublished"])
+	}
+
+	roles := []struct {
+		term string
+		name string
+	}{
+		{"author", "Carroll, Lewis"},
+		{"illustrator", "Tenniel, John"},
+		{"translator", "Smith, Jane"},
+		{"contributor", "Doe, John"},
+	}
+	for _, r := range roles {
+		agents, ok := doc[r.term].([]any)
+		if !ok || len(agents) != 1 {
+			t.Errorf("expected 1 %s, got %v", r.term, doc[r.term])
+			continue
+		}
+		if name := agents[0].(map[string]any)["name"]; name != r.name {
+			t.Errorf("unexpected %s name, got '%v'", r.term, name)
+		}
+	}
+
+	encodings, ok := doc["encoding"].([]any)
+	if !ok || len(encodings) != 1 {
+		t.Fatalf("expected 1 encoding, got %v", doc["encoding"])
+	}
+	media := encodings[0].(map[string]any)
+	if media["@type"] != "MediaObject" {
+		t.Errorf("unexpected encoding @type, got '%v'", media["@type"])
+	}
+	if media["encodingFormat"] != "text/plain; charset=utf-8" {
+		t.Errorf("unexpected encodingFormat, got '%v'", media["encodingFormat"])
+	}
+	if media["contentSize"] != "174693" {
+		t.Errorf("unexpected contentSize, got '%v'", media["contentSize"])
+	}
+}
@@ -0,0 +1,121 @@
+package pgrdf
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// jsonSchemaID is the published location of the current JSON Schema document.
+const jsonSchemaID = "https://raw.githubusercontent.com/mrcook/pgrdf/master/schema/pgrdf-v1.schema.json"
+
+// jsonSchemaEnums lists the known values for custom string types.
+var jsonSchemaEnums = map[reflect.Type][]string{
+	reflect.TypeOf(BookType("")): {
+		string(BookTypeUnknown),
+		string(BookTypeCollection),
+		string(BookTypeDataset),
+		string(BookTypeImage),
+		string(BookTypeMovingImage),
+		string(BookTypeSound),
+		string(BookTypeStillImage),
+		string(BookTypeText),
+	},
+}
+
+// JSONSchema returns the JSON Schema (draft 2020-12) document describing the
+// current version of the JSON envelope written by `Ebook.WriteJSON`.
+// The schema is generated from the struct definitions and their `json` tags.
+func JSONSchema() ([]byte, error) {
+	defs := map[string]any{}
+
+	root := jsonSchemaObject(reflect.TypeOf(Ebook{}), defs)
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = jsonSchemaID
+	root["title"] = "pgrdf Ebook"
+
+	props := root["properties"].(map[string]any)
+	props["schema"] = map[string]any{"const": JSONSchemaVersion}
+	root["required"] = append([]string{"schema"}, root["required"].([]string)...)
+	root["$defs"] = defs
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// jsonSchemaObject generates an object schema for a struct type, with any
+// nested struct types being added to the definitions.
+func jsonSchemaObject(t reflect.Type, defs map[string]any) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitEmpty := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+		props[name] = jsonSchemaType(field.Type, defs)
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func jsonSchemaType(t reflect.Type, defs map[string]any) map[string]any {
+	if enum, ok := jsonSchemaEnums[t]; ok {
+		return map[string]any{"type": "string", "enum": enum}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Slice:
+		// nil slices are marshaled to null
+		return map[string]any{
+			"type":  []string{"array", "null"},
+			"items": jsonSchemaType(t.Elem(), defs),
+		}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // prevent recursion
+			defs[t.Name()] = jsonSchemaObject(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	default:
+		return map[string]any{}
+	}
+}
+
+// jsonFieldName returns the JSON name for the struct field and whether
+// the `omitempty` option is present.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if len(tag) == 0 {
+		return field.Name, false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if len(name) == 0 {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			return name, true
+		}
+	}
+	return name, false
+}
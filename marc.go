@@ -1,5 +1,7 @@
 package pgrdf
 
+import "github.com/mrcook/pgrdf/internal/marcrel"
+
 // MarcRelator representing a MARC Relator code, e.g. `aut`, `edt`, etc.
 type MarcRelator string
 
@@ -292,3 +294,9 @@ const (
 	// but is not listed in the official MARC relators.
 	RoleUnk MarcRelator = "unk"
 )
+
+// Term returns the relator term for the code, e.g. "editor" for `edt`, or an
+// empty string when the code is unknown.
+func (m MarcRelator) Term() string {
+	return marcrel.Terms[string(m)]
+}
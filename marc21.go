@@ -0,0 +1,264 @@
+package pgrdf
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mrcook/pgrdf/internal/marc21"
+	"github.com/mrcook/pgrdf/internal/ns"
+)
+
+// marcLanguageCodes maps the ISO 639-1 codes used by Project Gutenberg to
+// the MARC language codes (ISO 639-2/B). Any three letter codes are used as-is.
+var marcLanguageCodes = map[string]string{
+	"af": "afr", "ar": "ara", "bg": "bul", "br": "bre", "ca": "cat", "cs": "cze",
+	"cy": "wel", "da": "dan", "de": "ger", "el": "gre", "en": "eng", "eo": "epo",
+	"es": "spa", "et": "est", "fa": "per", "fi": "fin", "fr": "fre", "fy": "fry",
+	"ga": "gle", "gd": "gla", "gl": "glg", "he": "heb", "hi": "hin", "hu": "hun",
+	"ia": "ina", "is": "ice", "it": "ita", "ja": "jpn", "ko": "kor", "la": "lat",
+	"lt": "lit", "nl": "dut", "no": "nor", "oc": "oci", "pl": "pol", "pt": "por",
+	"ro": "rum", "ru": "rus", "sa": "san", "sk": "slo", "sl": "slv", "sr": "srp",
+	"sv": "swe", "tl": "tgl", "tr": "tur", "uk": "ukr", "yi": "yid", "zh": "chi",
+}
+
+// marcFormSubdivisions are the LCSH form subdivisions written to a `$v` subfield.
+var marcFormSubdivisions = map[string]bool{
+	"Fiction": true, "Juvenile fiction": true, "Juvenile literature": true,
+	"Poetry": true, "Juvenile poetry": true, "Drama": true, "Biography": true,
+	"Correspondence": true, "Periodicals": true, "Early works to 1800": true,
+	"Humor": true, "Sources": true, "Dictionaries": true, "Handbooks, manuals, etc.": true,
+}
+
+// WriteMARCXML marshals the Ebook to a MARC 21 bibliographic record and writes
+// it to the provided `io.Writer` as a MARCXML document.
+func (e *Ebook) WriteMARCXML(w io.Writer) error {
+	return marc21.WriteXML(w, marcRecord(e))
+}
+
+// WriteMARC21 marshals the Ebook to a MARC 21 bibliographic record and writes
+// it to the provided `io.Writer` in the binary ISO 2709 exchange format.
+func (e *Ebook) WriteMARC21(w io.Writer) error {
+	return marc21.WriteISO2709(w, marcRecord(e))
+}
+
+// marcRecord will serialise an Ebook to a MARC 21 record.
+//
+// The `marc*` RDF tags are written to their matching MARC fields, authors to
+// a 100 (first) or 700 field, and all other creators to 700 fields, each with
+// the relator term (`$e`) and code (`$4`) from the creator role.
+func marcRecord(e *Ebook) *marc21.Record {
+	sf := marc21.NewSubfield
+	r := &marc21.Record{Leader: marcLeader(e)}
+
+	r.AddControlField("001", strconv.Itoa(e.ID))
+	r.AddControlField("007", "cr")
+	r.AddControlField("008", marcFixedData(e))
+
+	r.AddDataField("010", ' ', ' ', sf('a', e.LCCN))
+	r.AddDataField("020", ' ', ' ', sf('a', e.ISBN))
+
+	if len(e.Languages) > 1 {
+		var subs []marc21.Subfield
+		for _, lang := range e.Languages {
+			subs = append(subs, sf('a', marcLanguage(lang)))
+		}
+		r.AddDataField("041", '0', ' ', subs...)
+	}
+
+	for _, s := range e.Subjects {
+		if s.Schema == ns.DcTerms+"LCC" {
+			r.AddDataField("050", ' ', '4', sf('a', s.Heading))
+		}
+	}
+
+	var mainEntry bool
+	var addedEntries []Creator
+	for _, c := range e.Creators {
+		if !mainEntry && (c.Role == RoleAut || len(c.Role) == 0) && len(c.Name) > 0 {
+			r.AddDataField("100", marcNameIndicator(c.Name), ' ', marcNameSubfields(c)...)
+			mainEntry = true
+			continue
+		}
+		addedEntries = append(addedEntries, c)
+	}
+
+	if len(e.Titles) > 0 {
+		ind1 := byte('0')
+		if mainEntry {
+			ind1 = '1'
+		}
+		title := sf('a', e.Titles[0])
+		subtitle := sf('b', strings.Join(e.Titles[1:], " ; "))
+		r.AddDataField("245", ind1, marcNonFilingChars(e.Titles[0], e.Languages), title, subtitle)
+	}
+	for _, title := range e.AlternateTitles {
+		r.AddDataField("246", '3', ' ', sf('a', title))
+	}
+
+	r.AddDataField("250", ' ', ' ', sf('a', e.EditionNote))
+	r.AddDataField("260", ' ', ' ', sf('a', e.PublicationNote))
+	r.AddDataField("264", ' ', '1', sf('b', e.Publisher), sf('c', marcYear(e.ReleaseDate)))
+	r.AddDataField("300", ' ', ' ', sf('a', e.PhysicalDescriptionNote))
+	for _, series := range e.Series {
+		r.AddDataField("490", '0', ' ', sf('a', series))
+	}
+
+	for _, note := range e.Notes {
+		r.AddDataField("500", ' ', ' ', sf('a', note))
+	}
+	r.AddDataField("505", '0', ' ', sf('a', e.TableOfContents))
+	for _, note := range e.ProductionNotes {
+		r.AddDataField("508", ' ', ' ', sf('a', note))
+	}
+	r.AddDataField("520", ' ', ' ', sf('a', e.Summary))
+	r.AddDataField("540", ' ', ' ', sf('a', e.Copyright))
+	for _, note := range e.LanguageNotes {
+		r.AddDataField("546", ' ', ' ', sf('a', note))
+	}
+
+	for _, s := range e.Subjects {
+		if s.Schema == ns.DcTerms+"LCSH" {
+			r.AddDataField("650", ' ', '0', marcSubjectSubfields(s.Heading)...)
+		}
+	}
+
+	for _, c := range addedEntries {
+		r.AddDataField("700", marcNameIndicator(c.Name), ' ', marcNameSubfields(c)...)
+	}
+
+	r.AddDataField("856", '4', '0', sf('u', e.URL()), sf('z', "Free eBook from Project Gutenberg"))
+
+	return r
+}
+
+// marcLeader builds the leader for a Unicode (UTF-8) monograph record.
+// The record length and base address are calculated when writing ISO 2709.
+func marcLeader(e *Ebook) string {
+	recordType := byte('a') // language material
+	switch e.BookType {
+	case BookTypeSound:
+		recordType = 'i'
+	case BookTypeImage, BookTypeStillImage:
+		recordType = 'k'
+	case BookTypeMovingImage:
+		recordType = 'g'
+	case BookTypeDataset:
+		recordType = 'm'
+	case BookTypeCollection:
+		recordType = 'p'
+	}
+	return fmt.Sprintf("00000n%cm a2200000 i 4500", recordType)
+}
+
+// marcFixedData builds the 40 character 008 field for an online resource.
+func marcFixedData(e *Ebook) string {
+	entered := "      "
+	if len(e.ReleaseDate) >= 10 {
+		entered = strings.ReplaceAll(e.ReleaseDate[2:10], "-", "")
+	}
+
+	date1 := marcYear(e.ReleaseDate)
+	if len(date1) != 4 {
+		date1 = "uuuu"
+	}
+
+	lang := "und"
+	if len(e.Languages) > 0 {
+		lang = marcLanguage(e.Languages[0])
+	}
+
+	// 18-34 are the material specific elements, with position 23 (5 here) being
+	// the form of item: `o` for online.
+	return entered + "s" + date1 + "    " + "xx " + "     o           " + lang + " d"
+}
+
+// marcLanguage converts a PG language code to a MARC language code.
+func marcLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if code, ok := marcLanguageCodes[lang]; ok {
+		return code
+	}
+	if len(lang) == 3 {
+		return lang
+	}
+	return "und"
+}
+
+func marcYear(date string) string {
+	if len(date) < 4 {
+		return ""
+	}
+	return date[:4]
+}
+
+// marcNameIndicator returns `1` for names in surname form, e.g. "Dickens, Charles".
+func marcNameIndicator(name string) byte {
+	if strings.Contains(name, ",") {
+		return '1'
+	}
+	return '0'
+}
+
+func marcNameSubfields(c Creator) []marc21.Subfield {
+	sf := marc21.NewSubfield
+
+	role := c.Role
+	if len(role) == 0 {
+		role = RoleAut
+	}
+
+	var dates string
+	if c.Born != 0 || c.Died != 0 {
+		dates = marcNameYear(c.Born) + "-" + marcNameYear(c.Died)
+	}
+
+	subs := []marc21.Subfield{sf('a', c.Name), sf('d', dates), sf('e', role.Term()), sf('4', string(role))}
+	if c.ID > 0 {
+		subs = append(subs, sf('0', fmt.Sprintf("%s2009/agents/%d", gutenbergBaseURL, c.ID)))
+	}
+	return subs
+}
+
+func marcNameYear(year int) string {
+	if year == 0 {
+		return ""
+	}
+	if year < 0 {
+		return strconv.Itoa(-year) + " B.C."
+	}
+	return strconv.Itoa(year)
+}
+
+// marcSubjectSubfields splits an LCSH heading, e.g. "Orphans -- Fiction", into
+// the topical term and its subdivisions.
+func marcSubjectSubfields(heading string) []marc21.Subfield {
+	parts := strings.Split(heading, " -- ")
+	subs := []marc21.Subfield{marc21.NewSubfield('a', parts[0])}
+	for _, part := range parts[1:] {
+		code := byte('x') // general subdivision
+		switch {
+		case marcFormSubdivisions[part]:
+			code = 'v'
+		case len(part) > 0 && part[0] >= '0' && part[0] <= '9', strings.Contains(part, "century"):
+			code = 'y'
+		}
+		subs = append(subs, marc21.NewSubfield(code, part))
+	}
+	return subs
+}
+
+// marcNonFilingChars returns the number of characters (as the 245 second
+// indicator) of a leading English article to be ignored when sorting.
+func marcNonFilingChars(title string, languages []string) byte {
+	if len(languages) > 0 && languages[0] != "en" {
+		return '0'
+	}
+	for _, article := range []string{"The ", "An ", "A "} {
+		if strings.HasPrefix(title, article) {
+			return byte('0' + len(article))
+		}
+	}
+	return '0'
+}
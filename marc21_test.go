@@ -0,0 +1,223 @@
+package pgrdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+)
+
+func TestEbook_WriteMARCXML(t *testing.T) {
+	ebook := generateEbook()
+	ebook.AddCreator(pgrdf.Creator{ID: 8, Name: "Tenniel, John", Born: 1820, Died: 1914, Role: pgrdf.RoleIll})
+	ebook.AddSubject("PR", "http://purl.org/dc/terms/LCC")
+	ebook.AddSubject("Alice (Fictitious character from Carroll) -- Juvenile fiction", "http://purl.org/dc/terms/LCSH")
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteMARCXML(w); err != nil {
+		t.Fatalf("error writing MARCXML: %s", err)
+	}
+	data := w.String()
+
+	expected := []string{
+		`<leader>00000nam a2200000 i 4500</leader>`,
+		`<controlfield tag="001">11</controlfield>`,
+		`<controlfield tag="008">080627s2008    xx      o           eng d</controlfield>`,
+		`<datafield tag="010" ind1=" " ind2=" ">` + "\n" + `      <subfield code="a">77177892</subfield>`,
+		`<datafield tag="050" ind1=" " ind2="4">` + "\n" + `      <subfield code="a">PR</subfield>`,
+		`<datafield tag="100" ind1="1" ind2=" ">` + "\n" + `      <subfield code="a">Carroll, Lewis</subfield>` + "\n" +
+			`      <subfield code="d">1832-1898</subfield>` + "\n" +
+			`      <subfield code="e">author</subfield>` + "\n" +
+			`      <subfield code="4">aut</subfield>`,
+		`<datafield tag="245" ind1="1" ind2="0">` + "\n" + `      <subfield code="a">Alice&#39;s Adventures in Wonderland</subfield>`,
+		`<datafield tag="246" ind1="3" ind2=" ">` + "\n" + `      <subfield code="a">Alice in Wonderland</subfield>`,
+		`<datafield tag="250" ind1=" " ind2=" ">` + "\n" + `      <subfield code="a">2nd Edition</subfield>`,
+		`<datafield tag="520" ind1=" " ind2=" ">` + "\n" + `      <subfield code="a">A short story about short summaries.</subfield>`,
+		`<datafield tag="650" ind1=" " ind2="0">` + "\n" +
+			`      <subfield code="a">Alice (Fictitious character from Carroll)</subfield>` + "\n" +
+			`      <subfield code="v">Juvenile fiction</subfield>`,
+		`<datafield tag="700" ind1="1" ind2=" ">` + "\n" + `      <subfield code="a">Tenniel, John</subfield>` + "\n" +
+			`      <subfield code="d">1820-1914</subfield>` + "\n" +
+			`      <subfield code="e">illustrator</subfield>` + "\n" +
+			`      <subfield code="4">ill</subfield>`,
+		`<subfield code="u">https://www.gutenberg.org/ebooks/11</subfield>`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(data, s) {
+			t.Errorf("expected MARCXML to contain:\n%s", s)
+		}
+	}
+}
+
+func TestEbook_WriteMARC21(t *testing.T) {
+	ebook := generateEbook()
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteMARC21(w); err != nil {
+		t.Fatalf("error writing MARC 21: %s", err)
+	}
+	data := w.Bytes()
+
+	if string(data[5:10]) != "nam a" {
+		t.Errorf("unexpected leader, got '%s'", data[0:24])
+	}
+	if !bytes.Contains(data, []byte("\x1faCarroll, Lewis\x1fd1832-1898\x1feauthor\x1f4aut")) {
+		t.Error("expected the 100 field to be present")
+	}
+}
+
+func TestReadMARCXML(t *testing.T) {
+	ebooks, err := pgrdf.ReadMARCXML(strings.NewReader(marcCataloguerRecord))
+	if err != nil {
+		t.Fatalf("error reading MARCXML: %s", err)
+	}
+	if len(ebooks) != 1 {
+		t.Fatalf("expected 1 ebook, got %d", len(ebooks))
+	}
+	ebook := ebooks[0]
+
+	if ebook.ID != 1400 {
+		t.Errorf("unexpected ID, got %d", ebook.ID)
+	}
+	if ebook.BookType != pgrdf.BookTypeText {
+		t.Errorf("unexpected book type, got '%s'", ebook.BookType)
+	}
+	if len(ebook.Titles) != 2 || ebook.Titles[0] != "Great expectations" || ebook.Titles[1] != "a novel" {
+		t.Errorf("unexpected titles, got %q", ebook.Titles)
+	}
+	if ebook.ReleaseDate != "1998-07-01" {
+		t.Errorf("unexpected release date, got '%s'", ebook.ReleaseDate)
+	}
+	if len(ebook.Languages) != 1 || ebook.Languages[0] != "en" {
+		t.Errorf("unexpected languages, got %q", ebook.Languages)
+	}
+	if len(ebook.Series) != 1 || ebook.Series[0] != "Dickens library ; v. 3" {
+		t.Errorf("unexpected series, got %q", ebook.Series)
+	}
+
+	expected := []pgrdf.Creator{
+		{ID: 37, Name: "Dickens, Charles", Born: 1812, Died: 1870, Role: pgrdf.RoleAut},
+		{Name: "Stone, Marcus", Born: 1840, Died: 1921, Role: pgrdf.RoleIll},
+		{Name: "Pailthorpe, F. W.", Role: pgrdf.RoleEdt},
+		{Name: "Pailthorpe, F. W.", Role: pgrdf.RoleCom},
+	}
+	if len(ebook.Creators) != len(expected) {
+		t.Fatalf("expected %d creators, got %d", len(expected), len(ebook.Creators))
+	}
+	for i, c := range expected {
+		got := ebook.Creators[i]
+		if got.ID != c.ID || got.Name != c.Name || got.Born != c.Born || got.Died != c.Died || got.Role != c.Role {
+			t.Errorf("unexpected creator #%d, got %+v", i, got)
+		}
+	}
+
+	subjects := []string{"PR", "Orphans -- Fiction", "London (England) -- History -- 19th century -- Fiction"}
+	if len(ebook.Subjects) != len(subjects) {
+		t.Fatalf("expected %d subjects, got %d", len(subjects), len(ebook.Subjects))
+	}
+	for i, heading := range subjects {
+		if ebook.Subjects[i].Heading != heading {
+			t.Errorf("unexpected subject heading, got '%s'", ebook.Subjects[i].Heading)
+		}
+	}
+}
+
+func TestReadMARC21_RoundTrip(t *testing.T) {
+	ebook := generateEbook()
+	ebook.Languages = []string{"en", "fr"}
+	ebook.AddCreator(pgrdf.Creator{ID: 8, Name: "Tenniel, John", Born: 1820, Died: 1914, Role: pgrdf.RoleIll})
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteMARC21(w); err != nil {
+		t.Fatalf("error writing MARC 21: %s", err)
+	}
+	ebooks, err := pgrdf.ReadMARC21(w)
+	if err != nil {
+		t.Fatalf("error reading MARC 21: %s", err)
+	}
+	if len(ebooks) != 1 {
+		t.Fatalf("expected 1 ebook, got %d", len(ebooks))
+	}
+	got := ebooks[0]
+
+	if got.ID != ebook.ID || got.ReleaseDate != ebook.ReleaseDate || got.BookType != ebook.BookType {
+		t.Errorf("unexpected ID/date/type, got %d, '%s', '%s'", got.ID, got.ReleaseDate, got.BookType)
+	}
+	if got.Titles[0] != ebook.Titles[0] || got.AlternateTitles[0] != ebook.AlternateTitles[0] {
+		t.Errorf("unexpected titles, got %q %q", got.Titles, got.AlternateTitles)
+	}
+	if strings.Join(got.Languages, ",") != "en,fr" {
+		t.Errorf("unexpected languages, got %q", got.Languages)
+	}
+	if got.PublicationNote != ebook.PublicationNote || got.TableOfContents != ebook.TableOfContents {
+		t.Errorf("unexpected notes, got '%s' and '%s'", got.PublicationNote, got.TableOfContents)
+	}
+	if got.LCCN != ebook.LCCN || got.ISBN != ebook.ISBN || got.Publisher != ebook.Publisher {
+		t.Errorf("unexpected LCCN/ISBN/publisher, got '%s', '%s', '%s'", got.LCCN, got.ISBN, got.Publisher)
+	}
+	if len(got.Creators) != 2 {
+		t.Fatalf("expected 2 creators, got %d", len(got.Creators))
+	}
+	if got.Creators[1].ID != 8 || got.Creators[1].Role != pgrdf.RoleIll || got.Creators[1].Born != 1820 {
+		t.Errorf("unexpected illustrator, got %+v", got.Creators[1])
+	}
+	if len(got.Subjects) != 1 || got.Subjects[0].Heading != "Fantasy fiction" {
+		t.Errorf("unexpected subjects, got %+v", got.Subjects)
+	}
+}
+
+// a record as supplied by a cataloguer, with ISBD punctuation and relator terms.
+const marcCataloguerRecord = `<?xml version="1.0" encoding="UTF-8"?>
+<collection xmlns="http://www.loc.gov/MARC21/slim">
+  <record>
+    <leader>01234nam a2200289 i 4500</leader>
+    <controlfield tag="008">980701s1998    xx      o     000 1 eng d</controlfield>
+    <datafield tag="050" ind1=" " ind2="4">
+      <subfield code="a">PR</subfield>
+    </datafield>
+    <datafield tag="100" ind1="1" ind2=" ">
+      <subfield code="a">Dickens, Charles,</subfield>
+      <subfield code="d">1812-1870,</subfield>
+      <subfield code="e">author.</subfield>
+      <subfield code="0">http://www.gutenberg.org/2009/agents/37</subfield>
+    </datafield>
+    <datafield tag="245" ind1="1" ind2="0">
+      <subfield code="a">Great expectations :</subfield>
+      <subfield code="b">a novel /</subfield>
+      <subfield code="c">by Charles Dickens.</subfield>
+    </datafield>
+    <datafield tag="490" ind1="1" ind2=" ">
+      <subfield code="a">Dickens library ;</subfield>
+      <subfield code="v">v. 3</subfield>
+    </datafield>
+    <datafield tag="650" ind1=" " ind2="0">
+      <subfield code="a">Orphans</subfield>
+      <subfield code="v">Fiction.</subfield>
+    </datafield>
+    <datafield tag="650" ind1=" " ind2="7">
+      <subfield code="a">Orphans.</subfield>
+      <subfield code="2">fast</subfield>
+    </datafield>
+    <datafield tag="651" ind1=" " ind2="0">
+      <subfield code="a">London (England)</subfield>
+      <subfield code="x">History</subfield>
+      <subfield code="y">19th century</subfield>
+      <subfield code="v">Fiction.</subfield>
+    </datafield>
+    <datafield tag="700" ind1="1" ind2=" ">
+      <subfield code="a">Stone, Marcus,</subfield>
+      <subfield code="d">1840-1921,</subfield>
+      <subfield code="e">illustrator.</subfield>
+    </datafield>
+    <datafield tag="700" ind1="1" ind2=" ">
+      <subfield code="a">Pailthorpe, F. W.</subfield>
+      <subfield code="4">http://id.loc.gov/vocabulary/relators/edt</subfield>
+      <subfield code="4">com</subfield>
+    </datafield>
+    <datafield tag="856" ind1="4" ind2="0">
+      <subfield code="u">http://www.gutenberg.org/ebooks/1400</subfield>
+    </datafield>
+  </record>
+</collection>
+`
@@ -0,0 +1,323 @@
+package pgrdf
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mrcook/pgrdf/internal/marc21"
+	"github.com/mrcook/pgrdf/internal/marcrel"
+	"github.com/mrcook/pgrdf/internal/ns"
+)
+
+// ReadMARCXML document from the given `io.Reader` and unmarshal each MARC 21
+// bibliographic record to an Ebook.
+func ReadMARCXML(r io.Reader) ([]*Ebook, error) {
+	records, err := marc21.ReadXML(r)
+	if err != nil {
+		return nil, err
+	}
+	return marcUnmarshal(records), nil
+}
+
+// ReadMARC21 reads binary ISO 2709 records from the given `io.Reader` and
+// unmarshals each MARC 21 bibliographic record to an Ebook.
+func ReadMARC21(r io.Reader) ([]*Ebook, error) {
+	records, err := marc21.ReadISO2709(r)
+	if err != nil {
+		return nil, err
+	}
+	return marcUnmarshal(records), nil
+}
+
+func marcUnmarshal(records []*marc21.Record) []*Ebook {
+	var ebooks []*Ebook
+	for _, r := range records {
+		ebooks = append(ebooks, ebookFromMARC(r))
+	}
+	return ebooks
+}
+
+var (
+	// marcEbookURLRE matches a gutenberg.org ebook URL, e.g. in an 856 field.
+	marcEbookURLRE = regexp.MustCompile(`gutenberg\.org/ebooks/(\d+)`)
+
+	// marcAgentURIRE matches a gutenberg.org agent URI, e.g. in a `$0` subfield.
+	marcAgentURIRE = regexp.MustCompile(`gutenberg\.org/2009/agents/(\d+)`)
+
+	// marcDatesRE matches the dates of a name, e.g. "1812-1870", "1812-", "384-322 B.C."
+	marcDatesRE = regexp.MustCompile(`^(\d+)?\??\s*(B\.C\.)?-(\d+)?\??\s*(B\.C\.)?`)
+
+	// marcFinalWordRE matches the final word of a value ending with a full stop.
+	marcFinalWordRE = regexp.MustCompile(`(?:^|[\s(])([^\s(]+)\.$`)
+)
+
+// ebookFromMARC will deserialise a MARC 21 record to an Ebook, which is the
+// reverse of marcRecord(). Creator roles are taken from the `$4` relator
+// codes, falling back to the `$e` relator terms.
+func ebookFromMARC(r *marc21.Record) *Ebook {
+	e := &Ebook{}
+
+	e.ID, _ = strconv.Atoi(strings.TrimSpace(r.ControlField("001")))
+	if e.ID == 0 {
+		for _, f := range r.Fields("856") {
+			if m := marcEbookURLRE.FindStringSubmatch(f.Subfield('u')); m != nil {
+				e.ID, _ = strconv.Atoi(m[1])
+				break
+			}
+		}
+	}
+
+	if len(r.Leader) == 24 {
+		e.BookType = marcBookType(r.Leader[6])
+	}
+
+	fixed := r.ControlField("008")
+	e.ReleaseDate = marcReleaseDate(fixed)
+
+	for _, f := range r.Fields("041") {
+		for _, code := range f.SubfieldValues('a') {
+			e.Languages = append(e.Languages, pgLanguage(code))
+		}
+	}
+	if len(e.Languages) == 0 && len(fixed) >= 38 {
+		if lang := strings.TrimSpace(fixed[35:38]); len(lang) > 0 && lang != "und" && lang != "|||" {
+			e.Languages = append(e.Languages, pgLanguage(lang))
+		}
+	}
+
+	e.LCCN = strings.TrimSpace(firstSubfield(r, "010", 'a'))
+	e.ISBN = strings.TrimSpace(firstSubfield(r, "020", 'a'))
+
+	for _, f := range r.Fields("245") {
+		e.Titles = append(e.Titles, marcTrimPunctuation(f.Subfield('a')))
+		for _, subtitle := range strings.Split(f.Subfield('b'), " ; ") {
+			if subtitle = marcTrimPunctuation(subtitle); len(subtitle) > 0 {
+				e.Titles = append(e.Titles, subtitle)
+			}
+		}
+	}
+	for _, f := range r.Fields("246") {
+		e.AlternateTitles = append(e.AlternateTitles, marcTrimPunctuation(f.Subfield('a')))
+	}
+
+	e.EditionNote = marcFieldText(r, "250")
+	e.PublicationNote = marcFieldText(r, "260")
+	for _, f := range r.Fields("264") {
+		if f.Ind2 == '1' {
+			e.Publisher = marcTrimPunctuation(f.Subfield('b'))
+		}
+	}
+	e.PhysicalDescriptionNote = marcFieldText(r, "300")
+
+	for _, tag := range []string{"440", "490", "830"} {
+		for _, f := range r.Fields(tag) {
+			series := marcTrimPunctuation(strings.Join(f.SubfieldValues('a'), " "))
+			if volume := f.Subfield('v'); len(volume) > 0 {
+				series += " ; " + volume
+			}
+			if len(series) > 0 && !containsString(e.Series, series) {
+				e.Series = append(e.Series, series)
+			}
+		}
+	}
+
+	for _, f := range r.Fields("500") {
+		e.Notes = append(e.Notes, f.Subfield('a'))
+	}
+	e.TableOfContents = marcFieldText(r, "505")
+	for _, f := range r.Fields("508") {
+		e.ProductionNotes = append(e.ProductionNotes, f.Subfield('a'))
+	}
+	e.Summary = firstSubfield(r, "520", 'a')
+	e.Copyright = firstSubfield(r, "540", 'a')
+	for _, f := range r.Fields("546") {
+		e.LanguageNotes = append(e.LanguageNotes, f.Subfield('a'))
+	}
+
+	for _, tag := range []string{"100", "110", "700", "710"} {
+		for _, f := range r.Fields(tag) {
+			defaultRole := RoleCtb
+			if tag[0] == '1' {
+				defaultRole = RoleAut
+			}
+			for _, c := range marcCreators(f, defaultRole) {
+				e.AddCreator(c)
+			}
+		}
+	}
+
+	for _, f := range r.Fields("050") {
+		if lcc := strings.TrimSpace(f.Subfield('a')); len(lcc) > 0 {
+			e.AddSubject(lcc, ns.DcTerms+"LCC")
+		}
+	}
+	for _, tag := range []string{"600", "610", "650", "651"} {
+		for _, f := range r.Fields(tag) {
+			if f.Ind2 != '0' {
+				continue // only LCSH headings are supported
+			}
+			var parts []string
+			for _, s := range f.Subfields {
+				if strings.IndexByte("abcdqtvxyz", s.Code) >= 0 {
+					parts = append(parts, marcTrimPunctuation(s.Value))
+				}
+			}
+			if len(parts) > 0 {
+				e.AddSubject(strings.Join(parts, " -- "), ns.DcTerms+"LCSH")
+			}
+		}
+	}
+
+	return e
+}
+
+// marcCreators returns a Creator for each relator of a 1XX/7XX name field.
+func marcCreators(f marc21.DataField, defaultRole MarcRelator) []Creator {
+	creator := Creator{Name: marcTrimPunctuation(f.Subfield('a'))}
+	if m := marcDatesRE.FindStringSubmatch(f.Subfield('d')); m != nil {
+		creator.Born = marcDateYear(m[1], m[2])
+		creator.Died = marcDateYear(m[3], m[4])
+	}
+	for _, uri := range f.SubfieldValues('0') {
+		if m := marcAgentURIRE.FindStringSubmatch(uri); m != nil {
+			creator.ID, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	var roles []MarcRelator
+	for _, code := range f.SubfieldValues('4') {
+		// a relator URI, e.g. `http://id.loc.gov/vocabulary/relators/edt`
+		code = strings.TrimRight(strings.TrimSpace(code), "/")
+		if i := strings.LastIndexByte(code, '/'); i >= 0 {
+			code = code[i+1:]
+		}
+		code = strings.ToLower(code)
+		if len(code) == 3 {
+			roles = append(roles, MarcRelator(code))
+		}
+	}
+	if len(roles) == 0 {
+		for _, term := range f.SubfieldValues('e') {
+			if role, ok := marcRelatorFromTerm(term); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+	if len(roles) == 0 {
+		roles = append(roles, defaultRole)
+	}
+
+	var creators []Creator
+	for _, role := range roles {
+		c := creator
+		c.Role = role
+		creators = append(creators, c)
+	}
+	return creators
+}
+
+// marcRelatorFromTerm looks up the relator code for a `$e` term, e.g. "editor".
+func marcRelatorFromTerm(term string) (MarcRelator, bool) {
+	code, ok := marcrel.FromTerm(marcTrimPunctuation(term))
+	return MarcRelator(code), ok
+}
+
+func marcDateYear(year, bc string) int {
+	y, _ := strconv.Atoi(year)
+	if len(bc) > 0 {
+		return -y
+	}
+	return y
+}
+
+// marcReleaseDate returns an ISO 8601 date from the 008 date entered
+// (positions 0-5, `yymmdd`), using the century from date 1 (positions 7-10).
+func marcReleaseDate(fixed string) string {
+	if len(fixed) < 11 {
+		return ""
+	}
+	entered, year := fixed[0:6], fixed[7:11]
+	if _, err := strconv.Atoi(year); err != nil {
+		return ""
+	}
+	if _, err := strconv.Atoi(entered); err != nil || entered[0:2] != year[2:4] {
+		return year
+	}
+	return year + "-" + entered[2:4] + "-" + entered[4:6]
+}
+
+func marcBookType(recordType byte) BookType {
+	switch recordType {
+	case 'a', 't':
+		return BookTypeText
+	case 'i', 'j':
+		return BookTypeSound
+	case 'k':
+		return BookTypeStillImage
+	case 'g':
+		return BookTypeMovingImage
+	case 'm':
+		return BookTypeDataset
+	case 'p':
+		return BookTypeCollection
+	default:
+		return BookTypeUnknown
+	}
+}
+
+// pgLanguage converts a MARC language code to the ISO 639-1 code used by PG,
+// when one exists.
+func pgLanguage(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	for pg, marc := range marcLanguageCodes {
+		if marc == code {
+			return pg
+		}
+	}
+	return code
+}
+
+// marcTrimPunctuation removes the trailing ISBD punctuation from a subfield,
+// e.g. "Dickens, Charles," or "Great expectations /".
+func marcTrimPunctuation(value string) string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimRight(value, " ,:;/=")
+	// keep the full stop of initials and abbreviations, e.g. "F. J." or "etc."
+	if m := marcFinalWordRE.FindStringSubmatch(value); m != nil && len(m[1]) > 3 {
+		value = strings.TrimSuffix(value, ".")
+	}
+	return strings.TrimSpace(value)
+}
+
+// marcFieldText joins all subfields of the first field with the tag.
+func marcFieldText(r *marc21.Record, tag string) string {
+	fields := r.Fields(tag)
+	if len(fields) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, s := range fields[0].Subfields {
+		parts = append(parts, strings.TrimSpace(s.Value))
+	}
+	return strings.Join(parts, " ")
+}
+
+func firstSubfield(r *marc21.Record, tag string, code byte) string {
+	for _, f := range r.Fields(tag) {
+		if v := f.Subfield(code); len(v) > 0 {
+			return v
+		}
+	}
+	return ""
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package pgrdf
+
+import (
+	"reflect"
+)
+
+// MergeStrategy is how a field value is merged by Merge.
+type MergeStrategy int
+
+const (
+	// MergeDefault uses MergeUnion for lists, and MergePreferNonEmpty for all
+	// other fields.
+	MergeDefault MergeStrategy = iota
+
+	// MergeReplace always uses the overlay value, even when it is empty.
+	MergeReplace
+
+	// MergeUnion adds the overlay list elements which are not in the base
+	// list. Matching elements are merged, with creators being matched by
+	// their agent ID and role, subjects by heading, files by URL, etc.
+	MergeUnion
+
+	// MergePreferNonEmpty uses the overlay value, unless it is empty.
+	MergePreferNonEmpty
+
+	// MergeKeepBase always uses the base value.
+	MergeKeepBase
+)
+
+// MergePolicy sets the MergeStrategy of the Ebook fields, by their JSON name,
+// e.g. "subjects". Fields not in the policy use the Default strategy.
+type MergePolicy struct {
+	Default MergeStrategy
+	Fields  map[string]MergeStrategy
+}
+
+func (p *MergePolicy) strategy(field string) MergeStrategy {
+	if p == nil {
+		return MergeDefault
+	}
+	if s, ok := p.Fields[field]; ok {
+		return s
+	}
+	return p.Default
+}
+
+// Merge returns a new Ebook combining the base ebook with the overlay, which
+// is usually a partial record of corrections. Each field is merged using the
+// strategy given by the policy, with a nil policy using MergeDefault for all
+// fields. Neither the base nor the overlay are modified.
+//
+//	policy := &pgrdf.MergePolicy{Fields: map[string]pgrdf.MergeStrategy{
+//		"subjects": pgrdf.MergeReplace,
+//	}}
+//	ebook := pgrdf.Merge(base, corrections, policy)
+func Merge(base, overlay *Ebook, policy *MergePolicy) *Ebook {
+	merged := &Ebook{}
+	mergeStruct(reflect.ValueOf(merged).Elem(), reflect.ValueOf(base).Elem(), reflect.ValueOf(overlay).Elem(), policy)
+	return merged
+}
+
+// mergeStruct merges the fields of a and b into dst. List elements are merged
+// with a nil policy.
+func mergeStruct(dst, a, b reflect.Value, policy *MergePolicy) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+		name, _ := jsonFieldName(t.Field(i))
+		dst.Field(i).Set(mergeValue(a.Field(i), b.Field(i), policy.strategy(name)))
+	}
+}
+
+func mergeValue(a, b reflect.Value, strategy MergeStrategy) reflect.Value {
+	switch strategy {
+	case MergeKeepBase:
+		return cloneValue(a)
+	case MergeReplace:
+		return cloneValue(b)
+	case MergeUnion, MergeDefault:
+		if a.Kind() == reflect.Slice {
+			return mergeUnion(a, b)
+		}
+	}
+
+	if isEmptyValue(b) {
+		return cloneValue(a)
+	}
+	return cloneValue(b)
+}
+
+// mergeUnion returns the elements of a, followed by the elements of b not
+// found in a. Matching struct elements are merged.
+func mergeUnion(a, b reflect.Value) reflect.Value {
+	list := reflect.MakeSlice(a.Type(), 0, a.Len()+b.Len())
+	index := make(map[string]int)
+
+	for i := 0; i < a.Len(); i++ {
+		key := mergeKey(a.Index(i).Interface())
+		if _, ok := index[key]; !ok {
+			index[key] = list.Len()
+		}
+		list = reflect.Append(list, cloneValue(a.Index(i)))
+	}
+
+	for i := 0; i < b.Len(); i++ {
+		el := b.Index(i)
+		key := mergeKey(el.Interface())
+
+		j, ok := index[key]
+		if !ok {
+			index[key] = list.Len()
+			list = reflect.Append(list, cloneValue(el))
+			continue
+		}
+		if el.Kind() == reflect.Struct {
+			merged := reflect.New(el.Type()).Elem()
+			mergeStruct(merged, list.Index(j), el, nil)
+			list.Index(j).Set(merged)
+		}
+	}
+
+	return list
+}
+
+// mergeKey returns the key for matching list elements. Creators are matched
+// by both their agent ID and role, as an agent may have several roles; a
+// creator without a role is an author, as with `dcterms:creator`.
+func mergeKey(v interface{}) string {
+	if c, ok := v.(Creator); ok {
+		role := c.Role
+		if len(role) == 0 {
+			role = RoleAut
+		}
+		return creatorAgentKey(c) + ":" + string(role)
+	}
+	return elementKey(v)
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Slice {
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// cloneValue returns a copy of the value, with new slices, so the merged
+// ebook does not share any lists with the base or overlay.
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		list := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			list.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return list
+	case reflect.Struct:
+		clone := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				clone.Field(i).Set(cloneValue(v.Field(i)))
+			}
+		}
+		return clone
+	default:
+		return v
+	}
+}
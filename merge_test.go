@@ -0,0 +1,107 @@
+package pgrdf_test
+
+import (
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+)
+
+func sampleOverlay() *pgrdf.Ebook {
+	overlay := &pgrdf.Ebook{
+		ID:      999991234,
+		Titles:  []string{"Great Expectations"},
+		Summary: "A corrected summary.",
+		Creators: []pgrdf.Creator{
+			{ID: 37, Name: "Dickens, Charles", Aliases: []string{"Boz", "Charles Dickens"}, Role: pgrdf.RoleAut},
+			{ID: 37, Name: "Dickens, Charles", Role: pgrdf.RoleEdt},
+		},
+	}
+	overlay.AddSubject("Orphans -- Fiction", "http://purl.org/dc/terms/LCSH")
+	overlay.AddSubject("Pirates -- Fiction", "http://purl.org/dc/terms/LCSH")
+	overlay.AddBookshelf("Classics", "2009/pgterms/Bookshelf")
+	return overlay
+}
+
+func TestMerge(t *testing.T) {
+	base := getEbookFromSampleRdf(t)
+	merged := pgrdf.Merge(base, sampleOverlay(), nil)
+
+	if len(merged.Titles) != 2 || merged.Titles[0] != "Great Expectations" {
+		t.Errorf("unexpected titles, got %q", merged.Titles)
+	}
+	if merged.Summary != "A corrected summary." {
+		t.Errorf("unexpected summary, got '%s'", merged.Summary)
+	}
+	if merged.Publisher != base.Publisher || merged.ReleaseDate != base.ReleaseDate {
+		t.Errorf("expected empty overlay fields to keep the base values")
+	}
+	if len(merged.Subjects) != len(base.Subjects)+1 || merged.Subjects[len(merged.Subjects)-1].Heading != "Pirates -- Fiction" {
+		t.Errorf("expected one subject to be added, got %d", len(merged.Subjects))
+	}
+	if len(merged.Bookshelves) != 2 {
+		t.Errorf("expected 2 bookshelves, got %d", len(merged.Bookshelves))
+	}
+
+	if len(merged.Creators) != len(base.Creators)+1 {
+		t.Fatalf("expected one creator to be added, got %d", len(merged.Creators))
+	}
+	dickens := merged.Creators[0]
+	if dickens.Born != 1812 || len(dickens.Aliases) != 3 || dickens.Aliases[2] != "Charles Dickens" {
+		t.Errorf("unexpected merged creator, got %+v", dickens)
+	}
+	if added := merged.Creators[len(merged.Creators)-1]; added.ID != 37 || added.Role != pgrdf.RoleEdt {
+		t.Errorf("expected the editor role to be added, got %+v", added)
+	}
+
+	// the base must not be modified
+	if len(base.Creators[0].Aliases) != 2 || base.Summary == merged.Summary {
+		t.Errorf("expected the base ebook to be unchanged")
+	}
+	merged.Files[0].Encodings[0] = "changed"
+	if base.Files[0].Encodings[0] == "changed" {
+		t.Errorf("expected the merged ebook not to share lists with the base")
+	}
+}
+
+func TestMerge_CreatorWithoutRole(t *testing.T) {
+	base := &pgrdf.Ebook{Creators: []pgrdf.Creator{{ID: 37, Name: "Dickens, Charles", Role: pgrdf.RoleAut}}}
+	overlay := &pgrdf.Ebook{Creators: []pgrdf.Creator{{ID: 37, Born: 1812, Died: 1870}}}
+
+	merged := pgrdf.Merge(base, overlay, nil)
+	if len(merged.Creators) != 1 {
+		t.Fatalf("expected the creators to be merged, got %d", len(merged.Creators))
+	}
+	c := merged.Creators[0]
+	if c.Name != "Dickens, Charles" || c.Role != pgrdf.RoleAut || c.Born != 1812 || c.Died != 1870 {
+		t.Errorf("unexpected merged creator, got %+v", c)
+	}
+}
+
+func TestMerge_Policy(t *testing.T) {
+	base := getEbookFromSampleRdf(t)
+	policy := &pgrdf.MergePolicy{Fields: map[string]pgrdf.MergeStrategy{
+		"subjects":  pgrdf.MergeReplace,
+		"summary":   pgrdf.MergeKeepBase,
+		"publisher": pgrdf.MergeReplace,
+		"titles":    pgrdf.MergePreferNonEmpty,
+	}}
+	merged := pgrdf.Merge(base, sampleOverlay(), policy)
+
+	if len(merged.Subjects) != 2 {
+		t.Errorf("expected the subjects to be replaced, got %d", len(merged.Subjects))
+	}
+	if merged.Summary != base.Summary {
+		t.Errorf("expected the base summary, got '%s'", merged.Summary)
+	}
+	if len(merged.Publisher) != 0 {
+		t.Errorf("expected the publisher to be replaced with an empty value, got '%s'", merged.Publisher)
+	}
+	if len(merged.Titles) != 1 {
+		t.Errorf("expected the overlay titles, got %q", merged.Titles)
+	}
+
+	keep := pgrdf.Merge(base, sampleOverlay(), &pgrdf.MergePolicy{Default: pgrdf.MergeKeepBase})
+	if changes := pgrdf.Diff(base, keep); len(changes) != 0 {
+		t.Errorf("expected no changes when keeping the base, got %v", changes)
+	}
+}
@@ -0,0 +1,126 @@
+package pgrdf
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// OAI-PMH Dublin Core namespaces.
+const (
+	nsOAIDC             = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	nsDcElements        = "http://purl.org/dc/elements/1.1/"
+	nsXsi               = "http://www.w3.org/2001/XMLSchema-instance"
+	oaiDCSchemaLocation = "http://www.openarchives.org/OAI/2.0/oai_dc/ http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+)
+
+// oaiDC is the unqualified Dublin Core `<oai_dc:dc>` record used by OAI-PMH.
+type oaiDC struct {
+	XMLName        xml.Name `xml:"oai_dc:dc"`
+	NsOAIDC        string   `xml:"xmlns:oai_dc,attr"`
+	NsDc           string   `xml:"xmlns:dc,attr"`
+	NsXsi          string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+
+	Titles       []string `xml:"dc:title"`
+	Creators     []string `xml:"dc:creator"`
+	Contributors []string `xml:"dc:contributor"`
+	Subjects     []string `xml:"dc:subject"`
+	Descriptions []string `xml:"dc:description"`
+	Publisher    string   `xml:"dc:publisher,omitempty"`
+	Date         string   `xml:"dc:date,omitempty"`
+	Type         string   `xml:"dc:type,omitempty"`
+	Formats      []string `xml:"dc:format"`
+	Identifiers  []string `xml:"dc:identifier"`
+	Sources      []string `xml:"dc:source"`
+	Languages    []string `xml:"dc:language"`
+	Relations    []string `xml:"dc:relation"`
+	Rights       string   `xml:"dc:rights,omitempty"`
+}
+
+// WriteOAIDC marshals the Ebook to an OAI-PMH `oai_dc` (unqualified Dublin
+// Core) record and writes it to the provided `io.Writer`.
+//
+// Names are written with their dates, e.g. "Dickens, Charles, 1812-1870", and
+// non-author creators also include their role, e.g. "Tenniel, John, 1820-1914 [Illustrator]".
+func (e *Ebook) WriteOAIDC(w io.Writer) error {
+	data, err := xml.MarshalIndent(e.oaiDC(), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (e *Ebook) oaiDC() *oaiDC {
+	dc := &oaiDC{
+		NsOAIDC:        nsOAIDC,
+		NsDc:           nsDcElements,
+		NsXsi:          nsXsi,
+		SchemaLocation: oaiDCSchemaLocation,
+		Publisher:      e.Publisher,
+		Date:           e.ReleaseDate,
+		Type:           string(e.BookType),
+		Languages:      e.Languages,
+		Sources:        e.SourceLinks,
+		Rights:         e.Copyright,
+	}
+
+	dc.Titles = append(dc.Titles, e.Titles...)
+	dc.Titles = append(dc.Titles, e.AlternateTitles...)
+
+	for _, c := range e.Creators {
+		name := oaiDCName(c)
+		if c.Role == RoleAut || len(c.Role) == 0 {
+			dc.Creators = append(dc.Creators, name)
+			continue
+		}
+		if term := c.Role.Term(); len(term) > 0 {
+			name += " [" + strings.ToUpper(term[:1]) + term[1:] + "]"
+		}
+		dc.Contributors = append(dc.Contributors, name)
+	}
+
+	for _, s := range e.Subjects {
+		dc.Subjects = append(dc.Subjects, s.Heading)
+	}
+
+	if len(e.Summary) > 0 {
+		dc.Descriptions = append(dc.Descriptions, e.Summary)
+	}
+	dc.Descriptions = append(dc.Descriptions, e.Notes...)
+	if len(e.TableOfContents) > 0 {
+		dc.Descriptions = append(dc.Descriptions, e.TableOfContents)
+	}
+
+	for _, f := range e.Files {
+		for _, enc := range f.Encodings {
+			if !containsString(dc.Formats, enc) {
+				dc.Formats = append(dc.Formats, enc)
+			}
+		}
+	}
+
+	dc.Identifiers = append(dc.Identifiers, e.URL())
+	if len(e.ISBN) > 0 {
+		dc.Identifiers = append(dc.Identifiers, "urn:isbn:"+e.ISBN)
+	}
+	if len(e.LCCN) > 0 {
+		dc.Identifiers = append(dc.Identifiers, "info:lccn/"+e.LCCN)
+	}
+
+	dc.Relations = append(dc.Relations, e.Series...)
+
+	return dc
+}
+
+// oaiDCName returns the creator name with their dates, when known.
+func oaiDCName(c Creator) string {
+	if c.Born == 0 && c.Died == 0 {
+		return c.Name
+	}
+	return c.Name + ", " + marcNameYear(c.Born) + "-" + marcNameYear(c.Died)
+}
@@ -0,0 +1,43 @@
+package pgrdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+)
+
+func TestEbook_WriteOAIDC(t *testing.T) {
+	ebook := generateEbook()
+	ebook.AddCreator(pgrdf.Creator{ID: 8, Name: "Tenniel, John", Born: 1820, Died: 1914, Role: pgrdf.RoleIll})
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteOAIDC(w); err != nil {
+		t.Fatalf("error writing oai_dc: %s", err)
+	}
+	data := w.String()
+
+	expected := []string{
+		`<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/"`,
+		`<dc:title>Alice&#39;s Adventures in Wonderland</dc:title>`,
+		`<dc:title>Alice in Wonderland</dc:title>`,
+		`<dc:creator>Carroll, Lewis, 1832-1898</dc:creator>`,
+		`<dc:contributor>Tenniel, John, 1820-1914 [Illustrator]</dc:contributor>`,
+		`<dc:subject>Fantasy fiction</dc:subject>`,
+		`<dc:description>A short story about short summaries.</dc:description>`,
+		`<dc:date>2008-06-27</dc:date>`,
+		`<dc:type>Text</dc:type>`,
+		`<dc:format>text/plain; charset=utf-8</dc:format>`,
+		`<dc:identifier>https://www.gutenberg.org/ebooks/11</dc:identifier>`,
+		`<dc:identifier>urn:isbn:978-0-919366-14-5</dc:identifier>`,
+		`<dc:language>en</dc:language>`,
+		`<dc:relation>Best of Fantasy</dc:relation>`,
+		`<dc:rights>Public domain in the USA.</dc:rights>`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(data, s) {
+			t.Errorf("expected oai_dc to contain:\n%s", s)
+		}
+	}
+}
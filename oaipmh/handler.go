@@ -0,0 +1,440 @@
+package oaipmh
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrcook/pgrdf"
+)
+
+// Handler default values.
+const (
+	DefaultIdentifierPrefix  = "oai:gutenberg.org:"
+	DefaultEarliestDatestamp = "1971-12-01" // release of the first PG eText
+	DefaultPageSize          = 100
+	DefaultReadLimit         = 1000
+)
+
+// dateRE matches a datestamp with day granularity.
+var dateRE = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// verbArguments lists the allowed arguments for each verb, with the value
+// being whether the argument is required. The resumptionToken is exclusive.
+var verbArguments = map[string]map[string]bool{
+	"Identify":            {},
+	"ListMetadataFormats": {"identifier": false},
+	"ListSets":            {"resumptionToken": false},
+	"ListIdentifiers":     {"metadataPrefix": true, "from": false, "until": false, "set": false, "resumptionToken": false},
+	"ListRecords":         {"metadataPrefix": true, "from": false, "until": false, "set": false, "resumptionToken": false},
+	"GetRecord":           {"identifier": true, "metadataPrefix": true},
+}
+
+// Handler is an `http.Handler` implementing an OAI-PMH data provider for the
+// ebooks of the Source. Records are identified by the IdentifierPrefix and
+// the eText ID, e.g. "oai:gutenberg.org:1400".
+type Handler struct {
+	Source Source
+
+	RepositoryName    string
+	BaseURL           string // the URL the handler is served from
+	AdminEmail        string
+	IdentifierPrefix  string
+	EarliestDatestamp string
+	PageSize          int // number of records for each list response
+
+	// ReadLimit is the maximum number of ebooks read from the Source for each
+	// list response. When a from/until range matches few ebooks, a response
+	// may have fewer records than the PageSize, or none, along with a
+	// resumption token for the next ebooks. A DateSource avoids this, as only
+	// the ebooks within the range are read.
+	ReadLimit int
+}
+
+// NewHandler creates a Handler for the source with the default settings.
+func NewHandler(source Source, repositoryName, baseURL, adminEmail string) *Handler {
+	return &Handler{
+		Source:            source,
+		RepositoryName:    repositoryName,
+		BaseURL:           baseURL,
+		AdminEmail:        adminEmail,
+		IdentifierPrefix:  DefaultIdentifierPrefix,
+		EarliestDatestamp: DefaultEarliestDatestamp,
+		PageSize:          DefaultPageSize,
+		ReadLimit:         DefaultReadLimit,
+	}
+}
+
+// listArgs are the arguments of a list request, which are also encoded in
+// the resumption token along with the position to resume from.
+type listArgs struct {
+	from   string
+	until  string
+	offset int // index into the listed IDs, see Handler.ids
+	cursor int // number of records already returned
+}
+
+// ServeHTTP handles an OAI-PMH GET or POST request.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := &response{
+		Xmlns:          NamespaceOAI,
+		XmlnsXsi:       namespaceXsi,
+		SchemaLocation: NamespaceOAI + " " + schemaOAI,
+		ResponseDate:   time.Now().UTC().Format(time.RFC3339),
+		Request:        request{URL: h.BaseURL},
+	}
+	h.handle(resp, r.Form)
+	if resp.err != nil {
+		http.Error(w, resp.err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := xml.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(data)
+}
+
+func (h *Handler) handle(resp *response, args url.Values) {
+	verb := args.Get("verb")
+	allowed, ok := verbArguments[verb]
+	if !ok || len(args["verb"]) > 1 {
+		resp.error(ErrBadVerb, "illegal OAI verb")
+		return
+	}
+
+	for name, values := range args {
+		if name == "verb" {
+			continue
+		}
+		if _, ok := allowed[name]; !ok {
+			resp.error(ErrBadArgument, fmt.Sprintf("illegal argument: %s", name))
+		} else if len(values) > 1 {
+			resp.error(ErrBadArgument, fmt.Sprintf("repeated argument: %s", name))
+		}
+	}
+	if len(args.Get("resumptionToken")) > 0 {
+		if len(args) > 2 {
+			resp.error(ErrBadArgument, "resumptionToken is an exclusive argument")
+		}
+	} else {
+		for name, required := range allowed {
+			if required && len(args.Get(name)) == 0 {
+				resp.error(ErrBadArgument, fmt.Sprintf("missing argument: %s", name))
+			}
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return
+	}
+
+	// only add the request attributes for valid requests
+	resp.Request.Verb = verb
+	resp.Request.Identifier = args.Get("identifier")
+	resp.Request.MetadataPrefix = args.Get("metadataPrefix")
+	resp.Request.From = args.Get("from")
+	resp.Request.Until = args.Get("until")
+	resp.Request.ResumptionToken = args.Get("resumptionToken")
+
+	switch verb {
+	case "Identify":
+		h.identify(resp)
+	case "ListMetadataFormats":
+		h.listMetadataFormats(resp, args.Get("identifier"))
+	case "ListSets":
+		resp.error(ErrNoSetHierarchy, "sets are not supported")
+	case "ListIdentifiers", "ListRecords":
+		h.list(resp, verb, args)
+	case "GetRecord":
+		h.getRecord(resp, args.Get("identifier"), args.Get("metadataPrefix"))
+	}
+}
+
+func (h *Handler) identify(resp *response) {
+	resp.Identify = &identify{
+		RepositoryName:    h.RepositoryName,
+		BaseURL:           h.BaseURL,
+		ProtocolVersion:   "2.0",
+		AdminEmails:       []string{h.AdminEmail},
+		EarliestDatestamp: h.earliestDatestamp(),
+		DeletedRecord:     "no",
+		Granularity:       "YYYY-MM-DD",
+	}
+}
+
+func (h *Handler) listMetadataFormats(resp *response, identifier string) {
+	if len(identifier) > 0 {
+		if _, err := h.ebook(identifier); err != nil {
+			resp.sourceError(err, identifier)
+			return
+		}
+	}
+	resp.ListMetadataFormats = &listMetadataFormats{
+		Formats: []metadataFormat{{Prefix: MetadataPrefix, Schema: schemaOAIDC, Namespace: NamespaceOAIDC}},
+	}
+}
+
+func (h *Handler) getRecord(resp *response, identifier, prefix string) {
+	if prefix != MetadataPrefix {
+		resp.error(ErrCannotDisseminateFormat, fmt.Sprintf("unsupported metadata format: %s", prefix))
+		return
+	}
+
+	e, err := h.ebook(identifier)
+	if err != nil {
+		resp.sourceError(err, identifier)
+		return
+	}
+	rec, err := h.record(e)
+	if err != nil {
+		resp.err = err
+		return
+	}
+	resp.GetRecord = &getRecord{Record: rec}
+}
+
+// list handles both ListIdentifiers and ListRecords, reading the ebooks of the
+// Source in order until a page of records released within the date range has
+// been found, or the ReadLimit is reached.
+func (h *Handler) list(resp *response, verb string, args url.Values) {
+	var list listArgs
+	if token := args.Get("resumptionToken"); len(token) > 0 {
+		var ok bool
+		if list, ok = decodeToken(token); !ok {
+			resp.error(ErrBadResumptionToken, "invalid resumption token")
+			return
+		}
+	} else {
+		if prefix := args.Get("metadataPrefix"); prefix != MetadataPrefix {
+			resp.error(ErrCannotDisseminateFormat, fmt.Sprintf("unsupported metadata format: %s", prefix))
+			return
+		}
+		if len(args.Get("set")) > 0 {
+			resp.error(ErrNoSetHierarchy, "sets are not supported")
+			return
+		}
+		list.from, list.until = args.Get("from"), args.Get("until")
+		if !validDate(list.from) || !validDate(list.until) {
+			resp.error(ErrBadArgument, "from/until must use the YYYY-MM-DD granularity")
+			return
+		}
+		if len(list.from) > 0 && len(list.until) > 0 && list.from > list.until {
+			resp.error(ErrBadArgument, "from must not be later than until")
+			return
+		}
+	}
+
+	ids, filtered, err := h.ids(list)
+	if err != nil {
+		resp.err = err
+		return
+	}
+	if list.offset > len(ids) {
+		resp.error(ErrBadResumptionToken, "invalid resumption token")
+		return
+	}
+
+	var records []record
+	offset, reads := list.offset, 0
+	for ; offset < len(ids) && len(records) < h.pageSize() && reads < h.readLimit(); offset++ {
+		reads++
+		e, err := h.Source.Ebook(ids[offset])
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			resp.err = err
+			return
+		}
+		if !list.matches(e.ReleaseDate) {
+			continue
+		}
+
+		rec := record{Header: h.header(e)}
+		if verb == "ListRecords" {
+			if rec, err = h.record(e); err != nil {
+				resp.err = err
+				return
+			}
+		}
+		records = append(records, rec)
+	}
+
+	if len(records) == 0 && offset == len(ids) && list.offset == 0 {
+		resp.error(ErrNoRecordsMatch, "no records match the request")
+		return
+	}
+
+	var token *resumptionToken
+	if offset < len(ids) || list.offset > 0 {
+		token = &resumptionToken{Cursor: list.cursor}
+		if filtered || len(list.from) == 0 && len(list.until) == 0 {
+			token.CompleteListSize = len(ids)
+		}
+		if offset < len(ids) {
+			next := list
+			next.offset, next.cursor = offset, list.cursor+len(records)
+			token.Token = next.encode()
+		}
+	}
+
+	if verb == "ListRecords" {
+		resp.ListRecords = &listRecords{Records: records, ResumptionToken: token}
+		return
+	}
+	lst := &listIdentifiers{ResumptionToken: token}
+	for _, rec := range records {
+		lst.Headers = append(lst.Headers, rec.Header)
+	}
+	resp.ListIdentifiers = lst
+}
+
+// ids returns the eText IDs to list, which are filtered by the date range when
+// the Source is a DateSource.
+func (h *Handler) ids(list listArgs) (ids []int, filtered bool, err error) {
+	if s, ok := h.Source.(DateSource); ok && (len(list.from) > 0 || len(list.until) > 0) {
+		ids, err = s.ReleasedIDs(list.from, list.until)
+		return ids, true, err
+	}
+	ids, err = h.Source.IDs()
+	return ids, false, err
+}
+
+func (h *Handler) record(e *pgrdf.Ebook) (record, error) {
+	w := bytes.NewBuffer([]byte{})
+	if err := e.WriteOAIDC(w); err != nil {
+		return record{}, err
+	}
+	dc := bytes.TrimPrefix(w.Bytes(), []byte(xml.Header))
+
+	return record{Header: h.header(e), Metadata: metadata{DC: dc}}, nil
+}
+
+func (h *Handler) header(e *pgrdf.Ebook) header {
+	return header{
+		Identifier: h.identifierPrefix() + strconv.Itoa(e.ID),
+		Datestamp:  e.ReleaseDate,
+	}
+}
+
+func (h *Handler) ebook(identifier string) (*pgrdf.Ebook, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(identifier, h.identifierPrefix()))
+	if err != nil || !strings.HasPrefix(identifier, h.identifierPrefix()) {
+		return nil, ErrNotFound
+	}
+	return h.Source.Ebook(id)
+}
+
+func (h *Handler) identifierPrefix() string {
+	if len(h.IdentifierPrefix) == 0 {
+		return DefaultIdentifierPrefix
+	}
+	return h.IdentifierPrefix
+}
+
+func (h *Handler) earliestDatestamp() string {
+	if len(h.EarliestDatestamp) == 0 {
+		return DefaultEarliestDatestamp
+	}
+	return h.EarliestDatestamp
+}
+
+func (h *Handler) pageSize() int {
+	if h.PageSize < 1 {
+		return DefaultPageSize
+	}
+	return h.PageSize
+}
+
+func (h *Handler) readLimit() int {
+	if h.ReadLimit < 1 {
+		return DefaultReadLimit
+	}
+	return h.ReadLimit
+}
+
+func (r *response) error(code, message string) {
+	r.Errors = append(r.Errors, oaiError{Code: code, Message: message})
+}
+
+func (r *response) sourceError(err error, identifier string) {
+	if errors.Is(err, ErrNotFound) {
+		r.error(ErrIDDoesNotExist, fmt.Sprintf("unknown identifier: %s", identifier))
+		return
+	}
+	r.err = err
+}
+
+// matches reports whether the release date is within the from/until range.
+func (l listArgs) matches(date string) bool {
+	return releasedWithin(date, l.from, l.until)
+}
+
+// releasedWithin reports whether the release date is within the from/until
+// dates, where an empty date leaves that end of the range open.
+func releasedWithin(date, from, until string) bool {
+	if len(from) > 0 && date < from {
+		return false
+	}
+	if len(until) > 0 && (len(date) == 0 || date > until) {
+		return false
+	}
+	return true
+}
+
+func (l listArgs) encode() string {
+	v := url.Values{}
+	v.Set("offset", strconv.Itoa(l.offset))
+	v.Set("cursor", strconv.Itoa(l.cursor))
+	if len(l.from) > 0 {
+		v.Set("from", l.from)
+	}
+	if len(l.until) > 0 {
+		v.Set("until", l.until)
+	}
+	return v.Encode()
+}
+
+func decodeToken(token string) (listArgs, bool) {
+	v, err := url.ParseQuery(token)
+	if err != nil {
+		return listArgs{}, false
+	}
+
+	var l listArgs
+	var errOffset, errCursor error
+	l.offset, errOffset = strconv.Atoi(v.Get("offset"))
+	l.cursor, errCursor = strconv.Atoi(v.Get("cursor"))
+	l.from, l.until = v.Get("from"), v.Get("until")
+
+	if errOffset != nil || errCursor != nil || l.offset < 0 || l.cursor < 0 {
+		return listArgs{}, false
+	}
+	if !validDate(l.from) || !validDate(l.until) {
+		return listArgs{}, false
+	}
+	return l, true
+}
+
+func validDate(date string) bool {
+	return len(date) == 0 || dateRE.MatchString(date)
+}
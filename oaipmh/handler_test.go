@@ -0,0 +1,213 @@
+package oaipmh_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/oaipmh"
+)
+
+func TestHandler_Identify(t *testing.T) {
+	body := get(t, testHandler(), "verb=Identify")
+
+	expected := []string{
+		`<request verb="Identify">https://example.org/oai</request>`,
+		`<repositoryName>Test Repository</repositoryName>`,
+		`<protocolVersion>2.0</protocolVersion>`,
+		`<adminEmail>admin@example.org</adminEmail>`,
+		`<earliestDatestamp>1971-12-01</earliestDatestamp>`,
+		`<granularity>YYYY-MM-DD</granularity>`,
+	}
+	assertContains(t, body, expected)
+}
+
+func TestHandler_GetRecord(t *testing.T) {
+	body := get(t, testHandler(), "verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:gutenberg.org:1400")
+
+	expected := []string{
+		`<identifier>oai:gutenberg.org:1400</identifier>`,
+		`<datestamp>1998-07-01</datestamp>`,
+		`<metadata><oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/"`,
+		`<dc:title>Great Expectations</dc:title>`,
+	}
+	assertContains(t, body, expected)
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		query string
+		code  string
+	}{
+		{"verb=Unknown", "badVerb"},
+		{"verb=GetRecord&identifier=oai:gutenberg.org:1400", "badArgument"},
+		{"verb=GetRecord&metadataPrefix=marc21&identifier=oai:gutenberg.org:1400", "cannotDisseminateFormat"},
+		{"verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:gutenberg.org:1", "idDoesNotExist"},
+		{"verb=ListRecords&metadataPrefix=oai_dc&from=2030-01-01", "noRecordsMatch"},
+		{"verb=ListRecords&metadataPrefix=oai_dc&from=2001-01-01T00:00:00Z", "badArgument"},
+		{"verb=ListRecords&resumptionToken=bogus", "badResumptionToken"},
+		{"verb=ListRecords&metadataPrefix=oai_dc&resumptionToken=offset%3D1%26cursor%3D1", "badArgument"},
+		{"verb=ListSets", "noSetHierarchy"},
+	}
+	for _, test := range tests {
+		body := get(t, testHandler(), test.query)
+		if !strings.Contains(body, `<error code="`+test.code+`">`) {
+			t.Errorf("expected %s error for '%s', got:\n%s", test.code, test.query, body)
+		}
+	}
+}
+
+// brokenSource fails to read any ebooks, e.g. when the catalog is unavailable.
+type brokenSource struct{}
+
+func (brokenSource) IDs() ([]int, error) { return nil, errors.New("catalog unavailable") }
+func (brokenSource) Ebook(id int) (*pgrdf.Ebook, error) {
+	return nil, errors.New("catalog unavailable")
+}
+
+func TestHandler_SourceErrors(t *testing.T) {
+	h := oaipmh.NewHandler(brokenSource{}, "Test Repository", "https://example.org/oai", "admin@example.org")
+
+	for _, query := range []string{
+		"verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:gutenberg.org:1400",
+		"verb=ListRecords&metadataPrefix=oai_dc",
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/oai?"+query, nil))
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500 for '%s', got %d", query, rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), "badArgument") {
+			t.Errorf("expected no badArgument error for '%s'", query)
+		}
+	}
+}
+
+func TestHandler_ListIdentifiers_ResumptionToken(t *testing.T) {
+	h := testHandler()
+	h.PageSize = 2
+
+	body := get(t, h, "verb=ListIdentifiers&metadataPrefix=oai_dc")
+	assertContains(t, body, []string{
+		`<identifier>oai:gutenberg.org:98</identifier>`,
+		`<identifier>oai:gutenberg.org:1400</identifier>`,
+		`<resumptionToken completeListSize="3" cursor="0">cursor=2&amp;offset=2</resumptionToken>`,
+	})
+	if strings.Contains(body, "<identifier>oai:gutenberg.org:7000</identifier>") {
+		t.Error("expected the third record on the next page")
+	}
+
+	body = get(t, h, "verb=ListIdentifiers&resumptionToken="+url.QueryEscape("cursor=2&offset=2"))
+	assertContains(t, body, []string{
+		`<identifier>oai:gutenberg.org:7000</identifier>`,
+		`<resumptionToken completeListSize="3" cursor="2"></resumptionToken>`,
+	})
+}
+
+func TestHandler_ListRecords_DateRange(t *testing.T) {
+	body := get(t, testHandler(), "verb=ListRecords&metadataPrefix=oai_dc&from=1995-01-01&until=2000-12-31")
+
+	assertContains(t, body, []string{
+		`<identifier>oai:gutenberg.org:1400</identifier>`,
+		`<dc:title>Great Expectations</dc:title>`,
+	})
+	for _, id := range []string{"98", "7000"} {
+		if strings.Contains(body, "<identifier>oai:gutenberg.org:"+id+"</identifier>") {
+			t.Errorf("expected record %s to be outside the date range", id)
+		}
+	}
+	if strings.Contains(body, "<resumptionToken") {
+		t.Error("expected no resumption token for a complete list")
+	}
+}
+
+// scanSource hides the ReleasedIDs method of a MemorySource, so the Handler
+// has to read each ebook to filter them by release date.
+type scanSource struct {
+	oaipmh.Source
+}
+
+func TestHandler_ListIdentifiers_ReadLimit(t *testing.T) {
+	h := testHandler()
+	h.Source = scanSource{h.Source}
+	h.ReadLimit = 1
+
+	// the first ebook, 98, is outside the range, giving an empty page
+	body := get(t, h, "verb=ListIdentifiers&metadataPrefix=oai_dc&from=1995-01-01")
+	if strings.Contains(body, "<header>") || strings.Contains(body, "noRecordsMatch") {
+		t.Errorf("expected an empty page, got:\n%s", body)
+	}
+	assertContains(t, body, []string{
+		`<resumptionToken cursor="0">cursor=0&amp;from=1995-01-01&amp;offset=1</resumptionToken>`,
+	})
+
+	body = get(t, h, "verb=ListIdentifiers&resumptionToken="+url.QueryEscape("cursor=0&from=1995-01-01&offset=1"))
+	assertContains(t, body, []string{
+		`<identifier>oai:gutenberg.org:1400</identifier>`,
+		`<resumptionToken cursor="0">cursor=1&amp;from=1995-01-01&amp;offset=2</resumptionToken>`,
+	})
+}
+
+func TestHandler_ListIdentifiers_DateSource(t *testing.T) {
+	h := testHandler()
+	h.PageSize = 1
+
+	// only the ebooks within the range are listed
+	body := get(t, h, "verb=ListIdentifiers&metadataPrefix=oai_dc&from=1995-01-01")
+	assertContains(t, body, []string{
+		`<identifier>oai:gutenberg.org:1400</identifier>`,
+		`<resumptionToken completeListSize="2" cursor="0">cursor=1&amp;from=1995-01-01&amp;offset=1</resumptionToken>`,
+	})
+}
+
+func TestDirectorySource(t *testing.T) {
+	source := oaipmh.DirectorySource{BaseDir: "../samples"}
+
+	ids, err := source.IDs()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ids) != 1 || ids[0] != 999991234 {
+		t.Fatalf("unexpected IDs, got %v", ids)
+	}
+	if _, err := source.Ebook(1); err != oaipmh.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func testHandler() *oaipmh.Handler {
+	source := oaipmh.NewMemorySource([]*pgrdf.Ebook{
+		{ID: 1400, Titles: []string{"Great Expectations"}, ReleaseDate: "1998-07-01"},
+		{ID: 98, Titles: []string{"A Tale of Two Cities"}, ReleaseDate: "1994-01-01"},
+		{ID: 7000, Titles: []string{"Kalevala"}, ReleaseDate: "2004-11-01"},
+	})
+	return oaipmh.NewHandler(source, "Test Repository", "https://example.org/oai", "admin@example.org")
+}
+
+func get(t *testing.T, h *oaipmh.Handler, query string) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/oai?"+query, nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/xml; charset=utf-8" {
+		t.Errorf("unexpected content type, got '%s'", ct)
+	}
+	data, _ := io.ReadAll(rec.Body)
+	return string(data)
+}
+
+func assertContains(t *testing.T, body string, expected []string) {
+	t.Helper()
+
+	for _, s := range expected {
+		if !strings.Contains(body, s) {
+			t.Errorf("expected response to contain: %s\ngot:\n%s", s, body)
+		}
+	}
+}
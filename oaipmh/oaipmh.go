@@ -0,0 +1,131 @@
+// Package oaipmh provides an OAI-PMH 2.0 data provider for harvesting ebook
+// metadata as unqualified Dublin Core (`oai_dc`) records.
+//
+// The Handler supports the Identify, ListMetadataFormats, ListSets,
+// ListIdentifiers, ListRecords and GetRecord verbs. Sets are not supported,
+// and the record datestamps are the ebook release dates, so only day
+// granularity is used for the `from` and `until` arguments.
+//
+// See http://www.openarchives.org/OAI/openarchivesprotocol.html for the
+// protocol specification.
+package oaipmh
+
+import (
+	"encoding/xml"
+)
+
+// Namespaces and schemas used in the responses.
+const (
+	NamespaceOAI   = "http://www.openarchives.org/OAI/2.0/"
+	NamespaceOAIDC = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	namespaceXsi   = "http://www.w3.org/2001/XMLSchema-instance"
+	schemaOAI      = "http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+	schemaOAIDC    = "http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+)
+
+// MetadataPrefix is the only supported metadata format.
+const MetadataPrefix = "oai_dc"
+
+// OAI-PMH error codes.
+const (
+	ErrBadArgument             = "badArgument"
+	ErrBadResumptionToken      = "badResumptionToken"
+	ErrBadVerb                 = "badVerb"
+	ErrCannotDisseminateFormat = "cannotDisseminateFormat"
+	ErrIDDoesNotExist          = "idDoesNotExist"
+	ErrNoRecordsMatch          = "noRecordsMatch"
+	ErrNoSetHierarchy          = "noSetHierarchy"
+)
+
+type response struct {
+	XMLName        xml.Name `xml:"OAI-PMH"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	XmlnsXsi       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+
+	ResponseDate string  `xml:"responseDate"`
+	Request      request `xml:"request"`
+	Errors       []oaiError
+
+	Identify            *identify            `xml:"Identify,omitempty"`
+	ListMetadataFormats *listMetadataFormats `xml:"ListMetadataFormats,omitempty"`
+	ListIdentifiers     *listIdentifiers     `xml:"ListIdentifiers,omitempty"`
+	ListRecords         *listRecords         `xml:"ListRecords,omitempty"`
+	GetRecord           *getRecord           `xml:"GetRecord,omitempty"`
+
+	// err is a server-side failure, such as the Source being unavailable,
+	// which is not the fault of the request arguments.
+	err error
+}
+
+type request struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	URL             string `xml:",chardata"`
+}
+
+type oaiError struct {
+	XMLName xml.Name `xml:"error"`
+	Code    string   `xml:"code,attr"`
+	Message string   `xml:",chardata"`
+}
+
+type identify struct {
+	RepositoryName    string   `xml:"repositoryName"`
+	BaseURL           string   `xml:"baseURL"`
+	ProtocolVersion   string   `xml:"protocolVersion"`
+	AdminEmails       []string `xml:"adminEmail"`
+	EarliestDatestamp string   `xml:"earliestDatestamp"`
+	DeletedRecord     string   `xml:"deletedRecord"`
+	Granularity       string   `xml:"granularity"`
+}
+
+type listMetadataFormats struct {
+	Formats []metadataFormat `xml:"metadataFormat"`
+}
+
+type metadataFormat struct {
+	Prefix    string `xml:"metadataPrefix"`
+	Schema    string `xml:"schema"`
+	Namespace string `xml:"metadataNamespace"`
+}
+
+type listIdentifiers struct {
+	Headers         []header         `xml:"header"`
+	ResumptionToken *resumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+type listRecords struct {
+	Records         []record         `xml:"record"`
+	ResumptionToken *resumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+type getRecord struct {
+	Record record `xml:"record"`
+}
+
+type record struct {
+	Header   header   `xml:"header"`
+	Metadata metadata `xml:"metadata"`
+}
+
+type header struct {
+	Identifier string `xml:"identifier"`
+	Datestamp  string `xml:"datestamp"`
+}
+
+// metadata holds the `oai_dc:dc` XML written by `Ebook.WriteOAIDC`.
+type metadata struct {
+	DC []byte `xml:",innerxml"`
+}
+
+// resumptionToken is empty for the last page of an incomplete list.
+type resumptionToken struct {
+	CompleteListSize int    `xml:"completeListSize,attr,omitempty"` // omitted when unknown
+	Cursor           int    `xml:"cursor,attr"`
+	Token            string `xml:",chardata"`
+}
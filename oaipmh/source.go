@@ -0,0 +1,101 @@
+package oaipmh
+
+import (
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/archive"
+)
+
+// ErrNotFound is returned by a Source when no ebook exists for an eText ID.
+var ErrNotFound = errors.New("oaipmh: ebook not found")
+
+// Source provides the ebooks served by the Handler.
+type Source interface {
+	// IDs returns the eText IDs of all ebooks, in a stable order, which is
+	// used when paging through the records with a resumption token.
+	IDs() ([]int, error)
+
+	// Ebook returns the ebook for the eText ID, or ErrNotFound.
+	Ebook(id int) (*pgrdf.Ebook, error)
+}
+
+// DateSource is a Source which can list the ebooks released within a date
+// range, so the Handler does not have to read every ebook to filter them by
+// their release date.
+type DateSource interface {
+	Source
+
+	// ReleasedIDs returns the eText IDs of the ebooks released within the
+	// from/until dates (YYYY-MM-DD, inclusive), in the same order as IDs.
+	// An empty from or until leaves that end of the range open.
+	ReleasedIDs(from, until string) ([]int, error)
+}
+
+// DirectorySource reads the ebooks from an extracted RDF catalog directory,
+// using the directory structure required by `archive.FromDirectory`.
+type DirectorySource struct {
+	BaseDir string
+}
+
+// IDs returns the eText IDs of all RDF files in the directory.
+func (s DirectorySource) IDs() ([]int, error) {
+	return archive.DirectoryIDs(s.BaseDir)
+}
+
+// Ebook reads the RDF file for the eText ID from the directory.
+func (s DirectorySource) Ebook(id int) (*pgrdf.Ebook, error) {
+	ebook, err := archive.FromDirectory(s.BaseDir, id)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return ebook, err
+}
+
+// MemorySource serves a fixed set of ebooks held in memory.
+type MemorySource struct {
+	ids    []int
+	ebooks map[int]*pgrdf.Ebook
+}
+
+// NewMemorySource creates a MemorySource for the ebooks, which are served in
+// order of their eText ID.
+func NewMemorySource(ebooks []*pgrdf.Ebook) *MemorySource {
+	s := &MemorySource{ebooks: make(map[int]*pgrdf.Ebook)}
+	for _, e := range ebooks {
+		if _, ok := s.ebooks[e.ID]; !ok {
+			s.ids = append(s.ids, e.ID)
+		}
+		s.ebooks[e.ID] = e
+	}
+	sort.Ints(s.ids)
+	return s
+}
+
+// IDs returns the eText IDs of all ebooks.
+func (s *MemorySource) IDs() ([]int, error) {
+	return s.ids, nil
+}
+
+// ReleasedIDs returns the eText IDs of the ebooks released within the
+// from/until dates.
+func (s *MemorySource) ReleasedIDs(from, until string) ([]int, error) {
+	var ids []int
+	for _, id := range s.ids {
+		if releasedWithin(s.ebooks[id].ReleaseDate, from, until) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Ebook returns the ebook for the eText ID.
+func (s *MemorySource) Ebook(id int) (*pgrdf.Ebook, error) {
+	e, ok := s.ebooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return e, nil
+}
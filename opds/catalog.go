@@ -0,0 +1,316 @@
+package opds
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrcook/pgrdf"
+)
+
+// DefaultPageSize is the number of entries per page of a feed.
+const DefaultPageSize = 25
+
+// ErrNotFound is returned when a facet, facet value, page or ebook does not exist.
+var ErrNotFound = errors.New("opds: not found")
+
+// Facet is a grouping of the catalog ebooks used for navigation. The value is
+// used as the URL path segment of the facet feeds.
+type Facet string
+
+const (
+	FacetBookshelf Facet = "bookshelves"
+	FacetSubject   Facet = "subjects"
+	FacetLanguage  Facet = "languages"
+	FacetAuthor    Facet = "authors"
+)
+
+// Facets are the navigation facets in the order they are listed in the root feed.
+var Facets = []Facet{FacetBookshelf, FacetSubject, FacetLanguage, FacetAuthor}
+
+var facetTitles = map[Facet]string{
+	FacetBookshelf: "Bookshelves",
+	FacetSubject:   "Subjects",
+	FacetLanguage:  "Languages",
+	FacetAuthor:    "Authors",
+}
+
+// Catalog builds the navigation and acquisition feeds for a collection of ebooks.
+//
+// The feed URLs are built from the BaseURL as follows:
+//
+//	{BaseURL}                           root navigation feed
+//	{BaseURL}/{facet}?page=N            navigation feed of the facet values
+//	{BaseURL}/{facet}/{value}?page=N    acquisition feed of the ebooks for a value
+//	{BaseURL}/ebooks/{id}               acquisition feed of a single ebook
+//
+// Facet values are sorted by name and ebooks by their eText ID, so that the
+// pages are stable for the same collection.
+type Catalog struct {
+	BaseURL  string
+	Title    string
+	PageSize int
+
+	ebooks  []*pgrdf.Ebook
+	byID    map[int]*pgrdf.Ebook
+	groups  map[Facet]map[string]*group
+	updated time.Time
+}
+
+// group holds the ebooks for a single facet value.
+type group struct {
+	key    string
+	title  string
+	ebooks []*pgrdf.Ebook
+}
+
+// NewCatalog creates a Catalog for the given ebooks, with the feeds served
+// from the base URL, e.g. "https://example.org/opds".
+func NewCatalog(baseURL, title string, ebooks []*pgrdf.Ebook) *Catalog {
+	c := &Catalog{
+		BaseURL:  strings.TrimSuffix(baseURL, "/"),
+		Title:    title,
+		PageSize: DefaultPageSize,
+		byID:     make(map[int]*pgrdf.Ebook),
+		groups:   make(map[Facet]map[string]*group),
+	}
+	for _, f := range Facets {
+		c.groups[f] = make(map[string]*group)
+	}
+
+	c.ebooks = append(c.ebooks, ebooks...)
+	sort.SliceStable(c.ebooks, func(i, j int) bool { return c.ebooks[i].ID < c.ebooks[j].ID })
+
+	for _, e := range c.ebooks {
+		c.byID[e.ID] = e
+		if t := updated(e); t.After(c.updated) {
+			c.updated = t
+		}
+
+		for _, shelf := range e.Bookshelves {
+			c.add(FacetBookshelf, shelf.Name, shelf.Name, e)
+		}
+		for _, s := range e.Subjects {
+			c.add(FacetSubject, s.Heading, s.Heading, e)
+		}
+		for _, lang := range e.Languages {
+			c.add(FacetLanguage, lang, lang, e)
+		}
+		for _, cr := range e.Creators {
+			if cr.Role != pgrdf.RoleAut && len(cr.Role) > 0 {
+				continue
+			}
+			key := cr.Name
+			if cr.ID > 0 {
+				key = strconv.Itoa(cr.ID)
+			}
+			c.add(FacetAuthor, key, cr.Name, e)
+		}
+	}
+
+	return c
+}
+
+func (c *Catalog) add(facet Facet, key, title string, e *pgrdf.Ebook) {
+	if len(key) == 0 {
+		return
+	}
+	g, ok := c.groups[facet][key]
+	if !ok {
+		g = &group{key: key, title: title}
+		c.groups[facet][key] = g
+	}
+	// an ebook may list the same value more than once, e.g. an author with two roles
+	if n := len(g.ebooks); n > 0 && g.ebooks[n-1] == e {
+		return
+	}
+	g.ebooks = append(g.ebooks, e)
+}
+
+// Root returns the start navigation feed, with an entry for each facet.
+func (c *Catalog) Root() *Feed {
+	feed := c.newFeed(c.BaseURL, c.Title, TypeNavigation)
+
+	for _, facet := range Facets {
+		href := c.facetURL(facet)
+		feed.Entries = append(feed.Entries, &Entry{
+			ID:      href,
+			Title:   facetTitles[facet],
+			Updated: feed.Updated,
+			Content: &Content{Type: "text", Value: fmt.Sprintf("%d entries", len(c.groups[facet]))},
+			Links:   []Link{{Rel: RelSubsection, Href: href, Type: TypeNavigation}},
+		})
+	}
+
+	return feed
+}
+
+// Navigation returns a page of the navigation feed listing the values of the
+// facet, each linking to the acquisition feed of its ebooks. Pages start at 1.
+func (c *Catalog) Navigation(facet Facet, page int) (*Feed, error) {
+	groups, ok := c.groups[facet]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	sorted := make([]*group, 0, len(groups))
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := strings.ToLower(sorted[i].title), strings.ToLower(sorted[j].title)
+		if a != b {
+			return a < b
+		}
+		return sorted[i].key < sorted[j].key
+	})
+
+	start, end, err := c.pageRange(page, len(sorted))
+	if err != nil {
+		return nil, err
+	}
+
+	href := c.facetURL(facet)
+	feed := c.newFeed(href, facetTitles[facet], TypeNavigation)
+	feed.Links = append(feed.Links, Link{Rel: RelUp, Href: c.BaseURL, Type: TypeNavigation})
+	feed.Links = append(feed.Links, c.pageLinks(href, TypeNavigation, page, len(sorted))...)
+	feed.Link(RelSelf).Href = pageURL(href, page)
+
+	for _, g := range sorted[start:end] {
+		groupHref := c.groupURL(facet, g.key)
+		feed.Entries = append(feed.Entries, &Entry{
+			ID:      groupHref,
+			Title:   g.title,
+			Updated: feed.Updated,
+			Content: &Content{Type: "text", Value: fmt.Sprintf("%d books", len(g.ebooks))},
+			Links:   []Link{{Rel: RelSubsection, Href: groupHref, Type: TypeAcquisition, Count: len(g.ebooks)}},
+		})
+	}
+
+	return feed, nil
+}
+
+// Acquisition returns a page of the acquisition feed for the ebooks with the
+// given facet value, e.g. the bookshelf name, language code, or author ID.
+func (c *Catalog) Acquisition(facet Facet, value string, page int) (*Feed, error) {
+	g, ok := c.groups[facet][value]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	start, end, err := c.pageRange(page, len(g.ebooks))
+	if err != nil {
+		return nil, err
+	}
+
+	href := c.groupURL(facet, g.key)
+	feed := c.newFeed(href, g.title, TypeAcquisition)
+	feed.Links = append(feed.Links, Link{Rel: RelUp, Href: c.facetURL(facet), Type: TypeNavigation})
+	feed.Links = append(feed.Links, c.pageLinks(href, TypeAcquisition, page, len(g.ebooks))...)
+	feed.Link(RelSelf).Href = pageURL(href, page)
+
+	for _, e := range g.ebooks[start:end] {
+		feed.Entries = append(feed.Entries, c.entry(e))
+	}
+
+	return feed, nil
+}
+
+// Book returns the acquisition feed for a single ebook.
+func (c *Catalog) Book(id int) (*Feed, error) {
+	e, ok := c.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	entry := c.entry(e)
+	feed := c.newFeed(c.bookURL(id), entry.Title, TypeAcquisition)
+	feed.Entries = append(feed.Entries, entry)
+	return feed, nil
+}
+
+// entry returns the ebook entry with a link to its own feed in this catalog.
+func (c *Catalog) entry(e *pgrdf.Ebook) *Entry {
+	entry := NewEntry(e)
+	entry.Links = append(entry.Links, Link{Rel: RelAlternate, Href: c.bookURL(e.ID), Type: TypeEntry})
+	return entry
+}
+
+func (c *Catalog) newFeed(href, title string, kind string) *Feed {
+	return &Feed{
+		ID:      href,
+		Title:   title,
+		Updated: c.updated.Format(time.RFC3339),
+		Links: []Link{
+			{Rel: RelSelf, Href: href, Type: kind},
+			{Rel: RelStart, Href: c.BaseURL, Type: TypeNavigation},
+		},
+	}
+}
+
+// pageRange returns the slice bounds of the page, where the first page of an
+// empty list is valid.
+func (c *Catalog) pageRange(page, total int) (int, int, error) {
+	if page < 1 || page > c.pageCount(total) {
+		return 0, 0, ErrNotFound
+	}
+	start := (page - 1) * c.pageSize()
+	end := start + c.pageSize()
+	if end > total {
+		end = total
+	}
+	return start, end, nil
+}
+
+func (c *Catalog) pageLinks(href, kind string, page, total int) []Link {
+	last := c.pageCount(total)
+	if last == 1 {
+		return nil
+	}
+
+	links := []Link{{Rel: RelFirst, Href: pageURL(href, 1), Type: kind}}
+	if page > 1 {
+		links = append(links, Link{Rel: RelPrevious, Href: pageURL(href, page-1), Type: kind})
+	}
+	if page < last {
+		links = append(links, Link{Rel: RelNext, Href: pageURL(href, page+1), Type: kind})
+	}
+	return append(links, Link{Rel: RelLast, Href: pageURL(href, last), Type: kind})
+}
+
+func (c *Catalog) pageCount(total int) int {
+	if total == 0 {
+		return 1
+	}
+	return (total + c.pageSize() - 1) / c.pageSize()
+}
+
+func (c *Catalog) pageSize() int {
+	if c.PageSize < 1 {
+		return DefaultPageSize
+	}
+	return c.PageSize
+}
+
+func (c *Catalog) facetURL(facet Facet) string {
+	return c.BaseURL + "/" + string(facet)
+}
+
+func (c *Catalog) groupURL(facet Facet, key string) string {
+	return c.facetURL(facet) + "/" + url.PathEscape(key)
+}
+
+func (c *Catalog) bookURL(id int) string {
+	return fmt.Sprintf("%s/ebooks/%d", c.BaseURL, id)
+}
+
+func pageURL(href string, page int) string {
+	if page <= 1 {
+		return href
+	}
+	return fmt.Sprintf("%s?page=%d", href, page)
+}
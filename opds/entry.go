@@ -0,0 +1,106 @@
+package opds
+
+import (
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mrcook/pgrdf"
+)
+
+// agentURI is the gutenberg.org URI format for an agent (creator) ID.
+const agentURI = "http://www.gutenberg.org/2009/agents/%d"
+
+// NewEntry converts an Ebook to an OPDS catalog entry.
+//
+// Authors are written as Atom authors, subjects as categories, and each file as
+// an open-access acquisition link. Image files and book covers are written as
+// image links, with the first image also used as the thumbnail.
+func NewEntry(e *pgrdf.Ebook) *Entry {
+	entry := &Entry{
+		ID:        e.URL(),
+		Title:     strings.Join(e.Titles, ": "),
+		Updated:   updated(e).Format(time.RFC3339),
+		Languages: e.Languages,
+		Issued:    e.ReleaseDate,
+		Publisher: e.Publisher,
+		Rights:    e.Copyright,
+	}
+
+	for _, c := range e.Creators {
+		if c.Role != pgrdf.RoleAut && len(c.Role) > 0 {
+			continue
+		}
+		author := Person{Name: c.Name}
+		if c.ID > 0 {
+			author.URI = fmt.Sprintf(agentURI, c.ID)
+		}
+		entry.Authors = append(entry.Authors, author)
+	}
+
+	for _, s := range e.Subjects {
+		entry.Categories = append(entry.Categories, Category{Scheme: s.Schema, Term: s.Heading, Label: s.Heading})
+	}
+
+	if len(e.Summary) > 0 {
+		entry.Summary = e.Summary
+	}
+	if len(e.Notes) > 0 {
+		entry.Content = &Content{Type: "text", Value: strings.Join(e.Notes, "\n")}
+	}
+
+	entry.Links = append(entry.Links, Link{Rel: RelAlternate, Href: e.URL(), Type: "text/html"})
+
+	var images []Link
+	for _, cover := range e.BookCovers {
+		if href := cover.AbsoluteURL(); len(href) > 0 {
+			images = append(images, Link{Href: href, Type: mime.TypeByExtension(path.Ext(href))})
+		}
+	}
+	for _, f := range e.Files {
+		if len(f.Encodings) == 0 {
+			continue
+		}
+		if strings.HasPrefix(f.Encodings[0], "image/") {
+			images = append(images, Link{Href: f.URL, Type: f.Encodings[0]})
+			continue
+		}
+		entry.Links = append(entry.Links, Link{Rel: RelAcquisition, Href: f.URL, Type: f.Encodings[0], Length: f.Extent})
+	}
+	if len(images) > 0 {
+		image, thumbnail := images[0], images[0]
+		image.Rel, thumbnail.Rel = RelImage, RelThumbnail
+		entry.Links = append(entry.Links, image, thumbnail)
+	}
+
+	return entry
+}
+
+// BookFeed returns an acquisition feed containing the single ebook entry.
+func BookFeed(e *pgrdf.Ebook) *Feed {
+	entry := NewEntry(e)
+	return &Feed{
+		ID:      entry.ID,
+		Title:   entry.Title,
+		Updated: entry.Updated,
+		Links:   []Link{{Rel: RelSelf, Href: entry.ID + ".opds", Type: TypeAcquisition}},
+		Entries: []*Entry{entry},
+	}
+}
+
+// updated returns the most recent file modification date, or the release
+// date when there are no files.
+func updated(e *pgrdf.Ebook) time.Time {
+	var latest time.Time
+	for _, f := range e.Files {
+		if t, err := f.ModifiedTime(); err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+	if latest.IsZero() {
+		latest, _ = time.Parse("2006-01-02", e.ReleaseDate)
+	}
+	return latest.UTC()
+}
@@ -0,0 +1,149 @@
+// Package opds renders OPDS 1.2 catalog feeds from a collection of ebooks.
+//
+// Each ebook is written as an Atom entry with open-access acquisition links for
+// its files, and image links for its cover. A Catalog groups the ebooks into
+// paginated navigation feeds by bookshelf, subject, language and author.
+//
+// For OPDS 2.0 readers, NewPublication converts an ebook to a JSON publication.
+//
+// See https://specs.opds.io/opds-1.2 for the specification.
+package opds
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Namespaces used in the feeds.
+const (
+	NamespaceAtom    = "http://www.w3.org/2005/Atom"
+	NamespaceDcTerms = "http://purl.org/dc/terms/"
+	NamespaceOPDS    = "http://opds-spec.org/2010/catalog"
+	NamespaceThread  = "http://purl.org/syndication/thread/1.0"
+)
+
+// Media types of the catalog documents.
+const (
+	TypeNavigation  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	TypeAcquisition = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	TypeEntry       = "application/atom+xml;type=entry;profile=opds-catalog"
+)
+
+// Link relations used in the feeds.
+const (
+	RelSelf        = "self"
+	RelStart       = "start"
+	RelUp          = "up"
+	RelSubsection  = "subsection"
+	RelAlternate   = "alternate"
+	RelFirst       = "first"
+	RelPrevious    = "previous"
+	RelNext        = "next"
+	RelLast        = "last"
+	RelAcquisition = "http://opds-spec.org/acquisition/open-access"
+	RelImage       = "http://opds-spec.org/image"
+	RelThumbnail   = "http://opds-spec.org/image/thumbnail"
+)
+
+// Feed is an Atom `<feed>`, which is either a navigation or acquisition feed.
+//
+// NOTE: the Atom elements have no namespace so that the namespaces are only
+// declared once on the root element when marshaling.
+type Feed struct {
+	XMLName   xml.Name `xml:"feed"`
+	Xmlns     string   `xml:"xmlns,attr,omitempty"`
+	XmlnsDc   string   `xml:"xmlns:dc,attr,omitempty"`
+	XmlnsOPDS string   `xml:"xmlns:opds,attr,omitempty"`
+	XmlnsThr  string   `xml:"xmlns:thr,attr,omitempty"`
+
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Author  *Person  `xml:"author,omitempty"`
+	Links   []Link   `xml:"link"`
+	Entries []*Entry `xml:"entry"`
+}
+
+// Entry is an Atom `<entry>`, describing either an ebook (acquisition feeds)
+// or a link to another feed (navigation feeds).
+type Entry struct {
+	ID         string     `xml:"id"`
+	Title      string     `xml:"title"`
+	Updated    string     `xml:"updated"`
+	Authors    []Person   `xml:"author,omitempty"`
+	Categories []Category `xml:"category,omitempty"`
+	Languages  []string   `xml:"dc:language,omitempty"`
+	Issued     string     `xml:"dc:issued,omitempty"`
+	Publisher  string     `xml:"dc:publisher,omitempty"`
+	Rights     string     `xml:"rights,omitempty"`
+	Summary    string     `xml:"summary,omitempty"`
+	Content    *Content   `xml:"content,omitempty"`
+	Links      []Link     `xml:"link"`
+}
+
+// Person is an Atom author or contributor.
+type Person struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+// Category is an Atom category, used for the ebook subjects.
+type Category struct {
+	Scheme string `xml:"scheme,attr,omitempty"`
+	Term   string `xml:"term,attr"`
+	Label  string `xml:"label,attr,omitempty"`
+}
+
+// Content is the Atom content of an entry.
+type Content struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Link is an Atom link. Count is the number of ebooks in the linked feed.
+type Link struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Title  string `xml:"title,attr,omitempty"`
+	Length int    `xml:"length,attr,omitempty"`
+	Count  int    `xml:"thr:count,attr,omitempty"`
+}
+
+// Link returns the first link with the given relation, or nil when not present.
+func (e *Entry) Link(rel string) *Link {
+	for i := range e.Links {
+		if e.Links[i].Rel == rel {
+			return &e.Links[i]
+		}
+	}
+	return nil
+}
+
+// Link returns the first link with the given relation, or nil when not present.
+func (f *Feed) Link(rel string) *Link {
+	for i := range f.Links {
+		if f.Links[i].Rel == rel {
+			return &f.Links[i]
+		}
+	}
+	return nil
+}
+
+// Write the feed as an XML document to the provided `io.Writer`.
+func (f *Feed) Write(w io.Writer) error {
+	f.Xmlns = NamespaceAtom
+	f.XmlnsDc = NamespaceDcTerms
+	f.XmlnsOPDS = NamespaceOPDS
+	f.XmlnsThr = NamespaceThread
+
+	data, err := xml.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	_, err = w.Write(data)
+	return err
+}
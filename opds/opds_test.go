@@ -0,0 +1,224 @@
+package opds_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/opds"
+)
+
+func TestNewEntry(t *testing.T) {
+	ebook := sampleEbook(t)
+	entry := opds.NewEntry(ebook)
+
+	if entry.ID != "https://www.gutenberg.org/ebooks/999991234" {
+		t.Errorf("unexpected ID, got '%s'", entry.ID)
+	}
+	if entry.Title != "Great Expectations: And a subtitle" {
+		t.Errorf("unexpected title, got '%s'", entry.Title)
+	}
+	if len(entry.Authors) != 1 || entry.Authors[0].Name != "Dickens, Charles" {
+		t.Errorf("unexpected authors, got %+v", entry.Authors)
+	}
+	if entry.Authors[0].URI != "http://www.gutenberg.org/2009/agents/37" {
+		t.Errorf("unexpected author URI, got '%s'", entry.Authors[0].URI)
+	}
+	if len(entry.Categories) != len(ebook.Subjects) {
+		t.Errorf("expected %d categories, got %d", len(ebook.Subjects), len(entry.Categories))
+	}
+
+	epub := findLink(entry.Links, "application/epub+zip")
+	if epub == nil {
+		t.Fatal("expected an EPUB acquisition link")
+	}
+	if epub.Rel != opds.RelAcquisition {
+		t.Errorf("unexpected EPUB link rel, got '%s'", epub.Rel)
+	}
+	if epub.Length == 0 {
+		t.Error("expected the EPUB link to have a length")
+	}
+
+	if entry.Link(opds.RelImage) == nil || entry.Link(opds.RelThumbnail) == nil {
+		t.Error("expected image and thumbnail links")
+	}
+}
+
+func TestBookFeed_Write(t *testing.T) {
+	w := bytes.NewBuffer([]byte{})
+	if err := opds.BookFeed(sampleEbook(t)).Write(w); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<feed xmlns="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/terms/" xmlns:opds="http://opds-spec.org/2010/catalog"`,
+		`<id>https://www.gutenberg.org/ebooks/999991234</id>`,
+		`<dc:language>en</dc:language>`,
+		`<link rel="http://opds-spec.org/acquisition/open-access" href="https://www.example.org/ebooks/999991234.epub.images" type="application/epub+zip"`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(w.String(), s) {
+			t.Errorf("expected feed to contain: %s", s)
+		}
+	}
+}
+
+func TestCatalog_Root(t *testing.T) {
+	catalog := opds.NewCatalog("https://example.org/opds/", "Test Catalog", testEbooks())
+	feed := catalog.Root()
+
+	if feed.ID != "https://example.org/opds" {
+		t.Errorf("unexpected ID, got '%s'", feed.ID)
+	}
+	if len(feed.Entries) != len(opds.Facets) {
+		t.Fatalf("expected %d entries, got %d", len(opds.Facets), len(feed.Entries))
+	}
+	if href := feed.Entries[0].Link(opds.RelSubsection).Href; href != "https://example.org/opds/bookshelves" {
+		t.Errorf("unexpected bookshelves link, got '%s'", href)
+	}
+	if feed.Updated != "2022-01-02T03:04:05Z" {
+		t.Errorf("unexpected updated date, got '%s'", feed.Updated)
+	}
+}
+
+func TestCatalog_Navigation(t *testing.T) {
+	catalog := opds.NewCatalog("https://example.org/opds", "Test Catalog", testEbooks())
+	catalog.PageSize = 2
+
+	feed, err := catalog.Navigation(opds.FacetLanguage, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(feed.Entries))
+	}
+	if feed.Entries[0].Title != "de" || feed.Entries[1].Title != "en" {
+		t.Errorf("unexpected entries, got '%s' and '%s'", feed.Entries[0].Title, feed.Entries[1].Title)
+	}
+	link := feed.Entries[1].Link(opds.RelSubsection)
+	if link.Href != "https://example.org/opds/languages/en" || link.Count != 2 {
+		t.Errorf("unexpected subsection link, got %+v", link)
+	}
+	if next := feed.Link(opds.RelNext); next == nil || next.Href != "https://example.org/opds/languages?page=2" {
+		t.Errorf("unexpected next link, got %+v", next)
+	}
+	if feed.Link(opds.RelPrevious) != nil {
+		t.Error("expected no previous link on the first page")
+	}
+
+	feed, err = catalog.Navigation(opds.FacetLanguage, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(feed.Entries) != 1 || feed.Entries[0].Title != "fi" {
+		t.Errorf("unexpected page 2 entries, got %d", len(feed.Entries))
+	}
+	if feed.Link(opds.RelSelf).Href != "https://example.org/opds/languages?page=2" {
+		t.Errorf("unexpected self link, got '%s'", feed.Link(opds.RelSelf).Href)
+	}
+
+	if _, err := catalog.Navigation(opds.FacetLanguage, 3); err != opds.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if _, err := catalog.Navigation("publishers", 1); err != opds.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestCatalog_Acquisition(t *testing.T) {
+	catalog := opds.NewCatalog("https://example.org/opds", "Test Catalog", testEbooks())
+
+	feed, err := catalog.Acquisition(opds.FacetAuthor, "37", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if feed.Title != "Dickens, Charles" {
+		t.Errorf("unexpected title, got '%s'", feed.Title)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(feed.Entries))
+	}
+	if feed.Entries[0].ID != "https://www.gutenberg.org/ebooks/98" || feed.Entries[1].ID != "https://www.gutenberg.org/ebooks/1400" {
+		t.Errorf("unexpected entry order, got '%s', '%s'", feed.Entries[0].ID, feed.Entries[1].ID)
+	}
+	if up := feed.Link(opds.RelUp); up == nil || up.Href != "https://example.org/opds/authors" {
+		t.Errorf("unexpected up link, got %+v", up)
+	}
+
+	feed, err = catalog.Acquisition(opds.FacetBookshelf, "Best Books Ever Listings", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if feed.ID != "https://example.org/opds/bookshelves/Best%20Books%20Ever%20Listings" {
+		t.Errorf("unexpected ID, got '%s'", feed.ID)
+	}
+}
+
+func TestCatalog_Book(t *testing.T) {
+	catalog := opds.NewCatalog("https://example.org/opds", "Test Catalog", testEbooks())
+
+	feed, err := catalog.Book(1400)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(feed.Entries) != 1 || feed.Entries[0].Title != "Great Expectations" {
+		t.Errorf("unexpected entries, got %+v", feed.Entries)
+	}
+	if _, err := catalog.Book(1); err != opds.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func sampleEbook(t *testing.T) *pgrdf.Ebook {
+	t.Helper()
+
+	file, err := os.Open("../samples/cache/epub/999991234/pg999991234.rdf")
+	if err != nil {
+		t.Fatalf("unable to open sample RDF: %s", err)
+	}
+	defer file.Close()
+
+	ebook, err := pgrdf.ReadRDF(file)
+	if err != nil {
+		t.Fatalf("unexpected error reading RDF: %s", err)
+	}
+	return ebook
+}
+
+func testEbooks() []*pgrdf.Ebook {
+	dickens := pgrdf.Creator{ID: 37, Name: "Dickens, Charles", Role: pgrdf.RoleAut}
+	return []*pgrdf.Ebook{
+		{
+			ID:          1400,
+			Titles:      []string{"Great Expectations"},
+			Languages:   []string{"en"},
+			Creators:    []pgrdf.Creator{dickens},
+			Bookshelves: []pgrdf.Bookshelf{{Name: "Best Books Ever Listings"}},
+			Files:       []pgrdf.File{{URL: "https://www.gutenberg.org/ebooks/1400.epub.images", Modified: "2022-01-02T03:04:05.123456"}},
+		},
+		{
+			ID:        98,
+			Titles:    []string{"A Tale of Two Cities"},
+			Languages: []string{"en", "de"},
+			Creators:  []pgrdf.Creator{dickens},
+		},
+		{
+			ID:          7000,
+			Titles:      []string{"Kalevala"},
+			Languages:   []string{"fi"},
+			ReleaseDate: "2004-11-01",
+		},
+	}
+}
+
+func findLink(links []opds.Link, kind string) *opds.Link {
+	for i := range links {
+		if links[i].Type == kind {
+			return &links[i]
+		}
+	}
+	return nil
+}
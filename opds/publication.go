@@ -0,0 +1,202 @@
+package opds
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mrcook/pgrdf"
+)
+
+// TypePublication is the media type of an OPDS 2.0 publication.
+const TypePublication = "application/opds-publication+json"
+
+// Publication is an OPDS 2.0 publication, which uses the Readium Web
+// Publication Manifest model.
+//
+// See https://drafts.opds.io/opds-2.0 for the specification.
+type Publication struct {
+	Metadata PublicationMetadata `json:"metadata"`
+	Links    []PublicationLink   `json:"links"`
+	Images   []PublicationLink   `json:"images,omitempty"`
+}
+
+// PublicationMetadata of an OPDS 2.0 publication. Creators are listed under
+// the property for their role, with all unsupported roles being contributors.
+type PublicationMetadata struct {
+	Type         string         `json:"@type"`
+	Identifier   string         `json:"identifier"`
+	Title        string         `json:"title"`
+	Subtitle     string         `json:"subtitle,omitempty"`
+	Authors      []Contributor  `json:"author,omitempty"`
+	Translators  []Contributor  `json:"translator,omitempty"`
+	Editors      []Contributor  `json:"editor,omitempty"`
+	Artists      []Contributor  `json:"artist,omitempty"`
+	Illustrators []Contributor  `json:"illustrator,omitempty"`
+	Colorists    []Contributor  `json:"colorist,omitempty"`
+	Narrators    []Contributor  `json:"narrator,omitempty"`
+	Contributors []Contributor  `json:"contributor,omitempty"`
+	Publisher    string         `json:"publisher,omitempty"`
+	Languages    []string       `json:"language,omitempty"`
+	Published    string         `json:"published,omitempty"`
+	Modified     string         `json:"modified,omitempty"`
+	Description  string         `json:"description,omitempty"`
+	Subjects     []PubSubject   `json:"subject,omitempty"`
+	BelongsTo    *PubCollection `json:"belongsTo,omitempty"`
+}
+
+// Contributor is a person involved in the creation of the publication.
+// Role is the relator code, and is only set for generic contributors.
+type Contributor struct {
+	Name       string `json:"name"`
+	Identifier string `json:"identifier,omitempty"`
+	Role       string `json:"role,omitempty"`
+}
+
+// PubSubject is a subject of the publication, with the LCSH or LCC scheme.
+type PubSubject struct {
+	Name   string `json:"name"`
+	Scheme string `json:"scheme,omitempty"`
+	Code   string `json:"code,omitempty"`
+}
+
+// PubCollection lists the series the publication belongs to.
+type PubCollection struct {
+	Series []PubSeries `json:"series,omitempty"`
+}
+
+// PubSeries is a series name.
+type PubSeries struct {
+	Name string `json:"name"`
+}
+
+// PublicationLink is a Readium link object.
+type PublicationLink struct {
+	Href       string          `json:"href"`
+	Type       string          `json:"type,omitempty"`
+	Rel        string          `json:"rel,omitempty"`
+	Title      string          `json:"title,omitempty"`
+	Properties *LinkProperties `json:"properties,omitempty"`
+}
+
+// LinkProperties are the additional link properties. Length is the file size
+// in bytes, from the ebook file extent.
+type LinkProperties struct {
+	Length int `json:"length,omitempty"`
+}
+
+// roleContributors returns the metadata property for the creator role, or nil
+// when the role is only supported as a generic contributor.
+func (m *PublicationMetadata) roleContributors(role pgrdf.MarcRelator) *[]Contributor {
+	switch role {
+	case pgrdf.RoleAut:
+		return &m.Authors
+	case pgrdf.RoleTrl:
+		return &m.Translators
+	case pgrdf.RoleEdt:
+		return &m.Editors
+	case pgrdf.RoleArt:
+		return &m.Artists
+	case pgrdf.RoleIll:
+		return &m.Illustrators
+	case pgrdf.RoleClr:
+		return &m.Colorists
+	case pgrdf.RoleNrt:
+		return &m.Narrators
+	default:
+		return nil
+	}
+}
+
+// NewPublication converts an Ebook to an OPDS 2.0 publication.
+//
+// Each file is added as an open-access acquisition link, except for image
+// files which are added to the images, along with the book covers.
+func NewPublication(e *pgrdf.Ebook) *Publication {
+	pub := &Publication{
+		Metadata: PublicationMetadata{
+			Type:        "http://schema.org/Book",
+			Identifier:  e.URL(),
+			Publisher:   e.Publisher,
+			Languages:   e.Languages,
+			Published:   e.ReleaseDate,
+			Description: e.Summary,
+		},
+	}
+	if len(e.Titles) > 0 {
+		pub.Metadata.Title = e.Titles[0]
+		pub.Metadata.Subtitle = strings.Join(e.Titles[1:], ": ")
+	}
+	if t := updated(e); !t.IsZero() {
+		pub.Metadata.Modified = t.Format(time.RFC3339)
+	}
+
+	for _, c := range e.Creators {
+		contributor := Contributor{Name: c.Name}
+		if c.ID > 0 {
+			contributor.Identifier = fmt.Sprintf(agentURI, c.ID)
+		}
+
+		role := c.Role
+		if len(role) == 0 {
+			role = pgrdf.RoleAut
+		}
+		if list := pub.Metadata.roleContributors(role); list != nil {
+			*list = append(*list, contributor)
+			continue
+		}
+		contributor.Role = string(role)
+		pub.Metadata.Contributors = append(pub.Metadata.Contributors, contributor)
+	}
+
+	for _, s := range e.Subjects {
+		subject := PubSubject{Name: s.Heading, Scheme: s.Schema}
+		if strings.HasSuffix(s.Schema, "LCC") {
+			subject.Code = s.Heading
+		}
+		pub.Metadata.Subjects = append(pub.Metadata.Subjects, subject)
+	}
+
+	if len(e.Series) > 0 {
+		pub.Metadata.BelongsTo = &PubCollection{}
+		for _, series := range e.Series {
+			pub.Metadata.BelongsTo.Series = append(pub.Metadata.BelongsTo.Series, PubSeries{Name: series})
+		}
+	}
+
+	pub.Links = append(pub.Links, PublicationLink{Rel: RelAlternate, Href: e.URL(), Type: "text/html"})
+
+	for _, cover := range e.BookCovers {
+		if href := cover.AbsoluteURL(); len(href) > 0 {
+			pub.Images = append(pub.Images, PublicationLink{Href: href, Type: mime.TypeByExtension(path.Ext(href))})
+		}
+	}
+	for _, f := range e.Files {
+		if len(f.Encodings) == 0 {
+			continue
+		}
+		if strings.HasPrefix(f.Encodings[0], "image/") {
+			pub.Images = append(pub.Images, PublicationLink{Href: f.URL, Type: f.Encodings[0]})
+			continue
+		}
+		link := PublicationLink{Rel: RelAcquisition, Href: f.URL, Type: f.Encodings[0]}
+		if f.Extent > 0 {
+			link.Properties = &LinkProperties{Length: f.Extent}
+		}
+		pub.Links = append(pub.Links, link)
+	}
+
+	return pub
+}
+
+// Write the publication as an indented JSON document to the provided `io.Writer`.
+func (p *Publication) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(p)
+}
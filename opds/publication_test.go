@@ -0,0 +1,104 @@
+package opds_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/opds"
+)
+
+func TestNewPublication(t *testing.T) {
+	ebook := sampleEbook(t)
+	pub := opds.NewPublication(ebook)
+
+	if pub.Metadata.Identifier != "https://www.gutenberg.org/ebooks/999991234" {
+		t.Errorf("unexpected identifier, got '%s'", pub.Metadata.Identifier)
+	}
+	if pub.Metadata.Title != "Great Expectations" || pub.Metadata.Subtitle != "And a subtitle" {
+		t.Errorf("unexpected title, got '%s' and '%s'", pub.Metadata.Title, pub.Metadata.Subtitle)
+	}
+	if len(pub.Metadata.Authors) != 1 || pub.Metadata.Authors[0].Name != "Dickens, Charles" {
+		t.Errorf("unexpected authors, got %+v", pub.Metadata.Authors)
+	}
+	if pub.Metadata.Authors[0].Identifier != "http://www.gutenberg.org/2009/agents/37" {
+		t.Errorf("unexpected author identifier, got '%s'", pub.Metadata.Authors[0].Identifier)
+	}
+
+	epub := findPublicationLink(pub.Links, "application/epub+zip")
+	if epub == nil {
+		t.Fatal("expected an EPUB acquisition link")
+	}
+	if epub.Rel != opds.RelAcquisition {
+		t.Errorf("unexpected EPUB link rel, got '%s'", epub.Rel)
+	}
+	if epub.Properties == nil || epub.Properties.Length == 0 {
+		t.Error("expected the EPUB link to have a length")
+	}
+
+	if len(pub.Images) == 0 {
+		t.Fatal("expected cover images")
+	}
+	if pub.Images[0].Href != "http://www.gutenberg.org/files/999991234/999991234-h/images/cover.jpg" {
+		t.Errorf("unexpected cover image, got '%s'", pub.Images[0].Href)
+	}
+}
+
+func TestNewPublication_Roles(t *testing.T) {
+	ebook := &pgrdf.Ebook{
+		ID:     2,
+		Titles: []string{"Roles"},
+		Creators: []pgrdf.Creator{
+			{ID: 1, Name: "Author, An"},
+			{ID: 2, Name: "Translator, A", Role: pgrdf.RoleTrl},
+			{ID: 3, Name: "Illustrator, An", Role: pgrdf.RoleIll},
+			{ID: 4, Name: "Compiler, A", Role: pgrdf.RoleCom},
+		},
+	}
+	meta := opds.NewPublication(ebook).Metadata
+
+	if len(meta.Authors) != 1 || meta.Authors[0].Name != "Author, An" {
+		t.Errorf("unexpected authors, got %+v", meta.Authors)
+	}
+	if len(meta.Translators) != 1 || meta.Translators[0].Name != "Translator, A" {
+		t.Errorf("unexpected translators, got %+v", meta.Translators)
+	}
+	if len(meta.Illustrators) != 1 || meta.Illustrators[0].Name != "Illustrator, An" {
+		t.Errorf("unexpected illustrators, got %+v", meta.Illustrators)
+	}
+	if len(meta.Contributors) != 1 || meta.Contributors[0].Role != "com" {
+		t.Errorf("unexpected contributors, got %+v", meta.Contributors)
+	}
+}
+
+func TestPublication_Write(t *testing.T) {
+	w := bytes.NewBuffer([]byte{})
+	if err := opds.NewPublication(sampleEbook(t)).Write(w); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(w.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %s", err)
+	}
+	meta, ok := doc["metadata"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected a metadata object")
+	}
+	if meta["@type"] != "http://schema.org/Book" {
+		t.Errorf("unexpected @type, got '%v'", meta["@type"])
+	}
+	if _, ok := meta["author"].([]interface{}); !ok {
+		t.Errorf("expected an author array, got %T", meta["author"])
+	}
+}
+
+func findPublicationLink(links []opds.PublicationLink, kind string) *opds.PublicationLink {
+	for i := range links {
+		if links[i].Type == kind {
+			return &links[i]
+		}
+	}
+	return nil
+}
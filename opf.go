@@ -0,0 +1,232 @@
+package pgrdf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// EPUB package namespaces.
+const (
+	nsOPF = "http://www.idpf.org/2007/opf"
+
+	// OPFIdentifierID is the `id` of the `dc:identifier` element written to
+	// the OPF metadata, for use as the package `unique-identifier`.
+	OPFIdentifierID = "pub-id"
+)
+
+// nameFullFormRE matches the parenthesised full forenames in a PG name, e.g.
+// the "(Frederick John)" of "Snell, F. J. (Frederick John)".
+var nameFullFormRE = regexp.MustCompile(`\s*\([^)]*\)`)
+
+// opfMetadata is the `<metadata>` element of an EPUB 2 or 3 package document.
+type opfMetadata struct {
+	XMLName xml.Name `xml:"metadata"`
+	NsDc    string   `xml:"xmlns:dc,attr"`
+	NsOPF   string   `xml:"xmlns:opf,attr"`
+
+	Identifiers  []opfElement `xml:"dc:identifier"`
+	Titles       []opfElement `xml:"dc:title"`
+	Creators     []opfElement `xml:"dc:creator"`
+	Contributors []opfElement `xml:"dc:contributor"`
+	Languages    []opfElement `xml:"dc:language"`
+	Subjects     []opfElement `xml:"dc:subject"`
+	Descriptions []opfElement `xml:"dc:description"`
+	Publisher    *opfElement  `xml:"dc:publisher,omitempty"`
+	Dates        []opfElement `xml:"dc:date"`
+	Sources      []opfElement `xml:"dc:source"`
+	Rights       *opfElement  `xml:"dc:rights,omitempty"`
+	Metas        []opfMeta    `xml:"meta"`
+}
+
+// opfElement is a Dublin Core element, with the `opf:*` attributes only
+// being used for EPUB 2.
+type opfElement struct {
+	ID     string `xml:"id,attr,omitempty"`
+	Role   string `xml:"opf:role,attr,omitempty"`
+	FileAs string `xml:"opf:file-as,attr,omitempty"`
+	Scheme string `xml:"opf:scheme,attr,omitempty"`
+	Event  string `xml:"opf:event,attr,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+// opfMeta is an EPUB 3 `<meta>` property.
+type opfMeta struct {
+	ID       string `xml:"id,attr,omitempty"`
+	Refines  string `xml:"refines,attr,omitempty"`
+	Property string `xml:"property,attr,omitempty"`
+	Scheme   string `xml:"scheme,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+// WriteOPFMetadata writes the Ebook as the EPUB 3 package `<metadata>` element,
+// ready for inserting into a `content.opf` document.
+//
+// The first title is the main title, with any others refined as subtitles.
+// Creators are written with a `role` refinement using the MARC relator scheme,
+// and a `file-as` refinement of the PG name, e.g. "Dickens, Charles". The
+// identifier has the id OPFIdentifierID.
+func (e *Ebook) WriteOPFMetadata(w io.Writer) error {
+	return writeOPFMetadata(w, e.opf3Metadata())
+}
+
+// WriteOPF2Metadata writes the Ebook as an EPUB 2 compatible package
+// `<metadata>` element, using the `opf:role` and `opf:file-as` attributes.
+func (e *Ebook) WriteOPF2Metadata(w io.Writer) error {
+	return writeOPFMetadata(w, e.opf2Metadata())
+}
+
+func writeOPFMetadata(w io.Writer, metadata *opfMetadata) error {
+	data, err := xml.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (e *Ebook) opf3Metadata() *opfMetadata {
+	m := e.opfCommon()
+
+	for i, title := range e.Titles {
+		id, titleType := "title", "main"
+		if i > 0 {
+			id, titleType = fmt.Sprintf("subtitle-%d", i), "subtitle"
+		}
+		m.Titles = append(m.Titles, opfElement{ID: id, Value: title})
+		m.Metas = append(m.Metas, opfMeta{Refines: "#" + id, Property: "title-type", Value: titleType})
+	}
+
+	for i, c := range e.Creators {
+		id := fmt.Sprintf("creator-%d", i+1)
+		el := opfElement{ID: id, Value: opfDisplayName(c.Name)}
+		if opfIsAuthor(c) {
+			m.Creators = append(m.Creators, el)
+		} else {
+			m.Contributors = append(m.Contributors, el)
+		}
+		m.Metas = append(m.Metas,
+			opfMeta{Refines: "#" + id, Property: "role", Scheme: "marc:relators", Value: string(opfRole(c))},
+			opfMeta{Refines: "#" + id, Property: "file-as", Value: c.Name},
+		)
+	}
+
+	if len(e.ReleaseDate) > 0 {
+		m.Dates = append(m.Dates, opfElement{Value: e.ReleaseDate})
+	}
+	if modified := e.opfModified(); len(modified) > 0 {
+		m.Metas = append(m.Metas, opfMeta{Property: "dcterms:modified", Value: modified})
+	}
+
+	for i, series := range e.Series {
+		id := fmt.Sprintf("series-%d", i+1)
+		m.Metas = append(m.Metas,
+			opfMeta{ID: id, Property: "belongs-to-collection", Value: series},
+			opfMeta{Refines: "#" + id, Property: "collection-type", Value: "series"},
+		)
+	}
+
+	return m
+}
+
+func (e *Ebook) opf2Metadata() *opfMetadata {
+	m := e.opfCommon()
+	m.Identifiers[0].Scheme = "URI"
+
+	if len(e.Titles) > 0 {
+		m.Titles = append(m.Titles, opfElement{Value: strings.Join(e.Titles, ": ")})
+	}
+
+	for _, c := range e.Creators {
+		el := opfElement{Role: string(opfRole(c)), FileAs: c.Name, Value: opfDisplayName(c.Name)}
+		if opfIsAuthor(c) {
+			m.Creators = append(m.Creators, el)
+		} else {
+			m.Contributors = append(m.Contributors, el)
+		}
+	}
+
+	if len(e.ReleaseDate) > 0 {
+		m.Dates = append(m.Dates, opfElement{Event: "publication", Value: e.ReleaseDate})
+	}
+	if modified := e.opfModified(); len(modified) > 0 {
+		m.Dates = append(m.Dates, opfElement{Event: "modification", Value: modified[:10]})
+	}
+
+	return m
+}
+
+// opfCommon returns the metadata elements which are the same for EPUB 2 and 3.
+func (e *Ebook) opfCommon() *opfMetadata {
+	m := &opfMetadata{
+		NsDc:        nsDcElements,
+		NsOPF:       nsOPF,
+		Identifiers: []opfElement{{ID: OPFIdentifierID, Value: e.URL()}},
+	}
+
+	for _, lang := range e.Languages {
+		m.Languages = append(m.Languages, opfElement{Value: lang})
+	}
+	for _, s := range e.Subjects {
+		m.Subjects = append(m.Subjects, opfElement{Value: s.Heading})
+	}
+	if len(e.Summary) > 0 {
+		m.Descriptions = append(m.Descriptions, opfElement{Value: e.Summary})
+	}
+	if len(e.Publisher) > 0 {
+		m.Publisher = &opfElement{Value: e.Publisher}
+	}
+	for _, source := range e.SourceLinks {
+		m.Sources = append(m.Sources, opfElement{Value: source})
+	}
+	if len(e.Copyright) > 0 {
+		m.Rights = &opfElement{Value: e.Copyright}
+	}
+
+	return m
+}
+
+// opfModified returns the most recent file modification date, or the release
+// date, in the `CCYY-MM-DDThh:mm:ssZ` format required by EPUB 3.
+func (e *Ebook) opfModified() string {
+	var latest time.Time
+	for _, f := range e.Files {
+		if t, err := f.ModifiedTime(); err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+	if latest.IsZero() {
+		latest, _ = time.Parse("2006-01-02", e.ReleaseDate)
+	}
+	if latest.IsZero() {
+		return ""
+	}
+	return latest.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+func opfIsAuthor(c Creator) bool {
+	return c.Role == RoleAut || len(c.Role) == 0
+}
+
+func opfRole(c Creator) MarcRelator {
+	if len(c.Role) == 0 {
+		return RoleAut
+	}
+	return c.Role
+}
+
+// opfDisplayName converts a PG name to display order, e.g. "Dickens, Charles"
+// becomes "Charles Dickens". Names with more than one comma, such as those
+// including a title or suffix, are returned unchanged.
+func opfDisplayName(name string) string {
+	parts := strings.Split(nameFullFormRE.ReplaceAllString(name, ""), ",")
+	if len(parts) != 2 {
+		return name
+	}
+	return strings.TrimSpace(parts[1]) + " " + strings.TrimSpace(parts[0])
+}
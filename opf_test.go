@@ -0,0 +1,245 @@
+package pgrdf_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+)
+
+func TestEbook_WriteOPFMetadata(t *testing.T) {
+	ebook := generateEbook()
+	ebook.Titles = append(ebook.Titles, "A Fairy Tale")
+	ebook.AddCreator(pgrdf.Creator{ID: 8, Name: "Tenniel, John", Role: pgrdf.RoleIll})
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteOPFMetadata(w); err != nil {
+		t.Fatalf("error writing OPF metadata: %s", err)
+	}
+	data := w.String()
+
+	expected := []string{
+		`<metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">`,
+		`<dc:identifier id="pub-id">https://www.gutenberg.org/ebooks/11</dc:identifier>`,
+		`<dc:title id="title">Alice&#39;s Adventures in Wonderland</dc:title>`,
+		`<dc:title id="subtitle-1">A Fairy Tale</dc:title>`,
+		`<dc:creator id="creator-1">Lewis Carroll</dc:creator>`,
+		`<dc:contributor id="creator-2">John Tenniel</dc:contributor>`,
+		`<dc:language>en</dc:language>`,
+		`<dc:subject>Fantasy fiction</dc:subject>`,
+		`<dc:date>2008-06-27</dc:date>`,
+		`<dc:rights>Public domain in the USA.</dc:rights>`,
+		`<meta refines="#title" property="title-type">main</meta>`,
+		`<meta refines="#subtitle-1" property="title-type">subtitle</meta>`,
+		`<meta refines="#creator-1" property="role" scheme="marc:relators">aut</meta>`,
+		`<meta refines="#creator-1" property="file-as">Carroll, Lewis</meta>`,
+		`<meta refines="#creator-2" property="role" scheme="marc:relators">ill</meta>`,
+		`<meta property="dcterms:modified">2020-10-12T03:45:53Z</meta>`,
+		`<meta id="series-1" property="belongs-to-collection">Best of Fantasy</meta>`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(data, s) {
+			t.Errorf("expected OPF metadata to contain:\n%s", s)
+		}
+	}
+
+	if err := xml.Unmarshal(w.Bytes(), new(struct{})); err != nil {
+		t.Errorf("expected well-formed XML: %s", err)
+	}
+}
+
+func TestEbook_WriteOPF2Metadata(t *testing.T) {
+	ebook := generateEbook()
+	ebook.AddCreator(pgrdf.Creator{ID: 8, Name: "Snell, F. J. (Frederick John)", Role: pgrdf.RoleTrl})
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteOPF2Metadata(w); err != nil {
+		t.Fatalf("error writing OPF metadata: %s", err)
+	}
+	data := w.String()
+
+	expected := []string{
+		`<dc:identifier id="pub-id" opf:scheme="URI">https://www.gutenberg.org/ebooks/11</dc:identifier>`,
+		`<dc:title>Alice&#39;s Adventures in Wonderland</dc:title>`,
+		`<dc:creator opf:role="aut" opf:file-as="Carroll, Lewis">Lewis Carroll</dc:creator>`,
+		`<dc:contributor opf:role="trl" opf:file-as="Snell, F. J. (Frederick John)">F. J. Snell</dc:contributor>`,
+		`<dc:date opf:event="publication">2008-06-27</dc:date>`,
+		`<dc:date opf:event="modification">2020-10-12</dc:date>`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(data, s) {
+			t.Errorf("expected OPF metadata to contain:\n%s", s)
+		}
+	}
+	if strings.Contains(data, "refines=") {
+		t.Error("expected no EPUB 3 refinements")
+	}
+}
+
+func TestReadOPF(t *testing.T) {
+	ebook, err := pgrdf.ReadOPF(strings.NewReader(opfEPUB3))
+	if err != nil {
+		t.Fatalf("error reading OPF: %s", err)
+	}
+
+	if ebook.ID != 1400 {
+		t.Errorf("unexpected ID, got %d", ebook.ID)
+	}
+	if len(ebook.Titles) != 2 || ebook.Titles[0] != "Great Expectations" || ebook.Titles[1] != "A Novel" {
+		t.Errorf("unexpected titles, got %q", ebook.Titles)
+	}
+	if ebook.ReleaseDate != "1998-07-01" {
+		t.Errorf("unexpected release date, got '%s'", ebook.ReleaseDate)
+	}
+	if len(ebook.Languages) != 1 || ebook.Languages[0] != "en" {
+		t.Errorf("unexpected languages, got %q", ebook.Languages)
+	}
+	if ebook.Copyright != "Public domain in the USA." {
+		t.Errorf("unexpected rights, got '%s'", ebook.Copyright)
+	}
+	if len(ebook.Subjects) != 2 || ebook.Subjects[1].Heading != "PR" || ebook.Subjects[1].Schema != "http://purl.org/dc/terms/LCC" {
+		t.Errorf("unexpected subjects, got %+v", ebook.Subjects)
+	}
+	if len(ebook.BookCovers) != 1 || ebook.BookCovers[0].Filename != "images/cover.jpg" {
+		t.Errorf("unexpected book covers, got %+v", ebook.BookCovers)
+	}
+
+	if len(ebook.Creators) != 2 {
+		t.Fatalf("expected 2 creators, got %d", len(ebook.Creators))
+	}
+	if ebook.Creators[0].Name != "Dickens, Charles" || ebook.Creators[0].Role != pgrdf.RoleAut {
+		t.Errorf("unexpected author, got %+v", ebook.Creators[0])
+	}
+	if ebook.Creators[1].Name != "Stone, Marcus" || ebook.Creators[1].Role != pgrdf.RoleIll {
+		t.Errorf("unexpected illustrator, got %+v", ebook.Creators[1])
+	}
+}
+
+func TestReadOPF_EPUB2(t *testing.T) {
+	ebook, err := pgrdf.ReadOPF(strings.NewReader(opfEPUB2))
+	if err != nil {
+		t.Fatalf("error reading OPF: %s", err)
+	}
+
+	if ebook.ID != 98 {
+		t.Errorf("unexpected ID, got %d", ebook.ID)
+	}
+	if ebook.ISBN != "978-0-14-143960-0" {
+		t.Errorf("unexpected ISBN, got '%s'", ebook.ISBN)
+	}
+	if ebook.ReleaseDate != "1994-01-01" {
+		t.Errorf("unexpected release date, got '%s'", ebook.ReleaseDate)
+	}
+	if len(ebook.Creators) != 2 || ebook.Creators[1].Name != "Browne, Hablot K." || ebook.Creators[1].Role != pgrdf.RoleIll {
+		t.Errorf("unexpected creators, got %+v", ebook.Creators)
+	}
+	if len(ebook.Series) != 1 || ebook.Series[0] != "Dickens Library" {
+		t.Errorf("unexpected series, got %q", ebook.Series)
+	}
+}
+
+func TestReadOPF_WriteOPFMetadata_RoundTrip(t *testing.T) {
+	ebook := generateEbook()
+	ebook.AddCreator(pgrdf.Creator{Name: "Tenniel, John", Role: pgrdf.RoleIll})
+
+	for name, write := range map[string]func(w *bytes.Buffer) error{
+		"EPUB 3": func(w *bytes.Buffer) error { return ebook.WriteOPFMetadata(w) },
+		"EPUB 2": func(w *bytes.Buffer) error { return ebook.WriteOPF2Metadata(w) },
+	} {
+		w := bytes.NewBufferString(`<package xmlns="http://www.idpf.org/2007/opf" unique-identifier="pub-id">`)
+		if err := write(w); err != nil {
+			t.Fatalf("%s: error writing OPF metadata: %s", name, err)
+		}
+		w.WriteString(`</package>`)
+
+		got, err := pgrdf.ReadOPF(w)
+		if err != nil {
+			t.Fatalf("%s: error reading OPF: %s", name, err)
+		}
+		if got.ID != ebook.ID || got.Titles[0] != ebook.Titles[0] || got.ReleaseDate != ebook.ReleaseDate {
+			t.Errorf("%s: unexpected ID/title/date, got %d, %q, '%s'", name, got.ID, got.Titles, got.ReleaseDate)
+		}
+		if len(got.Creators) != 2 || got.Creators[0].Name != "Carroll, Lewis" || got.Creators[1].Role != pgrdf.RoleIll {
+			t.Errorf("%s: unexpected creators, got %+v", name, got.Creators)
+		}
+	}
+}
+
+func TestReadEPUB(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	zw := zip.NewWriter(buf)
+	files := map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": epubContainerXML,
+		"OEBPS/content.opf":      opfEPUB3,
+	}
+	for name, content := range files {
+		f, _ := zw.Create(name)
+		_, _ = f.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("unable to create EPUB: %s", err)
+	}
+
+	ebook, err := pgrdf.ReadEPUB(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading EPUB: %s", err)
+	}
+	if ebook.ID != 1400 {
+		t.Errorf("unexpected ID, got %d", ebook.ID)
+	}
+}
+
+const epubContainerXML = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const opfEPUB3 = `<?xml version="1.0" encoding="utf-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+    <dc:rights>Public domain in the USA.</dc:rights>
+    <dc:identifier id="id">http://www.gutenberg.org/1400</dc:identifier>
+    <dc:creator id="author_0">Charles Dickens</dc:creator>
+    <meta property="file-as" refines="#author_0">Dickens, Charles</meta>
+    <meta property="role" refines="#author_0" scheme="marc:relators">aut</meta>
+    <dc:contributor id="ill_0">Marcus Stone</dc:contributor>
+    <meta property="file-as" refines="#ill_0">Stone, Marcus</meta>
+    <meta property="role" refines="#ill_0" scheme="marc:relators">ill</meta>
+    <dc:title id="subtitle">A Novel</dc:title>
+    <meta property="title-type" refines="#subtitle">subtitle</meta>
+    <dc:title id="title">Great Expectations</dc:title>
+    <dc:language>en</dc:language>
+    <dc:subject>Orphans -- Fiction</dc:subject>
+    <dc:subject id="lcc">PR</dc:subject>
+    <meta property="authority" refines="#lcc">LCC</meta>
+    <dc:date>1998-07-01</dc:date>
+    <meta property="dcterms:modified">2023-01-01T12:00:00Z</meta>
+    <meta name="cover" content="item1"/>
+  </metadata>
+  <manifest>
+    <item id="item1" href="images/cover.jpg" media-type="image/jpeg"/>
+  </manifest>
+</package>
+`
+
+const opfEPUB2 = `<?xml version="1.0" encoding="utf-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0" unique-identifier="id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+    <dc:identifier opf:scheme="ISBN">urn:isbn:978-0-14-143960-0</dc:identifier>
+    <dc:identifier id="id" opf:scheme="URI">http://www.gutenberg.org/ebooks/98</dc:identifier>
+    <dc:title>A Tale of Two Cities</dc:title>
+    <dc:creator opf:file-as="Dickens, Charles" opf:role="aut">Charles Dickens</dc:creator>
+    <dc:contributor opf:file-as="Browne, Hablot K." opf:role="ill">Hablot K. Browne</dc:contributor>
+    <dc:date opf:event="modification">2020-01-01</dc:date>
+    <dc:date opf:event="publication">1994-01-01</dc:date>
+    <meta name="calibre:series" content="Dickens Library"/>
+  </metadata>
+</package>
+`
@@ -0,0 +1,212 @@
+package pgrdf
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mrcook/pgrdf/internal/ns"
+)
+
+// opfEbookIDRE matches the eText ID of a Gutenberg `dc:identifier`, e.g.
+// "http://www.gutenberg.org/1400", "https://www.gutenberg.org/ebooks/1400",
+// or "urn:gutenberg:1400".
+var opfEbookIDRE = regexp.MustCompile(`(?:gutenberg\.org/(?:ebooks/)?|^urn:gutenberg:)(\d+)`)
+
+// opfPackage is the EPUB package document, as read by ReadOPF.
+type opfPackage struct {
+	XMLName          xml.Name        `xml:"http://www.idpf.org/2007/opf package"`
+	UniqueIdentifier string          `xml:"unique-identifier,attr"`
+	Metadata         opfReadMetadata `xml:"http://www.idpf.org/2007/opf metadata"`
+	Items            []opfItem       `xml:"http://www.idpf.org/2007/opf manifest>item"`
+}
+
+// opfReadMetadata keeps all the metadata elements in document order, so the
+// EPUB 3 `meta` refinements can be matched to their elements.
+type opfReadMetadata struct {
+	Elements []opfReadElement `xml:",any"`
+}
+
+type opfReadElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Value   string     `xml:",chardata"`
+}
+
+type opfItem struct {
+	ID         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr"`
+}
+
+// attr returns the value of an attribute by its local name, so both the EPUB 2
+// `opf:role` and any un-namespaced `role` attributes are found.
+func (el opfReadElement) attr(name string) string {
+	for _, a := range el.Attrs {
+		if a.Name.Local == name {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
+
+func (el opfReadElement) value() string {
+	return strings.TrimSpace(el.Value)
+}
+
+// ReadOPF reads the metadata of an EPUB package document (`content.opf`) from
+// the given `io.Reader` and unmarshals it to an Ebook.
+//
+// Both EPUB 2 and EPUB 3 metadata is supported. The ebook ID is taken from a
+// gutenberg.org `dc:identifier`, creator names from their `file-as` value, and
+// roles from the MARC relator codes.
+func ReadOPF(r io.Reader) (*Ebook, error) {
+	var pkg opfPackage
+	if err := xml.NewDecoder(r).Decode(&pkg); err != nil {
+		return nil, err
+	}
+	return opfUnmarshal(&pkg)
+}
+
+// opfUnmarshal will deserialise the OPF package metadata to an Ebook, which is
+// the reverse of opf3Metadata() and opf2Metadata().
+func opfUnmarshal(pkg *opfPackage) (*Ebook, error) {
+	elements := pkg.Metadata.Elements
+	if len(elements) == 0 {
+		return nil, errors.New("no metadata found in OPF")
+	}
+
+	// EPUB 3 refinements, by the id of the element being refined
+	refines := make(map[string]map[string]string)
+	for _, el := range elements {
+		if el.XMLName.Local != "meta" || !strings.HasPrefix(el.attr("refines"), "#") {
+			continue
+		}
+		id := strings.TrimPrefix(el.attr("refines"), "#")
+		if refines[id] == nil {
+			refines[id] = make(map[string]string)
+		}
+		refines[id][el.attr("property")] = el.value()
+	}
+	refinement := func(el opfReadElement, property string) string {
+		if id := el.attr("id"); len(id) > 0 {
+			return refines[id][property]
+		}
+		return ""
+	}
+
+	e := &Ebook{}
+	var titles, subtitles []string
+	var coverID string
+
+	for _, el := range elements {
+		if el.XMLName.Space != nsDcElements {
+			switch el.attr("property") {
+			case "dcterms:issued":
+				if len(e.ReleaseDate) == 0 {
+					e.ReleaseDate = opfDate(el.value())
+				}
+			case "belongs-to-collection":
+				e.Series = append(e.Series, el.value())
+			}
+			switch el.attr("name") {
+			case "calibre:series":
+				e.Series = append(e.Series, el.attr("content"))
+			case "cover":
+				coverID = el.attr("content")
+			}
+			continue
+		}
+
+		value := el.value()
+		if len(value) == 0 {
+			continue
+		}
+
+		switch el.XMLName.Local {
+		case "identifier":
+			if m := opfEbookIDRE.FindStringSubmatch(value); m != nil && (e.ID == 0 || el.attr("id") == pkg.UniqueIdentifier) {
+				e.ID, _ = strconv.Atoi(m[1])
+			} else if strings.HasPrefix(strings.ToLower(value), "urn:isbn:") {
+				e.ISBN = value[len("urn:isbn:"):]
+			}
+		case "title":
+			if refinement(el, "title-type") == "subtitle" {
+				subtitles = append(subtitles, value)
+			} else {
+				titles = append(titles, value)
+			}
+		case "creator", "contributor":
+			e.AddCreator(opfCreator(el, refinement(el, "file-as"), refinement(el, "role")))
+		case "language":
+			e.Languages = append(e.Languages, value)
+		case "subject":
+			schema := ns.DcTerms + "LCSH"
+			if authority := strings.ToUpper(refinement(el, "authority")); authority == "LCC" {
+				schema = ns.DcTerms + "LCC"
+			}
+			e.AddSubject(value, schema)
+		case "description":
+			if len(e.Summary) == 0 {
+				e.Summary = value
+			} else {
+				e.Notes = append(e.Notes, value)
+			}
+		case "publisher":
+			e.Publisher = value
+		case "rights":
+			e.Copyright = value
+		case "source":
+			e.SourceLinks = append(e.SourceLinks, value)
+		case "date":
+			if event := el.attr("event"); event == "" || event == "publication" || event == "issued" {
+				e.ReleaseDate = opfDate(value)
+			}
+		}
+	}
+	e.Titles = splitTitles(append(titles, subtitles...))
+
+	for _, item := range pkg.Items {
+		if item.ID == coverID || strings.Contains(" "+item.Properties+" ", " cover-image ") {
+			e.BookCovers = append(e.BookCovers, BookCover{Filename: item.Href})
+			break
+		}
+	}
+
+	return e, nil
+}
+
+// opfCreator builds a creator from a `dc:creator` or `dc:contributor`, using the
+// EPUB 3 refinements, or otherwise the EPUB 2 `opf:file-as`/`opf:role` attributes.
+func opfCreator(el opfReadElement, fileAs, role string) Creator {
+	if len(fileAs) == 0 {
+		fileAs = el.attr("file-as")
+	}
+	if len(role) == 0 {
+		role = el.attr("role")
+	}
+
+	c := Creator{Name: el.value(), Role: MarcRelator(strings.ToLower(role))}
+	if len(fileAs) > 0 {
+		c.Name = fileAs
+	}
+	if len(c.Role) == 0 {
+		c.Role = RoleAut
+		if el.XMLName.Local == "contributor" {
+			c.Role = RoleCtb
+		}
+	}
+	return c
+}
+
+// opfDate returns the date part of an EPUB date, e.g. "1998-07-01T00:00:00Z".
+func opfDate(date string) string {
+	if len(date) > 10 && date[10] == 'T' {
+		return date[:10]
+	}
+	return date
+}
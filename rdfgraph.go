@@ -0,0 +1,191 @@
+package pgrdf
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mrcook/pgrdf/internal/ns"
+	"github.com/mrcook/pgrdf/internal/triples"
+)
+
+// turtlePrefixes are the namespaces declared in the RDF/XML documents, plus XSD for the datatypes.
+var turtlePrefixes = []triples.Prefix{
+	{Name: "dcterms", IRI: ns.DcTerms},
+	{Name: "pgterms", IRI: ns.PgTerms},
+	{Name: "rdf", IRI: ns.Rdf},
+	{Name: "rdfs", IRI: ns.Rdfs},
+	{Name: "cc", IRI: ns.CC},
+	{Name: "marcrel", IRI: ns.MarcRel},
+	{Name: "dcam", IRI: ns.DcDcam},
+	{Name: "xsd", IRI: ns.Xsd},
+}
+
+// WriteNTriples marshals the Ebook to an RDF graph and writes it to the
+// provided `io.Writer` in the N-Triples format.
+func (e *Ebook) WriteNTriples(w io.Writer) error {
+	return triples.WriteNTriples(w, rdfGraph(e))
+}
+
+// WriteTurtle marshals the Ebook to an RDF graph and writes it to the
+// provided `io.Writer` in the Turtle format.
+func (e *Ebook) WriteTurtle(w io.Writer) error {
+	return triples.WriteTurtle(w, rdfGraph(e), gutenbergBaseURL, turtlePrefixes)
+}
+
+// rdfGraph will serialise an Ebook object to the same RDF graph as described
+// by the RDF/XML document, with the `rdf:Description` nodes as blank nodes.
+// Creators with the `aut` role use `dcterms:creator`, all other roles use the
+// matching `marcrel:*` predicate.
+func rdfGraph(e *Ebook) *triples.Graph {
+	g := &triples.Graph{}
+
+	book := triples.IRI(resolveIRI(fmt.Sprintf("ebooks/%d", e.ID)))
+	g.Add(book, triples.IRI(ns.Rdf+"type"), triples.IRI(ns.PgTerms+"ebook"))
+
+	addLiterals := func(predicate string, values ...string) {
+		for _, v := range values {
+			if len(v) > 0 {
+				g.Add(book, triples.IRI(predicate), triples.Literal(v))
+			}
+		}
+	}
+	addDescription := func(subject triples.Term, predicate string, value triples.Term, memberOf string) {
+		node := g.NewBlank()
+		g.Add(subject, triples.IRI(predicate), node)
+		g.Add(node, triples.IRI(ns.Rdf+"value"), value)
+		if len(memberOf) > 0 {
+			g.Add(node, triples.IRI(ns.DcDcam+"memberOf"), triples.IRI(resolveIRI(memberOf)))
+		}
+	}
+
+	addLiterals(ns.DcTerms+"title", e.Titles...)
+	addLiterals(ns.DcTerms+"alternative", e.AlternateTitles...)
+	addLiterals(ns.DcTerms+"tableOfContents", e.TableOfContents)
+	addLiterals(ns.DcTerms+"publisher", e.Publisher)
+	if e.PublishedYear > 0 {
+		addLiterals(ns.PgTerms+"marc906", strconv.Itoa(e.PublishedYear))
+	}
+	if len(e.ReleaseDate) > 0 {
+		g.Add(book, triples.IRI(ns.DcTerms+"issued"), triples.TypedLiteral(e.ReleaseDate, ns.Xsd+"date"))
+	}
+	addLiterals(ns.PgTerms+"marc520", e.Summary)
+	addLiterals(ns.PgTerms+"marc440", e.Series...)
+	for _, lang := range e.Languages {
+		addDescription(book, ns.DcTerms+"language", triples.TypedLiteral(lang, ns.DcTerms+"RFC4646"), "")
+	}
+	addLiterals(ns.PgTerms+"marc907", e.LanguageDialect)
+	addLiterals(ns.PgTerms+"marc546", e.LanguageNotes...)
+	addLiterals(ns.PgTerms+"marc260", e.PublicationNote)
+	addLiterals(ns.PgTerms+"marc250", e.EditionNote)
+	addLiterals(ns.PgTerms+"marc508", e.ProductionNotes...)
+	g.Add(book, triples.IRI(ns.DcTerms+"license"), triples.IRI(resolveIRI("license")))
+	addLiterals(ns.DcTerms+"rights", e.Copyright)
+	addLiterals(ns.PgTerms+"marc905", e.CopyrightClearanceCode)
+	if len(e.BookType) > 0 {
+		addDescription(book, ns.DcTerms+"type", triples.Literal(string(e.BookType)), ns.DcTerms+"DCMIType")
+	}
+	addLiterals(ns.DcTerms+"description", e.Notes...)
+	addLiterals(ns.PgTerms+"marc300", e.PhysicalDescriptionNote)
+	addLiterals(ns.PgTerms+"marc904", e.SourceLinks...)
+	addLiterals(ns.PgTerms+"marc010", e.LCCN)
+	addLiterals(ns.PgTerms+"marc020", e.ISBN)
+	for _, cover := range e.BookCovers {
+		addLiterals(ns.PgTerms+"marc901", cover.String())
+	}
+	addLiterals(ns.PgTerms+"marc902", e.TitlePageImage)
+	addLiterals(ns.PgTerms+"marc903", e.BackCover)
+
+	agents := map[int]bool{}
+	for _, c := range e.Creators {
+		predicate := ns.DcTerms + "creator"
+		if len(c.Role) > 0 && c.Role != RoleAut {
+			predicate = ns.MarcRel + string(c.Role)
+		}
+
+		agent := g.NewBlank()
+		if c.ID > 0 {
+			agent = triples.IRI(resolveIRI(fmt.Sprintf("2009/agents/%d", c.ID)))
+		}
+		g.Add(book, triples.IRI(predicate), agent)
+
+		// agent details are only added once, even when they have multiple roles.
+		if agents[c.ID] && c.ID > 0 {
+			continue
+		}
+		if len(c.Name) == 0 && c.ID > 0 {
+			continue // a reference only, with the details found in another RDF
+		}
+		agents[c.ID] = true
+
+		g.Add(agent, triples.IRI(ns.Rdf+"type"), triples.IRI(ns.PgTerms+"agent"))
+		if len(c.Name) > 0 {
+			g.Add(agent, triples.IRI(ns.PgTerms+"name"), triples.Literal(c.Name))
+		}
+		for _, alias := range c.Aliases {
+			g.Add(agent, triples.IRI(ns.PgTerms+"alias"), triples.Literal(alias))
+		}
+		if c.Born != 0 {
+			g.Add(agent, triples.IRI(ns.PgTerms+"birthdate"), triples.TypedLiteral(strconv.Itoa(c.Born), ns.Xsd+"integer"))
+		}
+		if c.Died != 0 {
+			g.Add(agent, triples.IRI(ns.PgTerms+"deathdate"), triples.TypedLiteral(strconv.Itoa(c.Died), ns.Xsd+"integer"))
+		}
+		for _, webpage := range c.WebPages {
+			g.Add(agent, triples.IRI(ns.PgTerms+"webpage"), triples.IRI(resolveIRI(webpage)))
+		}
+	}
+
+	for _, s := range e.Subjects {
+		addDescription(book, ns.DcTerms+"subject", triples.Literal(s.Heading), s.Schema)
+	}
+
+	for _, f := range e.Files {
+		file := triples.IRI(resolveIRI(f.URL))
+		g.Add(book, triples.IRI(ns.DcTerms+"hasFormat"), file)
+		g.Add(file, triples.IRI(ns.Rdf+"type"), triples.IRI(ns.PgTerms+"file"))
+		g.Add(file, triples.IRI(ns.DcTerms+"extent"), triples.TypedLiteral(strconv.Itoa(f.Extent), ns.Xsd+"integer"))
+		if len(f.Modified) > 0 {
+			g.Add(file, triples.IRI(ns.DcTerms+"modified"), triples.TypedLiteral(f.Modified, ns.Xsd+"dateTime"))
+		}
+		g.Add(file, triples.IRI(ns.DcTerms+"isFormatOf"), book)
+		for _, enc := range f.Encodings {
+			addDescription(file, ns.DcTerms+"format", triples.TypedLiteral(enc, ns.DcTerms+"IMT"), ns.DcTerms+"IMT")
+		}
+	}
+
+	for _, s := range e.Bookshelves {
+		addDescription(book, ns.PgTerms+"bookshelf", triples.Literal(s.Name), s.Resource)
+	}
+
+	g.Add(book, triples.IRI(ns.PgTerms+"downloads"), triples.TypedLiteral(strconv.Itoa(e.Downloads), ns.Xsd+"integer"))
+
+	for _, l := range e.AuthorLinks {
+		g.Add(triples.IRI(resolveIRI(l.URL)), triples.IRI(ns.DcTerms+"description"), triples.Literal(l.Description))
+	}
+
+	if len(e.CCComment) > 0 || len(e.CCLicense) > 0 {
+		work := g.NewBlank()
+		g.Add(work, triples.IRI(ns.Rdf+"type"), triples.IRI(ns.CC+"Work"))
+		if len(e.CCComment) > 0 {
+			g.Add(work, triples.IRI(ns.Rdfs+"comment"), triples.Literal(e.CCComment))
+		}
+		if len(e.CCLicense) > 0 {
+			g.Add(work, triples.IRI(ns.CC+"license"), triples.IRI(resolveIRI(e.CCLicense)))
+		}
+	}
+
+	return g
+}
+
+// resolveIRI resolves a relative RDF resource, e.g. `ebooks/11`, against
+// the gutenberg.org base URL. Absolute IRIs are returned unchanged.
+func resolveIRI(resource string) string {
+	u, err := url.Parse(resource)
+	if err != nil || u.IsAbs() {
+		return resource
+	}
+	return gutenbergBaseURL + strings.TrimPrefix(resource, "/")
+}
@@ -0,0 +1,63 @@
+package pgrdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEbook_WriteNTriples(t *testing.T) {
+	ebook := getEbookFromSampleRdf(t)
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteNTriples(w); err != nil {
+		t.Fatalf("error writing N-Triples: %s", err)
+	}
+	data := w.String()
+
+	expected := []string{
+		`<http://www.gutenberg.org/ebooks/999991234> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.gutenberg.org/2009/pgterms/ebook> .`,
+		`<http://www.gutenberg.org/ebooks/999991234> <http://purl.org/dc/terms/title> "Great Expectations" .`,
+		`<http://www.gutenberg.org/ebooks/999991234> <http://purl.org/dc/terms/issued> "1998-07-01"^^<http://www.w3.org/2001/XMLSchema#date> .`,
+		`<http://www.gutenberg.org/ebooks/999991234> <http://purl.org/dc/terms/creator> <http://www.gutenberg.org/2009/agents/37> .`,
+		`<http://www.gutenberg.org/ebooks/999991234> <http://id.loc.gov/vocabulary/relators/ill> <http://www.gutenberg.org/2009/agents/9473> .`,
+		`<http://www.gutenberg.org/2009/agents/37> <http://www.gutenberg.org/2009/pgterms/birthdate> "1812"^^<http://www.w3.org/2001/XMLSchema#integer> .`,
+		`<http://www.gutenberg.org/2009/agents/37> <http://www.gutenberg.org/2009/pgterms/webpage> <https://en.wikipedia.org/wiki/Charles_Dickens> .`,
+		`<http://www.gutenberg.org/2009/pgterms/Bookshelf> .`,
+		`<https://en.wikipedia.org/wiki/Charles_Dickens> <http://purl.org/dc/terms/description> "en.wikipedia" .`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(data, line) {
+			t.Errorf("expected N-Triples to contain:\n%s", line)
+		}
+	}
+
+	for i, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		if !strings.HasSuffix(line, " .") {
+			t.Errorf("line %d is not a valid triple: %s", i+1, line)
+		}
+	}
+}
+
+func TestEbook_WriteTurtle(t *testing.T) {
+	ebook := generateEbook()
+
+	w := bytes.NewBuffer([]byte{})
+	if err := ebook.WriteTurtle(w); err != nil {
+		t.Fatalf("error writing Turtle: %s", err)
+	}
+	data := w.String()
+
+	expected := []string{
+		"@base <http://www.gutenberg.org/> .",
+		"@prefix marcrel: <http://id.loc.gov/vocabulary/relators/> .",
+		"<ebooks/11>\n    a pgterms:ebook ;\n    dcterms:title \"Alice's Adventures in Wonderland\" ;",
+		"    dcterms:subject [\n        rdf:value \"Fantasy fiction\" ;\n        dcam:memberOf dcterms:LCSH\n    ] ;",
+		"<2009/agents/7>\n    a pgterms:agent ;\n    pgterms:name \"Carroll, Lewis\" ;",
+	}
+	for _, s := range expected {
+		if !strings.Contains(data, s) {
+			t.Errorf("expected Turtle to contain:\n%s\n\ngot:\n%s", s, data)
+		}
+	}
+}
@@ -5,20 +5,24 @@ import (
 
 	"github.com/mrcook/pgrdf/internal/marshaler"
 	"github.com/mrcook/pgrdf/internal/nodeid"
+	"github.com/mrcook/pgrdf/internal/ns"
 )
 
+// gutenbergBaseURL is the `xml:base` used for all relative RDF resources.
+const gutenbergBaseURL = "http://www.gutenberg.org/"
+
 // rdfMarshal will serialise an Ebook object to a RDF object.
 func rdfMarshal(e *Ebook) *marshaler.RDF {
 	rdf := &marshaler.RDF{
 		// TODO: only add them if they're needed.
-		NsBase:    "http://www.gutenberg.org/",
-		NsDcTerms: "http://purl.org/dc/terms/",
-		NsPgTerms: "http://www.gutenberg.org/2009/pgterms/",
-		NsRdf:     "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
-		NsRdfs:    "http://www.w3.org/2000/01/rdf-schema#",
-		NsCC:      "http://web.resource.org/cc/",
-		NsDcDcam:  "http://purl.org/dc/dcam/",
-		NsMarcRel: "http://id.loc.gov/vocabulary/relators/",
+		NsBase:    gutenbergBaseURL,
+		NsDcTerms: ns.DcTerms,
+		NsPgTerms: ns.PgTerms,
+		NsRdf:     ns.Rdf,
+		NsRdfs:    ns.Rdfs,
+		NsCC:      ns.CC,
+		NsDcDcam:  ns.DcDcam,
+		NsMarcRel: ns.MarcRel,
 
 		Ebook: marshaler.Ebook{
 			About:           fmt.Sprintf("ebooks/%d", e.ID),
@@ -54,7 +58,7 @@ func rdfMarshal(e *Ebook) *marshaler.RDF {
 			SourceLinks:             e.SourceLinks,
 			LCCN:                    e.LCCN,
 			ISBN:                    e.ISBN,
-			BookCoverImages:         e.BookCovers,
+			BookCoverImages:         nil,
 			TitlePageImage:          e.TitlePageImage,
 			BackCoverImage:          e.BackCover,
 			Creators:                nil,
@@ -73,6 +77,10 @@ func rdfMarshal(e *Ebook) *marshaler.RDF {
 		},
 	}
 
+	for _, cover := range e.BookCovers {
+		rdf.Ebook.BookCoverImages = append(rdf.Ebook.BookCoverImages, cover.String())
+	}
+
 	for _, lang := range e.Languages {
 		rdf.Ebook.Languages = append(rdf.Ebook.Languages, marshaler.Language{
 			Description: marshaler.Description{
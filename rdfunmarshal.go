@@ -1,203 +1,205 @@
 package pgrdf
 
 import (
+	"errors"
 	"io"
+	"strconv"
 	"strings"
 
-	"github.com/mrcook/pgrdf/internal/unmarshaler"
+	"github.com/mrcook/pgrdf/internal/ns"
+	"github.com/mrcook/pgrdf/internal/triples"
 )
 
 // rdfUnmarshal will deserialise an RDF object to an Ebook object.
+//
+// The RDF/XML is first parsed to an RDF graph, so documents which do not use
+// the exact Project Gutenberg element nesting, e.g. those using typed nodes,
+// `rdf:parseType="Resource"`, or `rdf:nodeID` references, are read correctly.
 func rdfUnmarshal(r io.Reader) (*Ebook, error) {
-	rdf, err := unmarshaler.New(r)
+	g, err := triples.ParseRDFXML(r, gutenbergBaseURL)
 	if err != nil {
 		return nil, err
 	}
+	return ebookFromGraph(g)
+}
+
+// ebookFromGraph builds an Ebook from the RDF graph of a Project Gutenberg
+// RDF document. This is the reverse of rdfGraph().
+func ebookFromGraph(g *triples.Graph) (*Ebook, error) {
+	book, ok := findEbookNode(g)
+	if !ok {
+		return nil, errors.New("no pgterms:ebook found in RDF")
+	}
+
+	gr := graphReader{g: g}
 
 	ebook := &Ebook{
-		ID:                      rdf.Ebook.Id(),
-		Titles:                  splitTitles(rdf.Ebook.Titles),
-		AlternateTitles:         splitTitles(rdf.Ebook.Alternatives),
-		TableOfContents:         rdf.Ebook.TableOfContents,
-		Publisher:               rdf.Ebook.Publisher,
-		PublishedYear:           rdf.Ebook.PublishedYear,
-		ReleaseDate:             rdf.Ebook.Issued.Value,
-		Summary:                 rdf.Ebook.Summary,
-		Series:                  rdf.Ebook.Series,
+		ID:                      idFromIRI(book.Value),
+		Titles:                  splitTitles(gr.values(book, ns.DcTerms+"title")),
+		AlternateTitles:         splitTitles(gr.values(book, ns.DcTerms+"alternative")),
+		TableOfContents:         gr.value(book, ns.DcTerms+"tableOfContents"),
+		Publisher:               gr.value(book, ns.DcTerms+"publisher"),
+		PublishedYear:           gr.int(book, ns.PgTerms+"marc906"),
+		ReleaseDate:             gr.value(book, ns.DcTerms+"issued"),
+		Summary:                 gr.value(book, ns.PgTerms+"marc520"),
+		Series:                  gr.values(book, ns.PgTerms+"marc440"),
 		Languages:               nil,
-		LanguageDialect:         rdf.Ebook.LanguageDialect,
-		LanguageNotes:           rdf.Ebook.LanguageNotes,
-		PublicationNote:         rdf.Ebook.PublicationNote,
-		EditionNote:             rdf.Ebook.EditionNote,
-		ProductionNotes:         rdf.Ebook.ProductionNotes,
-		Copyright:               rdf.Ebook.Rights,
-		CopyrightClearanceCode:  rdf.Ebook.DpClearanceCode,
+		LanguageDialect:         gr.value(book, ns.PgTerms+"marc907"),
+		LanguageNotes:           gr.values(book, ns.PgTerms+"marc546"),
+		PublicationNote:         gr.value(book, ns.PgTerms+"marc260"),
+		EditionNote:             gr.value(book, ns.PgTerms+"marc250"),
+		ProductionNotes:         gr.values(book, ns.PgTerms+"marc508"),
+		Copyright:               gr.value(book, ns.DcTerms+"rights"),
+		CopyrightClearanceCode:  gr.value(book, ns.PgTerms+"marc905"),
 		BookType:                "",
-		Notes:                   rdf.Ebook.Descriptions,
-		PhysicalDescriptionNote: rdf.Ebook.PhysicalDescriptionNote,
-		SourceLinks:             rdf.Ebook.SourceLinks,
-		LCCN:                    rdf.Ebook.LCCN,
-		ISBN:                    rdf.Ebook.ISBN,
+		Notes:                   gr.values(book, ns.DcTerms+"description"),
+		PhysicalDescriptionNote: gr.value(book, ns.PgTerms+"marc300"),
+		SourceLinks:             gr.values(book, ns.PgTerms+"marc904"),
+		LCCN:                    gr.value(book, ns.PgTerms+"marc010"),
+		ISBN:                    gr.value(book, ns.PgTerms+"marc020"),
 		BookCovers:              nil,
-		TitlePageImage:          rdf.Ebook.TitlePageImage,
-		BackCover:               rdf.Ebook.BackCoverImage,
+		TitlePageImage:          gr.value(book, ns.PgTerms+"marc902"),
+		BackCover:               gr.value(book, ns.PgTerms+"marc903"),
 		Creators:                nil,
 		Subjects:                nil,
 		Files:                   nil,
 		Bookshelves:             nil,
-		Downloads:               rdf.Ebook.Downloads.Value,
+		Downloads:               gr.int(book, ns.PgTerms+"downloads"),
 		AuthorLinks:             nil,
-		CCComment:               rdf.Work.Comment,
-		CCLicense:               rdf.Work.License.Resource,
 	}
-	if rdf.Ebook.Type.Description.Value != nil {
-		ebook.SetBookType(rdf.Ebook.Type.Description.Value.Data)
+	if bookType := g.Object(book, ns.DcTerms+"type"); !bookType.IsZero() {
+		ebook.SetBookType(lastSegment(gr.termValue(bookType)))
 	}
 
-	for _, cover := range rdf.Ebook.BookCoverImages {
-		ebook.BookCovers = append(ebook.BookCovers, bookCoverFilename(cover))
+	for _, cover := range gr.values(book, ns.PgTerms+"marc901") {
+		ebook.BookCovers = append(ebook.BookCovers, NewBookCover(cover))
 	}
 
-	for _, lang := range rdf.Ebook.Languages {
-		// NOTE: this should never happen, but let's check for nil anyway
-		if lang.Description.Value != nil {
-			ebook.Languages = append(ebook.Languages, lang.Description.Value.Data)
+	for _, lang := range g.Objects(book, ns.DcTerms+"language") {
+		// language IRIs, e.g. `http://id.loc.gov/vocabulary/iso639-1/en`, only need the code
+		ebook.Languages = append(ebook.Languages, lastSegment(gr.termValue(lang)))
+	}
+
+	// authors are added first, followed by all other MARC relator roles.
+	for _, agent := range g.Objects(book, ns.DcTerms+"creator") {
+		ebook.AddCreator(gr.creator(agent, RoleAut))
+	}
+	for _, t := range g.Outgoing(book) {
+		if strings.HasPrefix(t.Predicate.Value, ns.MarcRel) {
+			role := MarcRelator(strings.TrimPrefix(t.Predicate.Value, ns.MarcRel))
+			ebook.AddCreator(gr.creator(t.Object, role))
 		}
 	}
 
-	for _, l := range rdf.Descriptions {
-		ebook.AddAuthorLink(l.Description, l.About)
+	for _, s := range g.Objects(book, ns.DcTerms+"subject") {
+		ebook.AddSubject(gr.termValue(s), g.Object(s, ns.DcDcam+"memberOf").Value)
 	}
 
-	for _, c := range rdf.Ebook.Creators {
-		creator := Creator{
-			ID:      c.AgentId(),
-			Name:    c.Agent.Name,
-			Aliases: c.Agent.Aliases,
-			Role:    RoleAut,
-		}
-		if c.Agent.BirthYear != nil {
-			creator.Born = c.Agent.BirthYear.Value
-		}
-		if c.Agent.DeathYear != nil {
-			creator.Died = c.Agent.DeathYear.Value
+	for _, f := range g.Objects(book, ns.DcTerms+"hasFormat") {
+		file := File{
+			URL:      f.Value,
+			Extent:   gr.int(f, ns.DcTerms+"extent"),
+			Modified: gr.value(f, ns.DcTerms+"modified"),
 		}
-		for _, webpage := range c.Agent.Webpages {
-			creator.WebPages = append(creator.WebPages, webpage.Resource)
+		for _, format := range g.Objects(f, ns.DcTerms+"format") {
+			file.AddEncoding(gr.termValue(format))
 		}
-		ebook.AddCreator(creator)
+		ebook.AddBookFile(file)
 	}
 
-	for _, rel := range rdf.Ebook.RelAdapters {
-		addRelatorToCreators(ebook, rel, RoleAdp)
-	}
-	for _, rel := range rdf.Ebook.RelAfterwords {
-		addRelatorToCreators(ebook, rel, RoleAft)
-	}
-	for _, rel := range rdf.Ebook.RelAnnotators {
-		addRelatorToCreators(ebook, rel, RoleAnn)
-	}
-	for _, rel := range rdf.Ebook.RelArrangers {
-		addRelatorToCreators(ebook, rel, RoleArr)
-	}
-	for _, rel := range rdf.Ebook.RelArtists {
-		addRelatorToCreators(ebook, rel, RoleArt)
-	}
-	for _, rel := range rdf.Ebook.RelIntroductions {
-		addRelatorToCreators(ebook, rel, RoleAui)
+	for _, s := range g.Objects(book, ns.PgTerms+"bookshelf") {
+		resource := g.Object(s, ns.DcDcam+"memberOf").Value
+		ebook.AddBookshelf(gr.termValue(s), strings.TrimPrefix(resource, gutenbergBaseURL))
 	}
-	for _, rel := range rdf.Ebook.RelCommentators {
-		addRelatorToCreators(ebook, rel, RoleCmm)
-	}
-	for _, rel := range rdf.Ebook.RelComposers {
-		addRelatorToCreators(ebook, rel, RoleCmp)
-	}
-	for _, rel := range rdf.Ebook.RelConductors {
-		addRelatorToCreators(ebook, rel, RoleCnd)
-	}
-	for _, rel := range rdf.Ebook.RelCompilers {
-		addRelatorToCreators(ebook, rel, RoleCom)
-	}
-	for _, rel := range rdf.Ebook.RelContributors {
-		addRelatorToCreators(ebook, rel, RoleCtb)
-	}
-	for _, rel := range rdf.Ebook.RelDubious {
-		addRelatorToCreators(ebook, rel, RoleDub)
-	}
-	for _, rel := range rdf.Ebook.RelEditors {
-		addRelatorToCreators(ebook, rel, RoleEdt)
-	}
-	for _, rel := range rdf.Ebook.RelEngravers {
-		addRelatorToCreators(ebook, rel, RoleEgr)
-	}
-	for _, rel := range rdf.Ebook.RelIllustrators {
-		addRelatorToCreators(ebook, rel, RoleIll)
-	}
-	for _, rel := range rdf.Ebook.RelLibrettists {
-		addRelatorToCreators(ebook, rel, RoleLbt)
-	}
-	for _, rel := range rdf.Ebook.RelOther {
-		addRelatorToCreators(ebook, rel, RoleOth)
-	}
-	for _, rel := range rdf.Ebook.RelPublishers {
-		addRelatorToCreators(ebook, rel, RolePbl)
+
+	// author links are descriptions of any resource other than the ebook.
+	for _, t := range g.Triples {
+		if t.Predicate.Value == ns.DcTerms+"description" && t.Subject.IsIRI() && t.Subject != book {
+			ebook.AddAuthorLink(gr.termValue(t.Object), t.Subject.Value)
+		}
 	}
-	for _, rel := range rdf.Ebook.RelPhotographers {
-		addRelatorToCreators(ebook, rel, RolePht)
+
+	if works := g.Subjects(ns.Rdf+"type", triples.IRI(ns.CC+"Work")); len(works) > 0 {
+		ebook.CCComment = gr.value(works[0], ns.Rdfs+"comment")
+		ebook.CCLicense = g.Object(works[0], ns.CC+"license").Value
 	}
-	for _, rel := range rdf.Ebook.RelPerformers {
-		addRelatorToCreators(ebook, rel, RolePrf)
+
+	return ebook, nil
+}
+
+// findEbookNode returns the `pgterms:ebook` node, falling back to the first
+// node with a `dcterms:title` when no node has been given that type.
+func findEbookNode(g *triples.Graph) (triples.Term, bool) {
+	if books := g.Subjects(ns.Rdf+"type", triples.IRI(ns.PgTerms+"ebook")); len(books) > 0 {
+		return books[0], true
 	}
-	for _, rel := range rdf.Ebook.RelPrinters {
-		addRelatorToCreators(ebook, rel, RolePrt)
+	for _, t := range g.Triples {
+		if t.Predicate.Value == ns.DcTerms+"title" {
+			return t.Subject, true
+		}
 	}
-	for _, rel := range rdf.Ebook.RelResearchers {
-		addRelatorToCreators(ebook, rel, RoleRes)
+	return triples.Term{}, false
+}
+
+// graphReader contains helpers for reading values from the graph.
+type graphReader struct {
+	g *triples.Graph
+}
+
+// termValue returns the value of a literal, or of the `rdf:value` for a node,
+// e.g. `<rdf:Description><rdf:value>`. Otherwise, the IRI is returned.
+func (gr graphReader) termValue(term triples.Term) string {
+	if term.IsLiteral() {
+		return term.Value
 	}
-	for _, rel := range rdf.Ebook.RelTranscribers {
-		addRelatorToCreators(ebook, rel, RoleTrc)
+	if value := gr.g.Object(term, ns.Rdf+"value"); !value.IsZero() {
+		return gr.termValue(value)
 	}
-	for _, rel := range rdf.Ebook.RelTranslators {
-		addRelatorToCreators(ebook, rel, RoleTrl)
+	if term.IsIRI() {
+		return term.Value
 	}
+	return ""
+}
 
-	// NOTE: this should be deprecated
-	for _, rel := range rdf.Ebook.RelCollaborators {
-		addRelatorToCreators(ebook, rel, RoleClb)
+func (gr graphReader) value(subject triples.Term, predicate string) string {
+	object := gr.g.Object(subject, predicate)
+	if object.IsZero() {
+		return ""
 	}
+	return gr.termValue(object)
+}
 
-	for _, rel := range rdf.Ebook.RelUnknown {
-		addRelatorToCreators(ebook, rel, RoleUnk)
+func (gr graphReader) values(subject triples.Term, predicate string) []string {
+	var values []string
+	for _, object := range gr.g.Objects(subject, predicate) {
+		values = append(values, gr.termValue(object))
 	}
+	return values
+}
 
-	for _, s := range rdf.Ebook.Subjects {
-		if s.Description.Value == nil || s.Description.MemberOf == nil {
-			continue // NOTE: this should never happen, but let's check for nil anyway
-		}
-		ebook.AddSubject(s.Description.Value.Data, s.Description.MemberOf.Resource)
+// int values which are not a valid number, e.g. marc906 "Various", return 0.
+func (gr graphReader) int(subject triples.Term, predicate string) int {
+	i, _ := strconv.Atoi(strings.TrimSpace(gr.value(subject, predicate)))
+	return i
+}
+
+// creator builds a Creator from an agent node with the given role. The agent
+// details are optional; MARC relators may only reference the agent ID.
+func (gr graphReader) creator(agent triples.Term, role MarcRelator) Creator {
+	creator := Creator{
+		Name:    gr.value(agent, ns.PgTerms+"name"),
+		Aliases: gr.values(agent, ns.PgTerms+"alias"),
+		Born:    gr.int(agent, ns.PgTerms+"birthdate"),
+		Died:    gr.int(agent, ns.PgTerms+"deathdate"),
+		Role:    role,
 	}
-	for _, f := range rdf.Ebook.HasFormats {
-		file := File{
-			URL:      f.File.About,
-			Extent:   f.File.Extent.Value,
-			Modified: f.File.Modified.Value,
-		}
-		for _, f := range f.File.Formats {
-			if f.Description.Value == nil {
-				continue // NOTE: this should never happen, but let's check for nil anyway
-			}
-			file.AddEncoding(f.Description.Value.Data)
-		}
-		ebook.AddBookFile(file)
+	if agent.IsIRI() {
+		creator.ID = idFromIRI(agent.Value)
 	}
-	for _, s := range rdf.Ebook.Bookshelves {
-		if s.Description.Value == nil || s.Description.MemberOf == nil {
-			continue // NOTE: this should never happen, but let's check for nil anyway
-		}
-		ebook.AddBookshelf(s.Description.Value.Data, s.Description.MemberOf.Resource)
+	for _, webpage := range gr.g.Objects(agent, ns.PgTerms+"webpage") {
+		creator.WebPages = append(creator.WebPages, webpage.Value)
 	}
-
-	return ebook, nil
+	return creator
 }
 
 func splitTitles(titles []string) []string {
@@ -214,36 +216,15 @@ func splitTitles(titles []string) []string {
 	return newTitles
 }
 
-// Extract the book cover filename from the file path.
-// marc901 tags contain a book cover filename from the HTML version of the ebook.
-func bookCoverFilename(cover string) string {
-	parts := strings.Split(cover, "-h")
-	cover = parts[len(parts)-1]
-	cover = strings.TrimPrefix(cover, "/")
-	return cover
+// idFromIRI extracts the ID from an IRI, e.g. `http://www.gutenberg.org/2009/agents/7`.
+func idFromIRI(iri string) int {
+	id, _ := strconv.Atoi(lastSegment(iri))
+	return id
 }
 
-// addRelatorToCreators appends a MARC relator to the creators list with the given role and agent (if present).
-func addRelatorToCreators(e *Ebook, relator unmarshaler.MarcRelator, role MarcRelator) {
-	creator := Creator{
-		ID:   relator.AgentId(),
-		Role: role,
-	}
-
-	if relator.Agent != nil {
-		creator.Name = relator.Agent.Name
-		creator.Aliases = relator.Agent.Aliases
-
-		if relator.Agent.BirthYear != nil {
-			creator.Born = relator.Agent.BirthYear.Value
-		}
-		if relator.Agent.DeathYear != nil {
-			creator.Died = relator.Agent.DeathYear.Value
-		}
-		for _, webpage := range relator.Agent.Webpages {
-			creator.WebPages = append(creator.WebPages, webpage.Resource)
-		}
-	}
-
-	e.AddCreator(creator)
+// lastSegment returns the last path segment of an IRI, or the value itself
+// when it is not an IRI.
+func lastSegment(value string) string {
+	parts := strings.Split(value, "/")
+	return parts[len(parts)-1]
 }
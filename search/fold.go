@@ -0,0 +1,67 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldTable maps the accented Latin letters to their unaccented forms.
+var foldTable = map[rune]string{}
+
+func init() {
+	for _, f := range []struct{ from, to string }{
+		{"àáâãäåāăą", "a"},
+		{"çćĉċč", "c"},
+		{"ďđ", "d"},
+		{"èéêëēĕėęě", "e"},
+		{"ĝğġģ", "g"},
+		{"ĥħ", "h"},
+		{"ìíîïĩīĭįı", "i"},
+		{"ĵ", "j"},
+		{"ķ", "k"},
+		{"ĺļľŀł", "l"},
+		{"ñńņňŉ", "n"},
+		{"òóôõöøōŏő", "o"},
+		{"ŕŗř", "r"},
+		{"śŝşšș", "s"},
+		{"ţťŧț", "t"},
+		{"ùúûüũūŭůűų", "u"},
+		{"ŵ", "w"},
+		{"ýÿŷ", "y"},
+		{"źżž", "z"},
+	} {
+		for _, r := range f.from {
+			foldTable[r] = f.to
+		}
+	}
+	foldTable['æ'] = "ae"
+	foldTable['œ'] = "oe"
+	foldTable['ß'] = "ss"
+	foldTable['þ'] = "th"
+	foldTable['ð'] = "d"
+}
+
+// Fold lowercases the text and removes the diacritics from Latin letters,
+// e.g. "Les Misérables" becomes "les miserables".
+func Fold(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		r = unicode.ToLower(r)
+		if unicode.Is(unicode.Mn, r) {
+			continue // combining marks
+		}
+		if f, ok := foldTable[r]; ok {
+			b.WriteString(f)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Tokenize splits the text into folded words, see Fold.
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
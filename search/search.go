@@ -0,0 +1,412 @@
+// Package search is a full-text search index over the ebook metadata: the
+// titles, alternate titles, creator names and aliases, subjects, and
+// summaries. Words are matched with their diacritics removed, and the ranking
+// can include the number of downloads as a popularity boost.
+package search
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mrcook/pgrdf"
+)
+
+// Field weights, with a word in the title counting three times as much as
+// the same word in the summary.
+const (
+	WeightTitle          = 3.0
+	WeightAlternateTitle = 2.0
+	WeightCreator        = 2.0
+	WeightSubject        = 1.5
+	WeightSummary        = 1.0
+)
+
+// indexVersion is the version of the serialised index format.
+const indexVersion = 1
+
+// compactMin is the number of removed documents kept before the index is
+// compacted, once they are also more than half of all documents.
+const compactMin = 1000
+
+// Index is an inverted index of ebook metadata.
+//
+// An Index is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     []document
+	ids      map[int]int // eText ID => docs index
+	postings map[string][]posting
+	docTerms map[int][]string // docs index => terms, for removing postings
+	deleted  int
+
+	// terms are the sorted terms for prefix matching, built when first
+	// needed under termsMu, so searches only need the read lock of mu. The
+	// terms are reset to nil when changed, under the write lock of mu.
+	termsMu sync.Mutex
+	terms   []string
+}
+
+// document is an indexed ebook. The fields are exported for gob encoding.
+type document struct {
+	ID        int
+	Title     string
+	Downloads int
+	Deleted   bool
+}
+
+// posting is a document using a term, with the sum of the field weights of
+// every use of the term.
+type posting struct {
+	Doc    int
+	Weight float64
+}
+
+// Result is a matching ebook of a Search.
+type Result struct {
+	ID        int
+	Title     string
+	Downloads int
+	Score     float64
+}
+
+// Options for a Search.
+type Options struct {
+	// Limit of results returned, with zero returning all matches.
+	Limit int
+
+	// Prefix matches the last query word as a prefix, e.g. "dick" matches
+	// "dickens", as wanted for search-as-you-type.
+	Prefix bool
+
+	// DownloadsBoost increases the score of popular ebooks, by multiplying
+	// the score with `1 + DownloadsBoost * log(1 + downloads)`. Zero ranks
+	// the results on their metadata only.
+	DownloadsBoost float64
+}
+
+// New returns an index of the given ebooks.
+func New(ebooks ...*pgrdf.Ebook) *Index {
+	idx := &Index{
+		ids:      make(map[int]int),
+		postings: make(map[string][]posting),
+		docTerms: make(map[int][]string),
+	}
+	for _, e := range ebooks {
+		idx.Add(e)
+	}
+	return idx
+}
+
+// Add the ebook to the index, replacing any ebook with the same eText ID.
+func (idx *Index) Add(e *pgrdf.Ebook) {
+	weights := make(map[string]float64)
+	add := func(weight float64, texts ...string) {
+		for _, text := range texts {
+			for _, term := range Tokenize(text) {
+				weights[term] += weight
+			}
+		}
+	}
+	add(WeightTitle, e.Titles...)
+	add(WeightAlternateTitle, e.AlternateTitles...)
+	for _, c := range e.Creators {
+		add(WeightCreator, c.Name)
+		add(WeightCreator, c.Aliases...)
+	}
+	for _, s := range e.Subjects {
+		add(WeightSubject, s.Heading)
+	}
+	add(WeightSummary, e.Summary)
+
+	var title string
+	if len(e.Titles) > 0 {
+		title = e.Titles[0]
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if i, ok := idx.ids[e.ID]; ok {
+		idx.remove(i)
+	}
+	doc := len(idx.docs)
+	idx.docs = append(idx.docs, document{ID: e.ID, Title: title, Downloads: e.Downloads})
+	idx.ids[e.ID] = doc
+
+	terms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		if _, ok := idx.postings[term]; !ok {
+			idx.terms = nil
+		}
+		idx.postings[term] = append(idx.postings[term], posting{Doc: doc, Weight: weight})
+		terms = append(terms, term)
+	}
+	idx.docTerms[doc] = terms
+}
+
+// Remove the ebook with the eText ID, returning false if it was not found.
+func (idx *Index) Remove(id int) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	i, ok := idx.ids[id]
+	if !ok {
+		return false
+	}
+	idx.remove(i)
+	delete(idx.ids, id)
+	return true
+}
+
+// remove marks the document as deleted, and removes its postings, so the
+// term frequencies only count the documents in the index. The deleted
+// documents are dropped once there are too many, which renumbers the docs.
+func (idx *Index) remove(doc int) {
+	idx.docs[doc].Deleted = true
+	idx.deleted++
+
+	for _, term := range idx.docTerms[doc] {
+		list := idx.postings[term]
+		// postings are in document order, as documents are only appended
+		i := sort.Search(len(list), func(i int) bool { return list[i].Doc >= doc })
+		if i == len(list) || list[i].Doc != doc {
+			continue
+		}
+		if len(list) == 1 {
+			delete(idx.postings, term)
+			idx.terms = nil
+			continue
+		}
+		idx.postings[term] = append(list[:i], list[i+1:]...)
+	}
+	delete(idx.docTerms, doc)
+
+	if idx.deleted >= compactMin && idx.deleted*2 > len(idx.docs) {
+		idx.docs, idx.postings = idx.compacted()
+		idx.deleted = 0
+		idx.rebuild()
+	}
+}
+
+// compacted returns the documents and postings without the deleted
+// documents, renumbering the remaining ones.
+func (idx *Index) compacted() ([]document, map[string][]posting) {
+	renumber := make([]int, len(idx.docs))
+	docs := make([]document, 0, len(idx.docs)-idx.deleted)
+	for i, d := range idx.docs {
+		renumber[i] = len(docs)
+		if !d.Deleted {
+			docs = append(docs, d)
+		}
+	}
+	postings := make(map[string][]posting, len(idx.postings))
+	for term, list := range idx.postings {
+		var kept []posting
+		for _, p := range list {
+			if !idx.docs[p.Doc].Deleted {
+				kept = append(kept, posting{Doc: renumber[p.Doc], Weight: p.Weight})
+			}
+		}
+		if len(kept) > 0 {
+			postings[term] = kept
+		}
+	}
+	return docs, postings
+}
+
+// rebuild the eText ID and document terms lookups from the docs and postings.
+func (idx *Index) rebuild() {
+	idx.ids = make(map[int]int, len(idx.docs))
+	for i, d := range idx.docs {
+		if !d.Deleted {
+			idx.ids[d.ID] = i
+		}
+	}
+	idx.docTerms = make(map[int][]string, len(idx.docs))
+	for term, list := range idx.postings {
+		for _, p := range list {
+			idx.docTerms[p.Doc] = append(idx.docTerms[p.Doc], term)
+		}
+	}
+}
+
+// Len returns the number of ebooks in the index.
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.ids)
+}
+
+// Search returns the ebooks matching all the words of the query, with the
+// highest scoring first. Ties are ordered by eText ID.
+func (idx *Index) Search(query string, opts *Options) []Result {
+	if opts == nil {
+		opts = &Options{}
+	}
+	words := Tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var scores map[int]float64
+	for i, word := range words {
+		terms := []string{word}
+		if opts.Prefix && i == len(words)-1 {
+			terms = idx.prefixTerms(word)
+		}
+
+		wordScores := make(map[int]float64)
+		for _, term := range terms {
+			list := idx.postings[term]
+			idf := math.Log(1 + float64(len(idx.docs)-idx.deleted)/float64(len(list)))
+			for _, p := range list {
+				if s := p.Weight * idf; s > wordScores[p.Doc] {
+					wordScores[p.Doc] = s
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+		for doc, s := range scores {
+			if ws, ok := wordScores[doc]; ok {
+				scores[doc] = s + ws
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for doc, score := range scores {
+		d := idx.docs[doc]
+		if opts.DownloadsBoost > 0 {
+			score *= 1 + opts.DownloadsBoost*math.Log1p(float64(d.Downloads))
+		}
+		results = append(results, Result{ID: d.ID, Title: d.Title, Downloads: d.Downloads, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results
+}
+
+// sortedTerms returns the sorted terms, building them when needed. The read
+// lock of mu must be held.
+func (idx *Index) sortedTerms() []string {
+	idx.termsMu.Lock()
+	defer idx.termsMu.Unlock()
+
+	if idx.terms == nil {
+		idx.terms = make([]string, 0, len(idx.postings))
+		for term := range idx.postings {
+			idx.terms = append(idx.terms, term)
+		}
+		sort.Strings(idx.terms)
+	}
+	return idx.terms
+}
+
+// prefixTerms returns all the terms starting with the prefix.
+func (idx *Index) prefixTerms(prefix string) []string {
+	sorted := idx.sortedTerms()
+
+	var terms []string
+	for i := sort.SearchStrings(sorted, prefix); i < len(sorted); i++ {
+		if !strings.HasPrefix(sorted[i], prefix) {
+			break
+		}
+		terms = append(terms, sorted[i])
+	}
+	return terms
+}
+
+// snapshot is the serialised form of an Index.
+type snapshot struct {
+	Version  int
+	Docs     []document
+	Postings map[string][]posting
+}
+
+// Write the index to the provided `io.Writer`, to be loaded with ReadIndex.
+// Removed and replaced ebooks are dropped from the written index.
+func (idx *Index) Write(w io.Writer) error {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	snap := snapshot{Version: indexVersion}
+	snap.Docs, snap.Postings = idx.compacted()
+
+	return gob.NewEncoder(w).Encode(snap)
+}
+
+// ReadIndex reads an index written by `Index.Write`, returning an error if
+// the index is not valid, e.g. a posting refers to a missing document.
+func ReadIndex(r io.Reader) (*Index, error) {
+	var snap snapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, err
+	}
+	if snap.Version != indexVersion {
+		return nil, fmt.Errorf("unsupported search index version %d", snap.Version)
+	}
+
+	if err := snap.validate(); err != nil {
+		return nil, fmt.Errorf("invalid search index: %w", err)
+	}
+
+	idx := &Index{docs: snap.Docs, postings: snap.Postings}
+	if idx.postings == nil {
+		idx.postings = make(map[string][]posting)
+	}
+	idx.rebuild()
+	return idx, nil
+}
+
+// validate checks the snapshot is as written by `Index.Write`, with unique
+// eText IDs, and the postings of each term in ascending document order, as
+// needed for removing documents.
+func (snap *snapshot) validate() error {
+	seen := make(map[int]bool, len(snap.Docs))
+	for _, d := range snap.Docs {
+		if d.Deleted {
+			return fmt.Errorf("deleted document %d", d.ID)
+		}
+		if seen[d.ID] {
+			return fmt.Errorf("duplicate document %d", d.ID)
+		}
+		seen[d.ID] = true
+	}
+	for term, list := range snap.Postings {
+		if len(list) == 0 {
+			return fmt.Errorf("term '%s' has no postings", term)
+		}
+		for i, p := range list {
+			if p.Doc < 0 || p.Doc >= len(snap.Docs) {
+				return fmt.Errorf("term '%s' has a posting for missing document %d", term, p.Doc)
+			}
+			if i > 0 && p.Doc <= list[i-1].Doc {
+				return fmt.Errorf("term '%s' postings are not in document order", term)
+			}
+		}
+	}
+	return nil
+}
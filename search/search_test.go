@@ -0,0 +1,263 @@
+package search_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/archive"
+	"github.com/mrcook/pgrdf/search"
+)
+
+func TestFold(t *testing.T) {
+	tests := map[string]string{
+		"Les Misérables":    "les miserables",
+		"Ærøskøbing Straße": "aeroskobing strasse",
+		"Dvořák":            "dvorak",
+		"Les Misérables":   "les miserables",
+	}
+	for in, expected := range tests {
+		if got := search.Fold(in); got != expected {
+			t.Errorf("unexpected fold of '%s', got '%s'", in, got)
+		}
+	}
+
+	if got := search.Tokenize("Orphans -- Fiction, 1861!"); !reflect.DeepEqual(got, []string{"orphans", "fiction", "1861"}) {
+		t.Errorf("unexpected tokens, got %v", got)
+	}
+}
+
+func ids(results []search.Result) []int {
+	list := []int{}
+	for _, r := range results {
+		list = append(list, r.ID)
+	}
+	return list
+}
+
+func testIndex() *search.Index {
+	e1 := &pgrdf.Ebook{ID: 135, Titles: []string{"Les Misérables"}, Downloads: 10}
+	e1.AddCreator(pgrdf.Creator{ID: 85, Name: "Hugo, Victor"})
+	e2 := &pgrdf.Ebook{ID: 48731, Titles: []string{"Victor Hugo: His Life"}, Summary: "A study of the author of Les Miserables.", Downloads: 5000}
+
+	return search.New(e1, e2, testIndexEbook1400())
+}
+
+func testIndexEbook1400() *pgrdf.Ebook {
+	e := &pgrdf.Ebook{ID: 1400, Titles: []string{"Great Expectations"}, Downloads: 20000}
+	e.AddCreator(pgrdf.Creator{ID: 37, Name: "Dickens, Charles", Aliases: []string{"Boz"}})
+	e.AddSubject("Orphans -- Fiction", "http://purl.org/dc/terms/LCSH")
+	return e
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := testIndex()
+
+	tests := []struct {
+		query    string
+		opts     *search.Options
+		expected []int
+	}{
+		{"miserables", nil, []int{135, 48731}},      // title ranks above summary
+		{"MISÉRABLES hugo", nil, []int{135, 48731}}, // all words must match
+		{"boz", nil, []int{1400}},                   // creator alias
+		{"orphans", nil, []int{1400}},               // subject
+		{"dickens hugo", nil, []int{}},
+		{"dick", nil, []int{}},
+		{"dick", &search.Options{Prefix: true}, []int{1400}},
+		{"miserables", &search.Options{DownloadsBoost: 2}, []int{48731, 135}},
+		{"miserables", &search.Options{Limit: 1}, []int{135}},
+		{"", nil, []int{}},
+	}
+	for _, test := range tests {
+		if got := ids(idx.Search(test.query, test.opts)); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("unexpected results for '%s', got %v", test.query, got)
+		}
+	}
+
+	// replacing and removing ebooks
+	idx.Add(&pgrdf.Ebook{ID: 135, Titles: []string{"Notre-Dame de Paris"}})
+	if got := ids(idx.Search("miserables", nil)); !reflect.DeepEqual(got, []int{48731}) {
+		t.Errorf("unexpected results after replace, got %v", got)
+	}
+	if !idx.Remove(1400) || idx.Remove(1400) {
+		t.Error("expected the first remove only to succeed")
+	}
+	if got := idx.Search("boz", nil); len(got) != 0 {
+		t.Errorf("expected no results after remove, got %v", ids(got))
+	}
+	if idx.Len() != 2 {
+		t.Errorf("expected 2 ebooks, got %d", idx.Len())
+	}
+}
+
+func TestIndex_ScoresAfterReplace(t *testing.T) {
+	orphans := &pgrdf.Ebook{ID: 2, Titles: []string{"Orphans of the Storm"}}
+	idx := testIndex()
+	for i := 0; i < 5; i++ {
+		idx.Add(orphans)
+	}
+	idx.Remove(135)
+	idx.Remove(48731)
+
+	// the same scores as an index built from the remaining ebooks
+	fresh := search.New(orphans, testIndexEbook1400())
+	for _, query := range []string{"orphans", "orph"} {
+		opts := &search.Options{Prefix: true}
+		got, expected := idx.Search(query, opts), fresh.Search(query, opts)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("unexpected results for '%s', got %+v, expected %+v", query, got, expected)
+		}
+	}
+	if got := idx.Search("miser", &search.Options{Prefix: true}); len(got) != 0 {
+		t.Errorf("expected no prefix results for removed ebooks, got %v", ids(got))
+	}
+}
+
+func TestIndex_ReplaceMany(t *testing.T) {
+	orphans := &pgrdf.Ebook{ID: 2, Titles: []string{"Orphans of the Storm"}}
+	idx := testIndex()
+	// enough replacements for the removed documents to be compacted
+	for i := 0; i < 2500; i++ {
+		idx.Add(&pgrdf.Ebook{ID: 2, Titles: []string{fmt.Sprintf("Orphans %d", i)}})
+	}
+	idx.Add(orphans)
+	idx.Remove(48731)
+
+	if idx.Len() != 3 {
+		t.Errorf("expected 3 ebooks, got %d", idx.Len())
+	}
+	miserables := &pgrdf.Ebook{ID: 135, Titles: []string{"Les Misérables"}, Downloads: 10}
+	miserables.AddCreator(pgrdf.Creator{ID: 85, Name: "Hugo, Victor"})
+	fresh := search.New(miserables, orphans, testIndexEbook1400())
+	for _, query := range []string{"orphans", "orph", "great", "miserables"} {
+		opts := &search.Options{Prefix: true}
+		got, expected := idx.Search(query, opts), fresh.Search(query, opts)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("unexpected results for '%s', got %+v, expected %+v", query, got, expected)
+		}
+	}
+	if got := idx.Search("1234", nil); len(got) != 0 {
+		t.Errorf("expected no results for replaced titles, got %v", ids(got))
+	}
+
+	idx.Remove(1400)
+	if got := idx.Search("great", nil); len(got) != 0 {
+		t.Errorf("expected no results for a removed ebook, got %v", ids(got))
+	}
+}
+
+func TestIndex_ConcurrentPrefixSearch(t *testing.T) {
+	idx := testIndex()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				idx.Search("dick", &search.Options{Prefix: true})
+				if i == 0 {
+					idx.Add(&pgrdf.Ebook{ID: 1000 + j, Titles: []string{fmt.Sprintf("Dictionary %d", j)}})
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := idx.Search("dict", &search.Options{Prefix: true}); len(got) != 50 {
+		t.Errorf("expected 50 results, got %d", len(got))
+	}
+}
+
+func TestIndex_WriteRead(t *testing.T) {
+	idx := testIndex()
+	idx.Remove(48731)
+
+	var buf bytes.Buffer
+	if err := idx.Write(&buf); err != nil {
+		t.Fatalf("unexpected error writing index: %s", err)
+	}
+	loaded, err := search.ReadIndex(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading index: %s", err)
+	}
+
+	if loaded.Len() != 2 {
+		t.Errorf("expected 2 ebooks, got %d", loaded.Len())
+	}
+	results := loaded.Search("great", nil)
+	if len(results) != 1 || results[0].ID != 1400 || results[0].Title != "Great Expectations" || results[0].Downloads != 20000 {
+		t.Errorf("unexpected results, got %+v", results)
+	}
+	if got := loaded.Search("miserables", nil); !reflect.DeepEqual(ids(got), []int{135}) {
+		t.Errorf("unexpected results, got %v", ids(got))
+	}
+
+	if _, err := search.ReadIndex(bytes.NewReader([]byte("invalid"))); err == nil {
+		t.Error("expected an error reading an invalid index")
+	}
+}
+
+// testSnapshot has the same gob encoding as the written index.
+type testSnapshot struct {
+	Version int
+	Docs    []struct {
+		ID    int
+		Title string
+	}
+	Postings map[string][]struct {
+		Doc    int
+		Weight float64
+	}
+}
+
+func TestReadIndex_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing document":   `{"Version": 1, "Docs": [{"ID": 1400}], "Postings": {"great": [{"Doc": 1, "Weight": 3}]}}`,
+		"negative document":  `{"Version": 1, "Docs": [{"ID": 1400}], "Postings": {"great": [{"Doc": -1, "Weight": 3}]}}`,
+		"duplicate ID":       `{"Version": 1, "Docs": [{"ID": 1400}, {"ID": 1400}], "Postings": {"great": [{"Doc": 0, "Weight": 3}]}}`,
+		"unordered postings": `{"Version": 1, "Docs": [{"ID": 1400}, {"ID": 98}], "Postings": {"dickens": [{"Doc": 1, "Weight": 2}, {"Doc": 0, "Weight": 2}]}}`,
+		"empty postings":     `{"Version": 1, "Docs": [{"ID": 1400}], "Postings": {"great": []}}`,
+	}
+	for name, data := range tests {
+		var snap testSnapshot
+		if err := json.Unmarshal([]byte(data), &snap); err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(snap); err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+		if _, err := search.ReadIndex(&buf); err == nil {
+			t.Errorf("%s: expected an error reading the index", name)
+		}
+	}
+}
+
+func TestIndex_Archive(t *testing.T) {
+	file, err := os.Open("../samples/rdf-files-test.tar")
+	if err != nil {
+		t.Fatalf("Unable to open RDF tar archive: %s", err)
+	}
+	defer file.Close()
+
+	idx := search.New()
+	err = archive.WalkTarArchive(file, func(e *pgrdf.Ebook) error {
+		idx.Add(e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error reading archive: %s", err)
+	}
+
+	if got := ids(idx.Search("dickens expectations", nil)); !reflect.DeepEqual(got, []int{1400}) {
+		t.Errorf("unexpected results, got %v", got)
+	}
+}
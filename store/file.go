@@ -0,0 +1,469 @@
+package store
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/catalog"
+	"github.com/mrcook/pgrdf/internal/idlist"
+)
+
+// Filenames of a FileStore directory.
+const (
+	LogFilename   = "ebooks.log"
+	IndexFilename = "index.gob"
+)
+
+var _ Store = (*FileStore)(nil)
+
+// snapshotVersion is the version of the index snapshot format.
+const snapshotVersion = 1
+
+// recordHeaderSize is the size of the record length and CRC-32 checksum.
+const recordHeaderSize = 8
+
+// FileStore is a Store using an append-only log file, with each ebook
+// written as a JSON record. Replacing or deleting an ebook appends a new
+// record, and Compact rewrites the log with only the current records.
+//
+// The eText ID offsets and secondary indexes are kept in memory, and written
+// to an index snapshot on Close, so the log does not need to be read when the
+// store is next opened. Any records written after the snapshot, e.g. when the
+// process was stopped without a Close, are read from the log on Open, and an
+// incomplete or corrupt last record is discarded. A corrupt record followed
+// by other records is an error, rather than losing the later records.
+//
+// A FileStore is safe for concurrent use, but only by a single process.
+type FileStore struct {
+	mu      sync.RWMutex
+	dir     string
+	log     *os.File
+	size    int64 // of the log file
+	garbage int64 // bytes of replaced and deleted records
+	records map[int]recordRef
+	indexes map[catalog.Index]map[string][]int
+}
+
+// recordRef is the location of an ebook record in the log file.
+type recordRef struct {
+	Offset int64
+	Size   int64
+}
+
+// record is the JSON document written to the log file.
+type record struct {
+	ID      int          `json:"id"`
+	Deleted bool         `json:"deleted,omitempty"`
+	Ebook   *pgrdf.Ebook `json:"ebook,omitempty"`
+}
+
+// snapshot is the index file of a FileStore.
+type snapshot struct {
+	Version int
+	LogSize int64
+	Garbage int64
+	Records map[int]recordRef
+	Indexes map[catalog.Index]map[string][]int
+}
+
+// Open the store in the directory, creating it when not found.
+func Open(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	log, err := os.OpenFile(filepath.Join(dir, LogFilename), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileStore{dir: dir, log: log}
+	if err := s.load(); err != nil {
+		log.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load the index snapshot, and then any log records written after it.
+func (s *FileStore) load() error {
+	s.reset()
+
+	info, err := s.log.Stat()
+	if err != nil {
+		return err
+	}
+
+	snap, err := readSnapshot(filepath.Join(s.dir, IndexFilename))
+	if err != nil {
+		return err
+	}
+	if snap != nil && snap.LogSize <= info.Size() {
+		s.size = snap.LogSize
+		s.garbage = snap.Garbage
+		s.records = snap.Records
+		for index, keys := range snap.Indexes {
+			s.indexes[index] = keys
+		}
+	}
+
+	return s.replay(info.Size())
+}
+
+func (s *FileStore) reset() {
+	s.size = 0
+	s.garbage = 0
+	s.records = make(map[int]recordRef)
+	s.indexes = make(map[catalog.Index]map[string][]int)
+	for _, index := range catalog.Indexes {
+		s.indexes[index] = make(map[string][]int)
+	}
+}
+
+// replay reads the log records from the current size onwards, truncating the
+// log at an incomplete last record, or a corrupt one ending the log, as left
+// by an interrupted write.
+func (s *FileStore) replay(logSize int64) error {
+	r := bufio.NewReader(io.NewSectionReader(s.log, s.size, logSize-s.size))
+	for s.size < logSize {
+		rec, size, err := readRecord(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err == errChecksum && s.size+size == logSize {
+			break
+		} else if err != nil {
+			return errors.Wrapf(err, "error reading record at offset %d", s.size)
+		}
+		if err := s.apply(rec, recordRef{Offset: s.size, Size: size}); err != nil {
+			return err
+		}
+		s.size += size
+	}
+
+	if s.size < logSize {
+		return s.log.Truncate(s.size)
+	}
+	return nil
+}
+
+// apply the record, written at the ref, to the offsets and indexes.
+func (s *FileStore) apply(rec *record, ref recordRef) error {
+	if old, ok := s.records[rec.ID]; ok {
+		e, err := s.read(old)
+		if err != nil {
+			return err
+		}
+		s.unindex(e)
+		delete(s.records, rec.ID)
+		s.garbage += old.Size
+	}
+
+	if rec.Deleted {
+		s.garbage += ref.Size
+		return nil
+	}
+	s.records[rec.ID] = ref
+	s.index(rec.Ebook)
+	return nil
+}
+
+// Get returns the ebook with the eText ID, or ErrNotFound.
+func (s *FileStore) Get(id int) (*pgrdf.Ebook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ref, ok := s.records[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s.read(ref)
+}
+
+// Put adds the ebook, replacing any ebook with the same eText ID.
+func (s *FileStore) Put(e *pgrdf.Ebook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.append(&record{ID: e.ID, Ebook: e})
+}
+
+// Delete removes the ebook with the eText ID, or returns ErrNotFound.
+func (s *FileStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[id]; !ok {
+		return ErrNotFound
+	}
+	return s.append(&record{ID: id, Deleted: true})
+}
+
+// IDs returns the eText IDs of all the ebooks, in ascending order.
+func (s *FileStore) IDs() ([]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]int, 0, len(s.records))
+	for id := range s.records {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// Len returns the number of ebooks in the store.
+func (s *FileStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.records)
+}
+
+// Query returns the eText IDs of the ebooks with the key in the secondary
+// index, in ascending order.
+func (s *FileStore) Query(index catalog.Index, key string) ([]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]int{}, s.indexes[index][catalog.NormaliseKey(index, key)]...), nil
+}
+
+// Keys returns all the keys of the secondary index, sorted by name.
+func (s *FileStore) Keys(index catalog.Index) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.indexes[index]))
+	for key := range s.indexes[index] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Compact rewrites the log file with only the current ebook records, dropping
+// all replaced and deleted records.
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filename := filepath.Join(s.dir, LogFilename)
+	tmp, err := os.CreateTemp(s.dir, "."+LogFilename+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+
+	ids := make([]int, 0, len(s.records))
+	for id := range s.records {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	records := make(map[int]recordRef, len(ids))
+	w := bufio.NewWriter(tmp)
+	var offset int64
+	for _, id := range ids {
+		ref := s.records[id]
+		if _, err := io.Copy(w, io.NewSectionReader(s.log, ref.Offset, ref.Size)); err != nil {
+			tmp.Close()
+			return err
+		}
+		records[id] = recordRef{Offset: offset, Size: ref.Size}
+		offset += ref.Size
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	// the snapshot offsets are for the old log
+	if err := os.Remove(filepath.Join(s.dir, IndexFilename)); err != nil && !os.IsNotExist(err) {
+		tmp.Close()
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	s.log.Close()
+	s.log = tmp
+	s.records = records
+	s.size = offset
+	s.garbage = 0
+
+	return s.writeSnapshot()
+}
+
+// Garbage returns the number of bytes in the log used by replaced and deleted
+// records, which Compact would free.
+func (s *FileStore) Garbage() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.garbage
+}
+
+// Close the store, writing the index snapshot.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.log.Sync(); err != nil {
+		s.log.Close()
+		return err
+	}
+	if err := s.writeSnapshot(); err != nil {
+		s.log.Close()
+		return err
+	}
+	return s.log.Close()
+}
+
+func (s *FileStore) append(rec *record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, recordHeaderSize+len(data))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(data))
+	copy(buf[recordHeaderSize:], data)
+
+	if _, err := s.log.WriteAt(buf, s.size); err != nil {
+		return err
+	}
+	ref := recordRef{Offset: s.size, Size: int64(len(buf))}
+	if err := s.apply(rec, ref); err != nil {
+		return err
+	}
+	s.size += ref.Size
+	return nil
+}
+
+func (s *FileStore) read(ref recordRef) (*pgrdf.Ebook, error) {
+	rec, _, err := readRecord(io.NewSectionReader(s.log, ref.Offset, ref.Size))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading record at offset %d", ref.Offset)
+	}
+	if rec.Ebook == nil {
+		return nil, errors.Errorf("no ebook in record at offset %d", ref.Offset)
+	}
+	return rec.Ebook, nil
+}
+
+var errChecksum = errors.New("record checksum mismatch")
+
+// readRecord reads a single record, returning it with its size in the log.
+// The size is also returned with a checksum error.
+func readRecord(r io.Reader) (*record, int64, error) {
+	header := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, err
+	}
+	data := make([]byte, binary.LittleEndian.Uint32(header[0:4]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	size := int64(recordHeaderSize + len(data))
+	if crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(header[4:8]) {
+		return nil, size, errChecksum
+	}
+
+	rec := &record{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, 0, err
+	}
+	return rec, size, nil
+}
+
+func (s *FileStore) index(e *pgrdf.Ebook) {
+	for _, index := range catalog.Indexes {
+		for _, key := range catalog.IndexKeys(e, index) {
+			s.indexes[index][key] = idlist.Insert(s.indexes[index][key], e.ID)
+		}
+	}
+}
+
+func (s *FileStore) unindex(e *pgrdf.Ebook) {
+	for _, index := range catalog.Indexes {
+		for _, key := range catalog.IndexKeys(e, index) {
+			ids := idlist.Remove(s.indexes[index][key], e.ID)
+			if len(ids) == 0 {
+				delete(s.indexes[index], key)
+			} else {
+				s.indexes[index][key] = ids
+			}
+		}
+	}
+}
+
+func (s *FileStore) writeSnapshot() error {
+	tmp, err := os.CreateTemp(s.dir, "."+IndexFilename+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+
+	snap := snapshot{
+		Version: snapshotVersion,
+		LogSize: s.size,
+		Garbage: s.garbage,
+		Records: s.records,
+		Indexes: s.indexes,
+	}
+	w := bufio.NewWriter(tmp)
+	if err := gob.NewEncoder(w).Encode(snap); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, IndexFilename))
+}
+
+// readSnapshot returns the index snapshot, or nil when not found or of an
+// older version, in which case the whole log is read.
+func readSnapshot(filename string) (*snapshot, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	snap := &snapshot{}
+	if err := gob.NewDecoder(bufio.NewReader(file)).Decode(snap); err != nil || snap.Version != snapshotVersion {
+		return nil, nil
+	}
+	return snap, nil
+}
@@ -0,0 +1,108 @@
+// Package store keeps a catalog of ebooks on disk, keyed by eText ID, with
+// the same secondary indexes as the in-memory `catalog` package. Only the
+// indexes are held in memory, with the ebooks being read from disk as needed.
+package store
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/archive"
+	"github.com/mrcook/pgrdf/catalog"
+)
+
+// ErrNotFound is returned when an eText ID is not in the store.
+var ErrNotFound = errors.New("ebook not found")
+
+// Store is a persistent collection of ebooks, keyed by eText ID.
+type Store interface {
+	// Get returns the ebook with the eText ID, or ErrNotFound.
+	Get(id int) (*pgrdf.Ebook, error)
+
+	// Put adds the ebook, replacing any ebook with the same eText ID.
+	Put(e *pgrdf.Ebook) error
+
+	// Delete removes the ebook with the eText ID, or returns ErrNotFound.
+	Delete(id int) error
+
+	// IDs returns the eText IDs of all the ebooks, in ascending order.
+	IDs() ([]int, error)
+
+	// Query returns the eText IDs of the ebooks with the key in the
+	// secondary index, in ascending order, see `catalog.Index`.
+	Query(index catalog.Index, key string) ([]int, error)
+
+	// Keys returns all the keys of the secondary index, sorted by name.
+	Keys(index catalog.Index) ([]string, error)
+
+	// Close the store, flushing all changes to disk.
+	Close() error
+}
+
+// ImportResult lists the eTexts changed by an Import.
+type ImportResult struct {
+	Added     []int
+	Updated   []int
+	Removed   []int
+	Unchanged int
+}
+
+// Import the ebooks from an RDF archive into the store. Ebooks are only
+// written when they are new or have changed, and ebooks no longer in the
+// archive are removed, so importing a newer archive updates the store
+// incrementally. An archive without any ebooks returns `archive.ErrNoRDFFiles`,
+// and nothing is removed.
+func Import(s Store, archiveFile io.Reader) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	ids, err := s.IDs()
+	if err != nil {
+		return result, err
+	}
+	stale := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		stale[id] = true
+	}
+
+	var walked int
+	err = archive.WalkTarArchive(archiveFile, func(e *pgrdf.Ebook) error {
+		walked++
+		delete(stale, e.ID)
+
+		old, err := s.Get(e.ID)
+		switch {
+		case err == ErrNotFound:
+			result.Added = append(result.Added, e.ID)
+		case err != nil:
+			return errors.Wrapf(err, "error reading eText ID '%d'", e.ID)
+		case reflect.DeepEqual(old, e):
+			result.Unchanged++
+			return nil
+		default:
+			result.Updated = append(result.Updated, e.ID)
+		}
+
+		return errors.Wrapf(s.Put(e), "error writing eText ID '%d'", e.ID)
+	})
+	if err != nil {
+		return result, err
+	}
+	if walked == 0 {
+		return result, archive.ErrNoRDFFiles
+	}
+
+	for _, id := range ids {
+		if !stale[id] {
+			continue
+		}
+		if err := s.Delete(id); err != nil {
+			return result, errors.Wrapf(err, "error removing eText ID '%d'", id)
+		}
+		result.Removed = append(result.Removed, id)
+	}
+
+	return result, nil
+}
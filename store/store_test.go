@@ -0,0 +1,284 @@
+package store_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/pgrdf"
+	"github.com/mrcook/pgrdf/archive"
+	"github.com/mrcook/pgrdf/catalog"
+	"github.com/mrcook/pgrdf/store"
+)
+
+func openStore(t *testing.T, dir string) *store.FileStore {
+	t.Helper()
+	s, err := store.Open(dir)
+	if err != nil {
+		t.Fatalf("unexpected error opening store: %s", err)
+	}
+	return s
+}
+
+func testEbook(id int, title, lang string) *pgrdf.Ebook {
+	e := &pgrdf.Ebook{ID: id, Titles: []string{title}, Languages: []string{lang}, BookType: pgrdf.BookTypeText}
+	e.AddCreator(pgrdf.Creator{ID: 37, Name: "Dickens, Charles"})
+	return e
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	s := openStore(t, dir)
+
+	for _, e := range []*pgrdf.Ebook{testEbook(1400, "Great Expectations", "en"), testEbook(98, "A Tale of Two Cities", "en"), testEbook(46, "A Christmas Carol", "en")} {
+		if err := s.Put(e); err != nil {
+			t.Fatalf("unexpected error writing ebook: %s", err)
+		}
+	}
+	if err := s.Put(testEbook(98, "Le Conte de deux cités", "fr")); err != nil {
+		t.Fatalf("unexpected error replacing ebook: %s", err)
+	}
+	if err := s.Delete(46); err != nil {
+		t.Fatalf("unexpected error deleting ebook: %s", err)
+	}
+	if err := s.Delete(46); err != store.ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	check := func(s *store.FileStore) {
+		t.Helper()
+		if ids, _ := s.IDs(); !reflect.DeepEqual(ids, []int{98, 1400}) {
+			t.Errorf("unexpected eText IDs, got %v", ids)
+		}
+		e, err := s.Get(98)
+		if err != nil || e.Titles[0] != "Le Conte de deux cités" {
+			t.Errorf("unexpected ebook, got %v (%v)", e, err)
+		}
+		if _, err := s.Get(46); err != store.ErrNotFound {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if ids, _ := s.Query(catalog.IndexLanguage, "EN"); !reflect.DeepEqual(ids, []int{1400}) {
+			t.Errorf("unexpected language query, got %v", ids)
+		}
+		if ids, _ := s.Query(catalog.IndexCreator, "37"); !reflect.DeepEqual(ids, []int{98, 1400}) {
+			t.Errorf("unexpected creator query, got %v", ids)
+		}
+		if keys, _ := s.Keys(catalog.IndexLanguage); !reflect.DeepEqual(keys, []string{"en", "fr"}) {
+			t.Errorf("unexpected language keys, got %v", keys)
+		}
+	}
+	check(s)
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error closing store: %s", err)
+	}
+
+	// reopened using the index snapshot
+	s = openStore(t, dir)
+	check(s)
+	garbage := s.Garbage()
+	if garbage == 0 {
+		t.Error("expected replaced and deleted records to be garbage")
+	}
+	s.Close()
+
+	// reopened by reading the log
+	if err := os.Remove(filepath.Join(dir, store.IndexFilename)); err != nil {
+		t.Fatal(err)
+	}
+	s = openStore(t, dir)
+	check(s)
+	if s.Garbage() != garbage {
+		t.Errorf("expected %d bytes of garbage, got %d", garbage, s.Garbage())
+	}
+
+	// compacted
+	if err := s.Compact(); err != nil {
+		t.Fatalf("unexpected error compacting store: %s", err)
+	}
+	check(s)
+	if s.Garbage() != 0 {
+		t.Errorf("expected no garbage after compaction, got %d", s.Garbage())
+	}
+	s.Close()
+	s = openStore(t, dir)
+	defer s.Close()
+	check(s)
+}
+
+func TestFileStore_IncompleteRecord(t *testing.T) {
+	dir := t.TempDir()
+	s := openStore(t, dir)
+	_ = s.Put(testEbook(1400, "Great Expectations", "en"))
+	_ = s.Put(testEbook(98, "A Tale of Two Cities", "en"))
+	s.Close()
+
+	// an interrupted write of the last record
+	logFile := filepath.Join(dir, store.LogFilename)
+	info, _ := os.Stat(logFile)
+	if err := os.Truncate(logFile, info.Size()-10); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Remove(filepath.Join(dir, store.IndexFilename))
+
+	s = openStore(t, dir)
+	defer s.Close()
+	if ids, _ := s.IDs(); !reflect.DeepEqual(ids, []int{1400}) {
+		t.Errorf("unexpected eText IDs, got %v", ids)
+	}
+	if err := s.Put(testEbook(98, "A Tale of Two Cities", "en")); err != nil {
+		t.Fatalf("unexpected error writing ebook: %s", err)
+	}
+	if e, err := s.Get(98); err != nil || e.Titles[0] != "A Tale of Two Cities" {
+		t.Errorf("unexpected ebook, got %v (%v)", e, err)
+	}
+}
+
+func TestFileStore_CorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	s := openStore(t, dir)
+	_ = s.Put(testEbook(1400, "Great Expectations", "en"))
+	_ = s.Put(testEbook(98, "A Tale of Two Cities", "en"))
+	s.Close()
+	_ = os.Remove(filepath.Join(dir, store.IndexFilename))
+
+	logFile := filepath.Join(dir, store.LogFilename)
+	data, _ := os.ReadFile(logFile)
+	corrupt := func(offset int) {
+		t.Helper()
+		b := append([]byte{}, data...)
+		b[offset] ^= 0xFF
+		if err := os.WriteFile(logFile, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a corrupt record followed by others must not be discarded
+	corrupt(10)
+	if s, err := store.Open(dir); err == nil {
+		s.Close()
+		t.Fatal("expected an error opening a log with a corrupt record")
+	}
+	if info, _ := os.Stat(logFile); info.Size() != int64(len(data)) {
+		t.Errorf("expected the log not to be truncated, got %d bytes", info.Size())
+	}
+
+	// a corrupt last record, e.g. from an interrupted write, is discarded
+	corrupt(len(data) - 10)
+	s = openStore(t, dir)
+	defer s.Close()
+	if ids, _ := s.IDs(); !reflect.DeepEqual(ids, []int{1400}) {
+		t.Errorf("unexpected eText IDs, got %v", ids)
+	}
+}
+
+// archiveWithout returns the sample archive without the given eText files.
+func archiveWithout(t *testing.T, skip string) io.Reader {
+	t.Helper()
+	data, err := os.ReadFile("../samples/rdf-files-test.tar")
+	if err != nil {
+		t.Fatalf("Unable to open RDF tar archive: %s", err)
+	}
+
+	var buf bytes.Buffer
+	tr := tar.NewReader(bytes.NewReader(data))
+	tw := tar.NewWriter(&buf)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if len(skip) > 0 && strings.Contains(header.Name, skip) {
+			continue
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestImport(t *testing.T) {
+	s := openStore(t, t.TempDir())
+	defer s.Close()
+
+	result, err := store.Import(s, archiveWithout(t, "pg11.rdf"))
+	if err != nil {
+		t.Fatalf("unexpected error importing archive: %s", err)
+	}
+	if !reflect.DeepEqual(result.Added, []int{1400}) || result.Unchanged != 0 {
+		t.Errorf("unexpected first import result, got %+v", result)
+	}
+
+	// changed locally, so updated by the next import
+	e, _ := s.Get(1400)
+	e.Downloads = 1
+	_ = s.Put(e)
+	_ = s.Put(&pgrdf.Ebook{ID: 999})
+
+	result, err = store.Import(s, archiveWithout(t, ""))
+	if err != nil {
+		t.Fatalf("unexpected error importing archive: %s", err)
+	}
+	if !reflect.DeepEqual(result.Added, []int{11}) || !reflect.DeepEqual(result.Updated, []int{1400}) || !reflect.DeepEqual(result.Removed, []int{999}) {
+		t.Errorf("unexpected second import result, got %+v", result)
+	}
+
+	result, err = store.Import(s, archiveWithout(t, ""))
+	if err != nil {
+		t.Fatalf("unexpected error importing archive: %s", err)
+	}
+	if len(result.Added)+len(result.Updated)+len(result.Removed) != 0 || result.Unchanged != 2 {
+		t.Errorf("expected no changes, got %+v", result)
+	}
+
+	if ids, _ := s.Query(catalog.IndexCreator, "37"); !reflect.DeepEqual(ids, []int{1400}) {
+		t.Errorf("unexpected creator query, got %v", ids)
+	}
+
+	// only the order of the subjects changed locally
+	e, _ = s.Get(11)
+	e.Subjects[0], e.Subjects[1] = e.Subjects[1], e.Subjects[0]
+	_ = s.Put(e)
+
+	result, err = store.Import(s, archiveWithout(t, ""))
+	if err != nil {
+		t.Fatalf("unexpected error importing archive: %s", err)
+	}
+	if !reflect.DeepEqual(result.Updated, []int{11}) || result.Unchanged != 1 {
+		t.Errorf("expected a reordered ebook to be updated, got %+v", result)
+	}
+}
+
+func TestImport_NoRDFFiles(t *testing.T) {
+	s := openStore(t, t.TempDir())
+	defer s.Close()
+
+	if _, err := store.Import(s, archiveWithout(t, "")); err != nil {
+		t.Fatalf("unexpected error importing archive: %s", err)
+	}
+
+	result, err := store.Import(s, archiveWithout(t, ".rdf"))
+	if err != archive.ErrNoRDFFiles {
+		t.Errorf("expected ErrNoRDFFiles, got '%v'", err)
+	}
+	if len(result.Removed) != 0 {
+		t.Errorf("expected no ebooks to be removed, got %v", result.Removed)
+	}
+	if ids, _ := s.IDs(); !reflect.DeepEqual(ids, []int{11, 1400}) {
+		t.Errorf("expected all ebooks to remain, got %v", ids)
+	}
+}
@@ -1,5 +1,11 @@
 package pgrdf
 
+// Subject schemas of the Library of Congress.
+const (
+	SchemaLCSH = "http://purl.org/dc/terms/LCSH" // Subject Headings
+	SchemaLCC  = "http://purl.org/dc/terms/LCC"  // Classification
+)
+
 // Subject is a Dublin Core Vocabulary Encoding Scheme such as LCSH and LCC.
 // <dcterms:subject>
 type Subject struct {